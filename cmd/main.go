@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"expvar"
 	"flag"
 	"fmt"
 	"log"
@@ -19,26 +21,51 @@ import (
 	migrate_driver "github.com/golang-migrate/migrate/v4/database/cockroachdb" // migrate_driver "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"                       // Import the file source driver
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	"stock-api/config"
 	"stock-api/infrastructure"
+	"stock-api/infrastructure/adapters/exporter"
 	"stock-api/infrastructure/adapters/handler"
 	"stock-api/infrastructure/adapters/middleware"
 	"stock-api/infrastructure/adapters/repository"
 	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
 	"stock-api/infrastructure/core/service"
+	"stock-api/infrastructure/eventbus"
 )
 
 var (
-	mode         = flag.String("mode", "api", "Mode: 'api' or 'data'")
-	migrate_dir  = flag.String("migrate", "", "Run database migrations 'up' or 'down'")
-	repo         *repository.StockBDRepository
-	stockService *service.StockService
-	httpHandler  *handler.StockHandler
+	mode             = flag.String("mode", "api", "Mode: 'api', 'data', 'sanity', or 'export'")
+	migrate_dir      = flag.String("migrate", "", "Run database migrations 'up' or 'down'")
+	sampleSize       = flag.Int("sample", 0, "Number of stocks checked in 'sanity' mode (defaults to SANITY_SAMPLE_SIZE)")
+	exportOut        = flag.String("out", "-", "Destination of 'export' mode: a file path, an upload URL, or '-' for stdout. {timestamp} is expanded")
+	exportFormat     = flag.String("format", exporter.FormatCSV, "Format of 'export' mode: 'csv' or 'json'")
+	exportLimit      = flag.Int("limit", 20, "Number of recommendations exported")
+	strategy         = flag.String("strategy", service.StrategyScore, "Recommendation strategy of 'export' mode")
+	weightsVersion   = flag.Int("weights", 0, "Version of the scoring weights used by 'export' mode (defaults to the one in effect)")
+	repo             port.StockStore
+	auditRepo        port.AuditRepository
+	shadowRepo       *repository.ShadowClassificationRepository
+	webhookRepo      *repository.WebhookRepository
+	schemaRepo       port.SchemaInspector
+	scoreHistoryRepo port.ScoreHistoryRepository
+	apiClient        port.APIClient
+	scoringWeights   *service.ScoringWeightsService
+	bus              *eventbus.Bus
+	stockService     *service.StockService
+	httpHandler      *handler.StockHandler
+	reclassifier     *service.ReclassifyService
+	adminHandler     *handler.AdminHandler
 )
 
+// sandboxSeed seeds the synthetic data of sandbox mode, so every sandbox boots with the
+// same stocks.
+const sandboxSeed = 42
+
 // setupRouter configures the Gin router with all required middleware.
-// It sets up CORS, logging, and recovery middleware.
+// It sets up CORS, logging, recovery, the request deadline, and the collection of the
+// cache hints given by services. In sandbox mode, every response is labeled as such.
 // Returns a configured *gin.Engine instance.
 func setupRouter(cfg *config.Config, zapLogger *zap.Logger) *gin.Engine {
 	r := gin.Default()
@@ -47,29 +74,116 @@ func setupRouter(cfg *config.Config, zapLogger *zap.Logger) *gin.Engine {
 	r.Use(middleware.AsyncCORSMiddleware(cfg.AllowedOrigins))
 	r.Use(middleware.AsyncLogger(zapLogger))
 	r.Use(gin.Recovery())
+	r.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+	r.Use(middleware.CacheHints())
+	if cfg.Sandbox.Enabled {
+		r.Use(middleware.Sandbox())
+	}
 
 	return r
 }
 
 // setupRoutes defines all API endpoints and attaches them to the router.
 // It initializes the handler with the worker pool and services.
-func setupRoutes(router *gin.Engine) {
-	srv := service.NewBestInvestmentsService()
+// Load shedding measures the worker pool and, unless sqlDB is nil, the database
+// connection pool. Administrative endpoints are grouped under /api/v1/admin, behind admin
+// authentication and the audit log. Sandbox mode has no shadow classifier or webhooks,
+// so it omits their endpoints.
+func setupRoutes(cfg *config.Config, router *gin.Engine, sqlDB *sql.DB) {
+	srv := service.NewBestInvestmentsService().WithScoringWeights(scoringWeights)
+
+	// Drop cached scores of rows that change
+	eventbus.Subscribe(bus, eventbus.StockWrites, func(event domain.StockWriteEvent) {
+		ids := make([]uint, len(event.Stocks))
+		for i := range event.Stocks {
+			ids[i] = event.Stocks[i].ID
+		}
+		srv.InvalidateScores(ids...)
+	})
 
 	// Worker pool size = (cores * 2) + 1 (for storage units)
 	workerPoolSize := (runtime.NumCPU() * 2) + 1
 
-	httpHandler = handler.NewStockHandler(stockService, srv, workerPoolSize)
+	httpHandler = handler.NewStockHandler(stockService, srv, workerPoolSize).WithScoringWeights(scoringWeights)
+	if cfg.Recommendations.SnapshotPath != "" {
+		httpHandler.WithRecommendationSnapshots(repository.NewFileSnapshotStore(cfg.Recommendations.SnapshotPath))
+		log.Printf("Recommendation snapshots kept at %s", cfg.Recommendations.SnapshotPath)
+	}
+
+	// Under pressure, bulk list queries are shed first so health checks, administration,
+	// and recommendations keep succeeding
+	resources := []middleware.Occupancy{httpHandler.WorkerPoolOccupancy}
+	if sqlDB != nil {
+		resources = append(resources, func() (int, int) {
+			stats := sqlDB.Stats()
+			return stats.InUse, stats.MaxOpenConnections
+		})
+	}
+	shedder := middleware.NewLoadShedder(resources...).WithThresholds(cfg.LoadShedding.LowThreshold, cfg.LoadShedding.NormalThreshold)
+	critical := shedder.Handle(middleware.PriorityCritical)
+	normal := shedder.Handle(middleware.PriorityNormal)
+	low := shedder.Handle(middleware.PriorityLow)
+
+	router.GET("/readyz", critical, handler.NewReadinessHandler(schemaRepo).Readyz)
 	api := router.Group("/api/v1")
-	api.GET("/health", func(c *gin.Context) {
+	api.GET("/health", critical, func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
-	api.POST("/stocks", httpHandler.FindStocks)
-	api.GET("/recommendations", httpHandler.GetStockRecommendations)
+	api.GET("/stocks", low, httpHandler.FindStocks)
+	api.POST("/stocks", low, httpHandler.FindStocks)
+	api.HEAD("/stocks", low, httpHandler.CountStocks)
+	api.GET("/stocks/classifications/:classification", low, httpHandler.FindStocksByClassification)
+	api.GET("/stocks/:ticker/score", normal, httpHandler.GetTickerScore)
+	api.GET("/stocks/:ticker/score-history", normal, handler.NewScoreHistoryHandler(scoreHistoryRepo).GetScoreHistory)
+	api.GET("/recommendations", normal, httpHandler.GetStockRecommendations)
+
+	if len(cfg.Admin.Tokens) == 0 {
+		log.Println("No ADMIN_TOKENS configured, admin endpoints will reject every request")
+	}
+	api.GET("/auth/whoami", normal, middleware.AdminAuth(cfg.Admin.Tokens), handler.WhoAmI)
+
+	adminHandler = handler.NewAdminHandler(auditRepo, newBatchProcessor(cfg), repo, srv, scoringWeights)
+	admin := api.Group("/admin")
+	admin.Use(critical, middleware.AdminAuth(cfg.Admin.Tokens), middleware.AuditLog(auditRepo))
+	admin.GET("/audit", adminHandler.ListAuditLog)
+	admin.GET("/metrics", gin.WrapH(expvar.Handler()))
+	admin.POST("/cache/purge", adminHandler.PurgeCaches)
+	admin.POST("/ingest", adminHandler.TriggerIngestion)
+	admin.POST("/stocks", httpHandler.CreateStock)
+	admin.POST("/stocks/batch", httpHandler.CreateStocks)
+	admin.PATCH("/stocks/:id", httpHandler.UpdateStock)
+
+	reclassifier = service.NewReclassifyService(repo, repo, newClassifier(cfg), newFieldValidator(), service.DefaultReclassifyBatchSize)
+	reclassifyHandler := handler.NewReclassifyHandler(reclassifier)
+	admin.POST("/reclassify", reclassifyHandler.StartJob)
+	admin.GET("/reclassify", reclassifyHandler.ListJobs)
+	admin.GET("/reclassify/:id", reclassifyHandler.GetJob)
+
+	weightsHandler := handler.NewScoringWeightsHandler(scoringWeights)
+	admin.GET("/scoring-weights", weightsHandler.GetWeights)
+	admin.GET("/scoring-weights/changes", weightsHandler.ListChanges)
+	admin.PUT("/scoring-weights/:label", weightsHandler.SetWeight)
+	admin.DELETE("/scoring-weights/:label", weightsHandler.DeleteWeight)
+
+	if cfg.Sandbox.Enabled {
+		return
+	}
+
+	shadowHandler := handler.NewShadowHandler(shadowRepo, cfg.Classification.Shadow)
+	admin.GET("/classifiers/shadow/report", shadowHandler.GetReport)
+
+	webhookHandler := handler.NewWebhookHandler(webhookRepo)
+	admin.POST("/webhooks", webhookHandler.CreateSubscription)
+	admin.GET("/webhooks", webhookHandler.ListSubscriptions)
+	admin.GET("/webhooks/:id", webhookHandler.GetSubscription)
+	admin.PUT("/webhooks/:id", webhookHandler.UpdateSubscription)
+	admin.DELETE("/webhooks/:id", webhookHandler.DeleteSubscription)
+	admin.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
 }
 
 // RunMigrations executes database migrations in the specified direction ("up" or "down").
 // It initializes the migration driver and runs the migrations from the "migrations" directory.
+// A schema that is already up to date is not an error. The resulting schema version is logged.
 // Returns an error if migration fails.
 func RunMigrations(cfg *config.Config, db *sql.DB, direction string) error {
 	// Validate the direction argument
@@ -95,35 +209,144 @@ func RunMigrations(cfg *config.Config, db *sql.DB, direction string) error {
 	// Run migrations based on the specified direction
 	switch direction {
 	case "up":
-		if err := m.Up(); err != nil {
+		err = m.Up()
+		switch {
+		case errors.Is(err, migrate.ErrNoChange):
+			log.Println("Migrations already up to date, nothing to apply")
+		case err != nil:
 			return fmt.Errorf("error applying migrations: %w", err)
+		default:
+			log.Println("Migrations applied successfully")
 		}
-		log.Println("Migrations applied successfully")
 	case "down":
-		if err := m.Down(); err != nil {
+		err = m.Down()
+		switch {
+		case errors.Is(err, migrate.ErrNoChange):
+			log.Println("No migrations to roll back")
+		case err != nil:
 			return fmt.Errorf("error rolling back migrations: %w", err)
+		default:
+			log.Println("Migrations rolled back successfully")
 		}
-		log.Println("Migrations rolled back successfully")
+	}
+
+	// Report the resulting schema version
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		log.Println("Schema version: none (no migrations applied)")
+	case err != nil:
+		return fmt.Errorf("error reading schema version: %w", err)
+	default:
+		log.Printf("Schema version: %d (dirty: %t)", version, dirty)
 	}
 
 	return nil
 }
 
-// setupBatchProcessor initializes and runs the batch processor in a goroutine.
-// It processes stocks using the external API client and classification service.
-// The done channel is closed when processing is finished.
-func setupBatchProcessor(cfg *config.Config, done chan struct{}) {
-	apiClient := service.NewExternalAPIClient(cfg.ExternalAPI.URL)
-	classificationService := service.NewClassificationService()
+// newFieldValidator creates the validator of the fields clients can filter and sort by.
+func newFieldValidator() port.FieldValidator {
+	return repository.NewGormFieldValidator(&domain.Stock{}, repository.VirtualFields()...)
+}
+
+// newClassifier creates the active classifier. An unknown or unusable classifier falls
+// back to the default one, so a misconfiguration does not stop ingestion.
+func newClassifier(cfg *config.Config) port.ClassificationService {
+	classifier, err := service.NewClassifier(cfg.Classification.Active, service.ClassifierDeps{History: repo})
+	if err != nil {
+		log.Printf("Classifier %q unavailable, using %q: %v", cfg.Classification.Active, service.DefaultClassifier, err)
+		return service.NewClassificationService()
+	}
+	return classifier
+}
+
+// newBatchProcessor creates the batch processor that fetches stocks from the API client,
+// classifies them, and saves them through the repository.
+func newBatchProcessor(cfg *config.Config) *handler.BatchProcessor {
+	classificationService := newClassifier(cfg)
 
 	processor := handler.NewBatchProcessor(
 		apiClient,
 		repo,
 		classificationService,
+		bus,
 		cfg.ExternalAPI.BatchSize,
 		cfg.ExternalAPI.JWTToken,
 		500, // e.g., 500ms
-	)
+	).WithScoreHistory(service.NewScoreHistoryRecorder(scoreHistoryRepo, service.NewBestInvestmentsService().WithScoringWeights(scoringWeights)))
+
+	// Shadow mode: run a candidate classifier alongside the active one
+	if cfg.Classification.Shadow != "" && shadowRepo != nil {
+		shadow, err := service.NewClassifier(cfg.Classification.Shadow, service.ClassifierDeps{History: repo})
+		if err != nil {
+			log.Printf("Shadow mode disabled: %v", err)
+			return processor
+		}
+		processor.WithShadowClassifier(cfg.Classification.Shadow, shadow, shadowRepo)
+		log.Printf("Shadow classifier %q enabled", cfg.Classification.Shadow)
+	}
+
+	return processor
+}
+
+// runSanityReport samples n stored stocks, checks them against the domain invariants,
+// and logs a one-line report. The violation rate is published as an expvar metric.
+func runSanityReport(ctx context.Context, n int) {
+	report, err := service.NewSanitySampler(repo).Run(ctx, n)
+	if err != nil {
+		log.Printf("Error running data sanity report: %v", err)
+		return
+	}
+	if report.Violating > 0 {
+		log.Printf("Data sanity report found violations: %s", report)
+		return
+	}
+	log.Printf("Data sanity report: %s", report)
+}
+
+// exportRecommendations generates the current recommendations with the given strategy and
+// version of the scoring weights (zero for the one in effect), and publishes them as a
+// snapshot. It is meant to be scheduled by cron.
+func exportRecommendations(ctx context.Context, strategy string, version int, format, dest string, limit int) error {
+	stocks, err := stockService.FindAllStocks(ctx, "time DESC", 1, domain.RecommendationPoolSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving stocks: %w", err)
+	}
+
+	weights, err := scoringWeights.GetWeights(ctx, version)
+	if err != nil {
+		return fmt.Errorf("error retrieving scoring weights: %w", err)
+	}
+
+	recommendations, err := service.NewBestInvestmentsService().WithScoringWeights(weights).GetStockRecommendationsWithStrategy(stocks, limit, strategy)
+	if err != nil {
+		return err
+	}
+
+	snapshot := exporter.Snapshot{
+		GeneratedAt:     time.Now().UTC(),
+		Strategy:        strategy,
+		WeightsVersion:  weights.Version,
+		Recommendations: recommendations,
+	}
+	data, contentType, err := exporter.Encode(snapshot, format)
+	if err != nil {
+		return err
+	}
+
+	dest, err = exporter.Publish(ctx, dest, data, contentType, snapshot.GeneratedAt)
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %d recommendations to %s", len(recommendations), dest)
+	return nil
+}
+
+// setupBatchProcessor initializes and runs the batch processor in a goroutine.
+// It processes stocks using the external API client and classification service.
+// The done channel is closed when processing is finished.
+func setupBatchProcessor(cfg *config.Config, done chan struct{}) {
+	processor := newBatchProcessor(cfg)
 
 	go func() {
 		defer close(done) // Closes the channel when the process finishes
@@ -139,42 +362,130 @@ func setupBatchProcessor(cfg *config.Config, done chan struct{}) {
 // It also handles graceful shutdown on interrupt signals.
 func main() {
 	flag.Parse()
+
+	// Registered first so it runs after every other deferred cleanup
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
-	// Initialize the database connection
-	db, err := infrastructure.NewDatabaseConnection(cfg.DB)
-	if err != nil {
-		log.Println("Error connecting to database:", err)
-		return // Ensure deferred functions are executed
+	// Sandbox mode serves synthetic data from process memory, with no database and no
+	// external calls
+	if cfg.Sandbox.Enabled {
+		if *migrate_dir != "" || *mode != "api" {
+			log.Println("Sandbox mode only runs the API, without migrations")
+			exitCode = 1
+			return
+		}
+		log.Printf("Sandbox mode enabled, seeding %d synthetic stocks", cfg.Sandbox.Stocks)
 	}
-	sqlDB, err := db.DB()
-	if err != nil {
-		log.Println("Error getting database instance:", err)
-		return // Ensure deferred functions are executed
+
+	var (
+		db         *gorm.DB
+		sqlDB      *sql.DB
+		dispatcher *service.WebhookDispatcher
+	)
+	if !cfg.Sandbox.Enabled {
+		// Initialize the database connection
+		db, err = infrastructure.NewDatabaseConnection(cfg.DB)
+		if err != nil {
+			log.Println("Error connecting to database:", err)
+			exitCode = 1
+			return // Ensure deferred functions are executed
+		}
+		sqlDB, err = db.DB()
+		if err != nil {
+			log.Println("Error getting database instance:", err)
+			exitCode = 1
+			return // Ensure deferred functions are executed
+		}
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Error closing database connection: %v", err)
+			}
+		}()
+		log.Println("Database connection established")
+
+		// Initialize the webhook dispatcher. Deliveries still in flight are awaited on
+		// shutdown, after the event bus has been drained.
+		webhookRepo = repository.NewWebhookRepository(db)
+		dispatcher = service.NewWebhookDispatcher(webhookRepo)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := dispatcher.Wait(ctx); err != nil {
+				log.Printf("Error waiting for webhook deliveries: %v", err)
+			}
+		}()
 	}
+
+	// Initialize the event bus shared by the repository and the batch processor
+	bus = eventbus.New(cfg.EventBus.BufferSize)
 	defer func() {
-		if err := sqlDB.Close(); err != nil {
-			log.Printf("Error closing database connection: %v", err)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := bus.Close(ctx); err != nil {
+			log.Printf("Error draining event bus: %v", err)
 		}
 	}()
-	log.Println("Database connection established")
+	if dispatcher != nil {
+		eventbus.Subscribe(bus, eventbus.StockWrites, dispatcher.HandleStockWrite)
+	}
 
-	// Initialize the repository
-	repo = repository.NewStockBDRepository(db)
-	if repo == nil {
-		log.Println("Error initializing repository")
-		return
+	// Initialize the repositories. Stocks live in the SQL database, except in sandbox mode,
+	// which keeps everything in process memory. Sorting by score uses the weights in effect.
+	if cfg.Sandbox.Enabled {
+		auditRepo = repository.NewAuditMemoryRepository()
+		schemaRepo = repository.MemorySchemaInspector{}
+		scoreHistoryRepo = repository.NewScoreHistoryMemoryRepository()
+		scoringWeights = service.NewScoringWeightsService(repository.NewScoringWeightMemoryRepository(), service.DefaultScoringWeightsTTL)
+		repo = repository.NewStockMemoryRepository(bus).WithScoringWeights(scoringWeights)
+		apiClient = service.NewSyntheticAPIClient(cfg.Sandbox.Stocks, sandboxSeed)
+	} else {
+		auditRepo = repository.NewAuditRepository(db)
+		shadowRepo = repository.NewShadowClassificationRepository(db)
+		schemaRepo = repository.NewSchemaRepository(db)
+		scoreHistoryRepo = repository.NewScoreHistoryRepository(db)
+		scoringWeights = service.NewScoringWeightsService(repository.NewScoringWeightRepository(db), service.DefaultScoringWeightsTTL)
+		stockRepo := repository.NewStockBDRepository(db, bus).WithScoringWeights(scoringWeights)
+		repo = stockRepo
+		apiClient = service.NewExternalAPIClient(cfg.ExternalAPI.URL)
+
+		// Other replicas must learn about the last writes before the connection is closed
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := stockRepo.Flush(ctx); err != nil {
+				log.Printf("Error flushing stock writes: %v", err)
+			}
+		}()
 	}
 	log.Println("Repository initialized")
 
 	// Initialize the service
-	stockService = service.NewStockService(repo, repository.NewGormFieldValidator(&domain.Stock{}))
+	labelPolicy, err := domain.ParseUnknownLabelPolicy(cfg.Classification.UnknownLabelPolicy)
+	if err != nil {
+		log.Println("Error initializing service:", err)
+		exitCode = 1
+		return
+	}
+	stockService = service.NewStockService(repo, newFieldValidator()).
+		WithLabelPolicy(labelPolicy).
+		WithPaginationLimits(domain.PaginationLimits{
+			DefaultPageSize: cfg.Pagination.DefaultPageSize,
+			MaxPageSize:     cfg.Pagination.MaxPageSize,
+		})
 	if stockService == nil {
 		log.Println("Error initializing service")
+		exitCode = 1
 		return
 	}
 	log.Println("Service initialized")
@@ -185,8 +496,26 @@ func main() {
 
 		if err := RunMigrations(cfg, sqlDB, *migrate_dir); err != nil {
 			log.Printf("Error running migrations: %v", err)
+			exitCode = 1
 			return
 		}
+
+		// Fill the columns derived by the application on rows written before they existed
+		if *migrate_dir == "up" {
+			backfillRepo := repository.NewStockBDRepository(db, nil)
+			updated, err := backfillRepo.BackfillCompanyNormalized(context.Background(), 0)
+			if err != nil {
+				log.Printf("Error backfilling normalized company names: %v", err)
+				exitCode = 1
+				return
+			}
+			if err := backfillRepo.Flush(context.Background()); err != nil {
+				log.Printf("Error backfilling normalized company names: %v", err)
+				exitCode = 1
+				return
+			}
+			log.Printf("Backfilled normalized company names of %d stocks", updated)
+		}
 		log.Println("Migrations completed")
 		return
 	}
@@ -197,6 +526,7 @@ func main() {
 		zapLogger, err := zap.NewProduction()
 		if err != nil {
 			log.Printf("Failed to initialize zap logger: %v", err)
+			exitCode = 1
 			return
 		}
 		defer func() {
@@ -205,16 +535,42 @@ func main() {
 			}
 		}()
 
+		// Seed the sandbox before serving, so the API starts with data
+		if cfg.Sandbox.Enabled {
+			if err := newBatchProcessor(cfg).ProcessStocks(context.Background()); err != nil {
+				log.Printf("Error seeding sandbox: %v", err)
+				exitCode = 1
+				return
+			}
+		}
+
 		router := setupRouter(cfg, zapLogger)
 
 		// Setting up the routes
-		setupRoutes(router)
+		setupRoutes(cfg, router, sqlDB)
+
+		// A running reclassification job and ingestion run are given time to finish on
+		// shutdown, before the event bus and the database connection are closed
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := reclassifier.Wait(ctx); err != nil {
+				log.Printf("Error waiting for reclassification job: %v", err)
+			}
+			if err := adminHandler.Wait(ctx); err != nil {
+				log.Printf("Error waiting for ingestion run: %v", err)
+			}
+		}()
 
-		// HTTP Server with graceful shutdown
+		// HTTP Server with graceful shutdown. Slow clients are bounded by the read and
+		// write timeouts, slow handlers by the request deadline set by the middleware.
 		srv := &http.Server{
 			Addr:              fmt.Sprintf("%s:%d", cfg.Server.URL, cfg.Server.Port),
 			Handler:           router,
 			ReadHeaderTimeout: 10 * time.Second, // Add a timeout for reading headers
+			ReadTimeout:       cfg.Server.ReadTimeout,
+			WriteTimeout:      cfg.Server.WriteTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
 		}
 
 		go func() {
@@ -223,6 +579,15 @@ func main() {
 			}
 		}()
 		log.Printf("Server started on port %d", cfg.Server.Port)
+
+		// Check a sample of the stored data in the background
+		if cfg.Sanity.SampleSize > 0 {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+				runSanityReport(ctx, cfg.Sanity.SampleSize)
+			}()
+		}
 	case "data":
 		// Setting up the batch processor
 		done := make(chan struct{}) // Channel to coordinate shutdown
@@ -232,6 +597,23 @@ func main() {
 		// Wait for the goroutine to finish
 		<-done
 		log.Println("Batch processor finished")
+	case "sanity":
+		n := *sampleSize
+		if n <= 0 {
+			n = cfg.Sanity.SampleSize
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		runSanityReport(ctx, n)
+		return
+	case "export":
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if err := exportRecommendations(ctx, *strategy, *weightsVersion, *exportFormat, *exportOut, *exportLimit); err != nil {
+			log.Printf("Error exporting recommendations: %v", err)
+			exitCode = 1
+		}
+		return
 	default:
 		return
 	}
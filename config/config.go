@@ -1,12 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"stock-api/infrastructure/core/service"
 )
 
 // ExternalAPIConfig holds the configuration for an external API.
@@ -24,9 +28,17 @@ type ExternalAPIConfig struct {
 // Fields:
 // - URL: The base URL of the server.
 // - Port: The port on which the server listens.
+// - ReadTimeout: The maximum duration for reading a whole request, including its body.
+// - WriteTimeout: The maximum duration from the end of the request headers to the end of the response. It must exceed RequestTimeout, so timeout responses can still be written.
+// - IdleTimeout: The maximum time an idle keep-alive connection is kept open.
+// - RequestTimeout: The deadline set on the context of every request, propagated to the repositories and external calls. Zero disables it.
 type ServerConfig struct {
-	URL  string
-	Port int
+	URL            string
+	Port           int
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	RequestTimeout time.Duration
 }
 
 // DBConfig holds the configuration for the database connection.
@@ -50,16 +62,99 @@ type DBConfig struct {
 	TimeZone string
 }
 
+// SandboxConfig holds the configuration of sandbox mode, which runs the API with synthetic
+// data and no infrastructure, for frontend development.
+// Fields:
+// - Enabled: Whether sandbox mode is on. It stores everything in process memory and makes no external calls.
+// - Stocks: The number of synthetic analyst events seeded on boot.
+type SandboxConfig struct {
+	Enabled bool
+	Stocks  int
+}
+
+// EventBusConfig holds the configuration for the in-process event bus.
+// Fields:
+// - BufferSize: The number of pending events each subscriber can queue before new events are dropped.
+type EventBusConfig struct {
+	BufferSize int
+}
+
+// AdminConfig holds the configuration for the administrative API.
+// Fields:
+// - Tokens: A map of admin bearer token to the actor name recorded in the audit log.
+type AdminConfig struct {
+	Tokens map[string]string
+}
+
+// ClassificationConfig holds the configuration for stock classification.
+// Fields:
+// - Active: The name of the classifier that labels stocks during ingestion and reclassify jobs. Defaults to the default classifier; candidates run in shadow mode first.
+// - Shadow: The name of a candidate classifier run in shadow mode during ingestion. Empty disables shadow mode.
+// - UnknownLabelPolicy: How client-supplied labels missing from the registry are handled ("deny", "namespace", or "allow").
+type ClassificationConfig struct {
+	Active             string
+	Shadow             string
+	UnknownLabelPolicy string
+}
+
+// PaginationConfig holds the page size limits of list endpoints.
+// Fields:
+// - DefaultPageSize: The page size applied when a client omits it.
+// - MaxPageSize: The largest page size a client can request; larger requests are clamped.
+type PaginationConfig struct {
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+// SanityConfig holds the configuration of the data sanity report.
+// Fields:
+// - SampleSize: The number of stored stocks checked against the domain invariants on boot. Zero disables the check.
+type SanityConfig struct {
+	SampleSize int
+}
+
+// RecommendationsConfig holds the configuration of the recommendations endpoint.
+// Fields:
+// - SnapshotPath: The file where the latest recommendations are kept, to serve them while the database is unavailable. Empty disables the snapshot.
+type RecommendationsConfig struct {
+	SnapshotPath string
+}
+
+// LoadSheddingConfig holds the configuration of load shedding.
+// Fields:
+// - LowThreshold: The occupancy of the busiest resource, from 0 to 1, from which bulk list queries are shed.
+// - NormalThreshold: The occupancy from which recommendations and single-stock reads are shed. It must not be below LowThreshold.
+type LoadSheddingConfig struct {
+	LowThreshold    float64
+	NormalThreshold float64
+}
+
 // Config holds the overall application configuration.
 // Fields:
 // - ExternalAPI: Configuration for the external API.
 // - Server: Configuration for the server.
 // - DB: Configuration for the database.
+// - Sandbox: Configuration of sandbox mode.
+// - EventBus: Configuration for the in-process event bus.
+// - Admin: Configuration for the administrative API.
+// - Classification: Configuration for stock classification.
+// - Pagination: Page size limits of list endpoints.
+// - Sanity: Configuration of the data sanity report.
+// - Recommendations: Configuration of the recommendations endpoint.
+// - LoadShedding: Occupancy thresholds from which requests are shed.
 type Config struct {
-	AllowedOrigins []string
-	ExternalAPI    ExternalAPIConfig
-	Server         ServerConfig
-	DB             DBConfig
+	AllowedOrigins  []string
+	ExternalAPI     ExternalAPIConfig
+	Server          ServerConfig
+	DB              DBConfig
+	Sandbox         SandboxConfig
+	EventBus        EventBusConfig
+	Admin           AdminConfig
+	Classification  ClassificationConfig
+	Pagination      PaginationConfig
+	Sanity          SanityConfig
+	Recommendations RecommendationsConfig
+	LoadShedding    LoadSheddingConfig
 }
 
 // LoadConfig loads the application configuration from environment variables or a .env file.
@@ -86,12 +181,78 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// Parse the server timeouts.
+	readTimeout, err := time.ParseDuration(getEnv("SERVER_READ_TIMEOUT", "15s"))
+	if err != nil {
+		return nil, err
+	}
+	writeTimeout, err := time.ParseDuration(getEnv("SERVER_WRITE_TIMEOUT", "30s"))
+	if err != nil {
+		return nil, err
+	}
+	idleTimeout, err := time.ParseDuration(getEnv("SERVER_IDLE_TIMEOUT", "60s"))
+	if err != nil {
+		return nil, err
+	}
+	requestTimeout, err := time.ParseDuration(getEnv("SERVER_REQUEST_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, err
+	}
+	if writeTimeout > 0 && requestTimeout >= writeTimeout {
+		return nil, fmt.Errorf("SERVER_REQUEST_TIMEOUT (%s) must be shorter than SERVER_WRITE_TIMEOUT (%s)", requestTimeout, writeTimeout)
+	}
+
 	// Parse the database port.
 	dbPort, err := strconv.Atoi(getEnv("DB_PORT", "5432"))
 	if err != nil {
 		return nil, err
 	}
 
+	// Parse the sandbox mode settings.
+	sandbox, err := strconv.ParseBool(getEnv("SANDBOX", "false"))
+	if err != nil {
+		return nil, err
+	}
+	sandboxStocks, err := strconv.Atoi(getEnv("SANDBOX_STOCKS", "200"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the event bus buffer size.
+	eventBufferSize, err := strconv.Atoi(getEnv("EVENT_BUS_BUFFER_SIZE", "256"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the pagination limits.
+	defaultPageSize, err := strconv.Atoi(getEnv("PAGINATION_DEFAULT_PAGE_SIZE", "20"))
+	if err != nil {
+		return nil, err
+	}
+	maxPageSize, err := strconv.Atoi(getEnv("PAGINATION_MAX_PAGE_SIZE", "500"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the sanity report sample size.
+	sanitySampleSize, err := strconv.Atoi(getEnv("SANITY_SAMPLE_SIZE", "500"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the load shedding thresholds.
+	lowShedThreshold, err := strconv.ParseFloat(getEnv("LOAD_SHEDDING_LOW_THRESHOLD", "0.5"), 64)
+	if err != nil {
+		return nil, err
+	}
+	normalShedThreshold, err := strconv.ParseFloat(getEnv("LOAD_SHEDDING_NORMAL_THRESHOLD", "0.9"), 64)
+	if err != nil {
+		return nil, err
+	}
+	if lowShedThreshold <= 0 || normalShedThreshold > 1 || lowShedThreshold > normalShedThreshold {
+		return nil, fmt.Errorf("load shedding thresholds must satisfy 0 < LOAD_SHEDDING_LOW_THRESHOLD (%g) <= LOAD_SHEDDING_NORMAL_THRESHOLD (%g) <= 1", lowShedThreshold, normalShedThreshold)
+	}
+
 	// Initialize the configuration struct.
 	cfg := &Config{
 		AllowedOrigins: splitAndTrim(getEnv("ALLOWED_ORIGINS", "127.0.0.1")),
@@ -101,8 +262,12 @@ func LoadConfig() (*Config, error) {
 			BatchSize: batchSize,
 		},
 		Server: ServerConfig{
-			URL:  getEnv("SERVER_URL", "https://app.example.com"),
-			Port: port,
+			URL:            getEnv("SERVER_URL", "https://app.example.com"),
+			Port:           port,
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			IdleTimeout:    idleTimeout,
+			RequestTimeout: requestTimeout,
 		},
 		DB: DBConfig{
 			DBType:   getEnv("DB_TYPE", "cockroachdb"),
@@ -114,6 +279,35 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 			TimeZone: "UTC",
 		},
+		Sandbox: SandboxConfig{
+			Enabled: sandbox,
+			Stocks:  sandboxStocks,
+		},
+		EventBus: EventBusConfig{
+			BufferSize: eventBufferSize,
+		},
+		Admin: AdminConfig{
+			Tokens: parseAdminTokens(getEnv("ADMIN_TOKENS", "")),
+		},
+		Classification: ClassificationConfig{
+			Active:             getEnv("CLASSIFIER", service.DefaultClassifier),
+			Shadow:             getEnv("SHADOW_CLASSIFIER", ""),
+			UnknownLabelPolicy: getEnv("UNKNOWN_LABEL_POLICY", "deny"),
+		},
+		Pagination: PaginationConfig{
+			DefaultPageSize: defaultPageSize,
+			MaxPageSize:     maxPageSize,
+		},
+		Sanity: SanityConfig{
+			SampleSize: sanitySampleSize,
+		},
+		Recommendations: RecommendationsConfig{
+			SnapshotPath: getEnv("RECOMMENDATIONS_SNAPSHOT_PATH", ""),
+		},
+		LoadShedding: LoadSheddingConfig{
+			LowThreshold:    lowShedThreshold,
+			NormalThreshold: normalShedThreshold,
+		},
 	}
 
 	return cfg, nil
@@ -135,6 +329,21 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parseAdminTokens parses a comma-separated list of "actor:token" pairs into a map of
+// token to actor. Malformed pairs are skipped.
+func parseAdminTokens(s string) map[string]string {
+	tokens := make(map[string]string)
+	for _, pair := range splitAndTrim(s) {
+		actor, token, ok := strings.Cut(pair, ":")
+		if !ok || actor == "" || token == "" {
+			log.Printf("Ignoring malformed admin token entry for %q", actor)
+			continue
+		}
+		tokens[token] = actor
+	}
+	return tokens
+}
+
 // splitAndTrim splits a comma-separated string and trims spaces from each element.
 func splitAndTrim(s string) []string {
 	return strings.FieldsFunc(s, func(r rune) bool {
@@ -0,0 +1,142 @@
+// Package exporter writes recommendation snapshots as CSV or JSON to a local file, to
+// standard output, or to a bucket through a pre-signed upload URL. It backs the "export"
+// mode of the command, which is meant to be run by cron.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-api/infrastructure/atomicfile"
+	"stock-api/infrastructure/core/domain"
+)
+
+// Supported export formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// TimestampPlaceholder is replaced in destinations by the UTC generation time, so each
+// scheduled run can write a new file (e.g., "exports/recommendations-{timestamp}.csv").
+const TimestampPlaceholder = "{timestamp}"
+
+// Snapshot is a set of recommendations generated at a point in time. WeightsVersion is
+// the version of the scoring weights they were ranked with, zero for the built-in
+// weights; the CSV format leaves it out to keep its columns stable.
+type Snapshot struct {
+	GeneratedAt     time.Time               `json:"generatedAt"`
+	Strategy        string                  `json:"strategy"`
+	WeightsVersion  int                     `json:"weightsVersion,omitempty"`
+	Recommendations []domain.Recommendation `json:"recommendations"`
+}
+
+// csvHeader lists the CSV columns. Every row repeats the snapshot metadata so the file
+// stays self-describing when rows are appended to a spreadsheet.
+var csvHeader = []string{"generated_at", "strategy", "position", "ticker", "company", "score", "rationale"}
+
+// Encode serializes the snapshot in the given format.
+//
+// Returns:
+//   - The encoded snapshot.
+//   - The content type of the encoding.
+//   - An error if the format is not supported.
+func Encode(snapshot Snapshot, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snapshot); err != nil {
+			return nil, "", fmt.Errorf("error encoding JSON: %w", err)
+		}
+		return buf.Bytes(), "application/json", nil
+	case FormatCSV:
+		if err := writeCSV(&buf, snapshot); err != nil {
+			return nil, "", fmt.Errorf("error encoding CSV: %w", err)
+		}
+		return buf.Bytes(), "text/csv", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported export format: %s (use %s or %s)", format, FormatCSV, FormatJSON)
+	}
+}
+
+func writeCSV(w io.Writer, snapshot Snapshot) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	generatedAt := snapshot.GeneratedAt.UTC().Format(time.RFC3339)
+	for _, r := range snapshot.Recommendations {
+		record := []string{
+			generatedAt,
+			snapshot.Strategy,
+			strconv.Itoa(r.Position),
+			r.Ticker,
+			r.Company,
+			strconv.FormatFloat(r.Score, 'f', 2, 64),
+			r.Rationale,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Publish writes data to dest, after expanding TimestampPlaceholder with generatedAt:
+//   - "-" writes to standard output.
+//   - An http(s) URL is uploaded with PUT, which works with the pre-signed upload URLs
+//     of S3, GCS, and Azure Blob Storage.
+//   - Anything else is a local path, written atomically (through a temporary file in the
+//     same directory) so readers of a shared drive never see a partial export.
+//
+// It returns the expanded destination.
+func Publish(ctx context.Context, dest string, data []byte, contentType string, generatedAt time.Time) (string, error) {
+	dest = strings.ReplaceAll(dest, TimestampPlaceholder, generatedAt.UTC().Format("20060102T150405Z"))
+
+	switch {
+	case dest == "-":
+		_, err := os.Stdout.Write(data)
+		return dest, err
+	case strings.HasPrefix(dest, "http://"), strings.HasPrefix(dest, "https://"):
+		return dest, upload(ctx, dest, data, contentType)
+	default:
+		if err := atomicfile.Write(dest, data); err != nil {
+			return dest, fmt.Errorf("error writing export: %w", err)
+		}
+		return dest, nil
+	}
+}
+
+func upload(ctx context.Context, url string, data []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading export: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("upload returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
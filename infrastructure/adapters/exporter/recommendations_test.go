@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func testSnapshot() Snapshot {
+	return Snapshot{
+		GeneratedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Strategy:    "score",
+		Recommendations: []domain.Recommendation{
+			{Position: 1, Ticker: "AAPL", Company: "Apple, Inc.", Score: 78.456, Rationale: "Potential of 15.0%, Recent upgrade"},
+		},
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("should encode CSV with quoted fields", func(t *testing.T) {
+		data, contentType, err := Encode(testSnapshot(), FormatCSV)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "text/csv", contentType)
+		assert.Equal(t,
+			"generated_at,strategy,position,ticker,company,score,rationale\n"+
+				"2025-03-01T12:00:00Z,score,1,AAPL,\"Apple, Inc.\",78.46,\"Potential of 15.0%, Recent upgrade\"\n",
+			string(data))
+	})
+
+	t.Run("should encode JSON", func(t *testing.T) {
+		data, contentType, err := Encode(testSnapshot(), FormatJSON)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", contentType)
+		var decoded Snapshot
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, testSnapshot(), decoded)
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		_, _, err := Encode(testSnapshot(), "xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestPublish(t *testing.T) {
+	generatedAt := testSnapshot().GeneratedAt
+
+	t.Run("should write files with the timestamp expanded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		dest, err := Publish(context.Background(), filepath.Join(dir, "out", "recs-{timestamp}.csv"), []byte("data"), "text/csv", generatedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "out", "recs-20250301T120000Z.csv"), dest)
+		content, err := os.ReadFile(dest)
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(content))
+
+		entries, _ := os.ReadDir(filepath.Join(dir, "out"))
+		assert.Len(t, entries, 1, "no temporary file should be left behind")
+	})
+
+	t.Run("should upload to URLs with PUT", func(t *testing.T) {
+		var method, contentType, body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, _ := io.ReadAll(r.Body)
+			method, contentType, body = r.Method, r.Header.Get("Content-Type"), string(raw)
+		}))
+		defer server.Close()
+
+		_, err := Publish(context.Background(), server.URL+"/bucket/recs.json", []byte("{}"), "application/json", generatedAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, method)
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, "{}", body)
+	})
+
+	t.Run("should fail on rejected uploads", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		_, err := Publish(context.Background(), server.URL, []byte("{}"), "application/json", generatedAt)
+		assert.ErrorContains(t, err, "403")
+	})
+}
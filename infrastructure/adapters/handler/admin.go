@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// AdminHandler serves the administrative endpoints under /api/v1/admin.
+// Every state-changing endpoint names its action with middleware.SetAuditAction,
+// so the audit log records it with the acting admin.
+type AdminHandler struct {
+	audit     port.AuditRepository
+	ingestion port.IngestionRunner
+	caches    []port.CachePurger
+	ingesting atomic.Bool
+	runs      sync.WaitGroup // Ingestion runs started from the API
+}
+
+// NewAdminHandler creates a new instance of AdminHandler.
+// caches lists every cache flushed by the cache purge endpoint.
+func NewAdminHandler(audit port.AuditRepository, ingestion port.IngestionRunner, caches ...port.CachePurger) *AdminHandler {
+	return &AdminHandler{audit: audit, ingestion: ingestion, caches: caches}
+}
+
+// PurgeCaches handles the HTTP request to flush every in-memory cache
+// (count results, recommendation scores, and scoring weights).
+//
+// Responses:
+// - 200: Returns the number of caches purged.
+func (h *AdminHandler) PurgeCaches(c *gin.Context) {
+	for _, cache := range h.caches {
+		cache.PurgeCache()
+	}
+
+	middleware.SetAuditAction(c, "cache.purge", gin.H{"caches": len(h.caches)})
+	response.Success(c, http.StatusOK, gin.H{"purged": len(h.caches)})
+}
+
+// TriggerIngestion handles the HTTP request to start an ingestion run in the background.
+// Only one run can be in progress at a time.
+//
+// Responses:
+// - 202: The run has started.
+// - 409: A run triggered from the API is already in progress.
+func (h *AdminHandler) TriggerIngestion(c *gin.Context) {
+	middleware.SetAuditAction(c, "ingestion.trigger", nil)
+
+	if !h.ingesting.CompareAndSwap(false, true) {
+		response.Error(c, http.StatusConflict, "Ingestion already running")
+		return
+	}
+
+	h.runs.Add(1)
+	go func() {
+		defer h.runs.Done()
+		defer h.ingesting.Store(false)
+		if err := h.ingestion.ProcessStocks(context.Background()); err != nil {
+			log.Printf("Error processing stocks: %v", err)
+		}
+	}()
+
+	response.Success(c, http.StatusAccepted, gin.H{"status": "started"})
+}
+
+// Wait blocks until the ingestion run started from the API, if any, has finished or ctx
+// is done.
+func (h *AdminHandler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListAuditLog handles the HTTP request to read the audit log, newest entries first.
+//
+// Query Parameters:
+// - page: (optional) The page number, 1 by default.
+// - pageSize: (optional) The number of entries per page, 50 by default.
+//
+// Responses:
+// - 200: Returns the requested page of audit entries.
+// - 400: The pagination parameters are invalid.
+// - 500: The audit log could not be read.
+func (h *AdminHandler) ListAuditLog(c *gin.Context) {
+	page, errPage := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, errSize := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if errPage != nil || errSize != nil || page <= 0 || pageSize <= 0 {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+
+	entries, err := h.audit.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve audit log")
+		return
+	}
+
+	response.Success(c, http.StatusOK, entries)
+}
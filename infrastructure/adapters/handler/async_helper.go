@@ -1,12 +1,28 @@
 package handler
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/response"
+)
+
+// Errors returned by AsyncOperation and AsyncManyOperation.
+var (
+	ErrOperationTimeout   = errors.New("operation timeout")
+	ErrClientDisconnected = errors.New("client disconnected")
+	ErrServerBusy         = errors.New("server busy")
 )
 
+// defaultOperationTimeout bounds asynchronous operations of requests without a deadline.
+// Requests served behind middleware.RequestTimeout wait until their own deadline instead.
+const defaultOperationTimeout = 5 * time.Second
+
 // ZeroValue returns the zero value for any type T.
 // This is useful for returning a default value in case of errors or timeouts.
 //
@@ -47,9 +63,10 @@ type AsyncResult[T any] struct {
 //   - T: The result of the operation, or the zero value of T in case of error.
 //   - error: An error if the operation fails, times out, the client disconnects, or the server is busy.
 //
-// The function waits up to 5 seconds for the operation to complete. If the operation does not complete
-// within this time, it returns a timeout error. If the client disconnects before the operation completes,
-// it returns a client disconnected error. If the worker pool is full, it returns a server busy error.
+// The function waits for the operation until the deadline of the request context, or up to 5 seconds
+// if the request has none. If the operation does not complete within this time, it returns
+// ErrOperationTimeout. If the client disconnects before the operation completes, it returns
+// ErrClientDisconnected. If the worker pool is full, it returns ErrServerBusy.
 func AsyncOperation[T any](
 	c *gin.Context,
 	workerPool chan struct{},
@@ -66,23 +83,25 @@ func AsyncOperation[T any](
 			resultChan <- AsyncResult[T]{Result: result, Error: err}
 		}()
 
+		ctx, cancel := operationContext(c)
+		defer cancel()
+
 		select {
 		case res := <-resultChan:
 			return res.Result, res.Error
-		case <-time.After(5 * time.Second):
-			return ZeroValue[T](), fmt.Errorf("operation timeout")
-		case <-c.Request.Context().Done():
-			return ZeroValue[T](), fmt.Errorf("client disconnected")
+		case <-ctx.Done():
+			return ZeroValue[T](), operationError(ctx)
 		}
 	default:
-		return ZeroValue[T](), fmt.Errorf("server busy")
+		return ZeroValue[T](), ErrServerBusy
 	}
 }
 
 // AsyncManyOperation executes the provided operation asynchronously using a worker pool,
 // and returns its result, count, and error. It leverages Go generics to support any result type.
 // The function ensures that the number of concurrent operations does not exceed the worker pool capacity.
-// It waits for the operation to complete, a timeout (5 seconds), or client disconnection, whichever comes first.
+// It waits for the operation to complete, the request deadline (5 seconds if the request has none), or
+// client disconnection, whichever comes first.
 //
 // Parameters:
 //   - c: The Gin context, used to detect client disconnection.
@@ -95,9 +114,9 @@ func AsyncOperation[T any](
 //   - error: An error if the operation failed, timed out, the client disconnected, or the server is busy.
 //
 // Possible errors:
-//   - "operation timeout": If the operation does not complete within 5 seconds.
-//   - "client disconnected": If the client disconnects before the operation completes.
-//   - "server busy": If the worker pool is full and cannot accept new operations.
+//   - ErrOperationTimeout: If the operation does not complete before the deadline.
+//   - ErrClientDisconnected: If the client disconnects before the operation completes.
+//   - ErrServerBusy: If the worker pool is full and cannot accept new operations.
 func AsyncManyOperation[T any](
 	c *gin.Context,
 	workerPool chan struct{},
@@ -114,15 +133,49 @@ func AsyncManyOperation[T any](
 			resultChan <- AsyncResult[T]{Result: result, Count: count, Error: err}
 		}()
 
+		ctx, cancel := operationContext(c)
+		defer cancel()
+
 		select {
 		case res := <-resultChan:
 			return res.Result, res.Count, res.Error
-		case <-time.After(5 * time.Second):
-			return ZeroValue[T](), 0, fmt.Errorf("operation timeout")
-		case <-c.Request.Context().Done():
-			return ZeroValue[T](), 0, fmt.Errorf("client disconnected")
+		case <-ctx.Done():
+			return ZeroValue[T](), 0, operationError(ctx)
 		}
 	default:
-		return ZeroValue[T](), 0, fmt.Errorf("server busy")
+		return ZeroValue[T](), 0, ErrServerBusy
+	}
+}
+
+// operationContext returns the context bounding the wait for an asynchronous operation:
+// the request context, with defaultOperationTimeout applied if it has no deadline.
+func operationContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx := c.Request.Context()
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, defaultOperationTimeout)
+}
+
+// operationError tells a timeout from a client disconnection once ctx is done.
+func operationError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrOperationTimeout
+	}
+	return ErrClientDisconnected
+}
+
+// respondAsyncError answers a request whose asynchronous operation failed: 504 if the
+// operation ran out of time, 400 if the filters are rejected or the data cannot be read at
+// the requested past time, and 500 with message otherwise.
+func respondAsyncError(c *gin.Context, err error, message string) {
+	if errors.Is(err, ErrOperationTimeout) {
+		response.Error(c, http.StatusGatewayTimeout, "Request timed out")
+		return
+	}
+	if errors.Is(err, domain.ErrAsOfUnsupported) || errors.Is(err, domain.ErrInvalidFilter) {
+		response.BadRequest(c, err.Error())
+		return
 	}
+	response.InternalServerError(c, message)
 }
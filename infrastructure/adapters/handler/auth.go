@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/response"
+)
+
+// WhoAmI handles the HTTP request to introspect the caller's token. It returns the
+// authenticated principal, its roles, its rate-limit tier, and its quota usage, so
+// client developers can debug permission issues without reading server logs.
+// It must run behind an authentication middleware.
+//
+// Responses:
+// - 200: Returns the authenticated principal.
+// - 401: The request is not authenticated.
+func WhoAmI(c *gin.Context) {
+	principal, ok := c.Get(middleware.PrincipalKey)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	response.Success(c, http.StatusOK, principal.(domain.Principal))
+}
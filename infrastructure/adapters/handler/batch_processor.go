@@ -8,12 +8,20 @@ import (
 
 	"stock-api/infrastructure/core/domain"
 	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/eventbus"
 )
 
 type BatchProcessor struct {
 	apiClient             port.APIClient
 	repo                  port.StockRepository
 	classificationService port.ClassificationService
+	bus                   *eventbus.Bus
+	// Shadow mode (optional)
+	shadowName       string
+	shadowClassifier port.ClassificationService
+	shadowRepo       port.ShadowClassificationRepository
+	// Score history (optional)
+	scoreRecorder port.ScoreRecorder
 	// Configuration
 	batchSize int
 	jwtToken  string
@@ -25,6 +33,7 @@ func NewBatchProcessor(
 	apiClient port.APIClient,
 	repo port.StockRepository,
 	classificationService port.ClassificationService,
+	bus *eventbus.Bus,
 	batchSize int,
 	token string,
 	apiDelay time.Duration,
@@ -33,6 +42,7 @@ func NewBatchProcessor(
 		apiClient:             apiClient,
 		repo:                  repo,
 		classificationService: classificationService,
+		bus:                   bus,
 		// Configuration
 		batchSize: batchSize,
 		jwtToken:  token,
@@ -40,20 +50,78 @@ func NewBatchProcessor(
 	}
 }
 
-// ProcessStocks processes paginated stocks by ticker
+// WithShadowClassifier enables shadow mode: every saved batch is also classified by the
+// given candidate classifier, and its labels are stored through shadowRepo without
+// touching the stocks. Failures in shadow mode are logged and never abort ingestion.
+func (bp *BatchProcessor) WithShadowClassifier(
+	name string,
+	classifier port.ClassificationService,
+	shadowRepo port.ShadowClassificationRepository,
+) *BatchProcessor {
+	bp.shadowName = name
+	bp.shadowClassifier = classifier
+	bp.shadowRepo = shadowRepo
+	return bp
+}
+
+// WithScoreHistory records, after each run, the score of every ticker the run wrote.
+// Failures to record are logged and never fail ingestion.
+func (bp *BatchProcessor) WithScoreHistory(recorder port.ScoreRecorder) *BatchProcessor {
+	bp.scoreRecorder = recorder
+	return bp
+}
+
+// ProcessStocks processes paginated stocks by ticker.
+// Suspicious stocks are flagged with soft validation warnings (see domain.Stock.CheckWarnings)
+// and stored as usual. When the run ends, successfully or not, the scores of the tickers
+// it wrote are recorded and an IngestionRunEvent, counting the warnings, is published on
+// the event bus.
 func (bp *BatchProcessor) ProcessStocks(ctx context.Context) error {
+	startTime := time.Now()
+	written := make(map[string]domain.Stock)
+	var warnings domain.WarningTally
+	total, err := bp.processStocks(ctx, startTime, written, &warnings)
+
+	bp.recordScores(written)
+
+	if warnings.Flagged > 0 {
+		log.Printf("%d stocks flagged with warnings: %v", warnings.Flagged, warnings.Counts)
+	}
+	event := domain.IngestionRunEvent{
+		Total:      total,
+		StartedAt:  startTime.UTC(),
+		Duration:   time.Since(startTime),
+		Warnings:   warnings,
+		OccurredAt: time.Now().UTC(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	eventbus.Publish(bp.bus, eventbus.IngestionCompleted, event)
+
+	return err
+}
+
+// processStocks runs the ingestion loop and returns the number of items processed.
+// The latest saved stock of each ticker is collected in written, and the warnings of the
+// saved stocks are counted in warnings.
+func (bp *BatchProcessor) processStocks(
+	ctx context.Context,
+	startTime time.Time,
+	written map[string]domain.Stock,
+	warnings *domain.WarningTally,
+) (int, error) {
 	var (
 		batch      []*domain.Stock
 		lastTicker string
 		total      int
-		startTime  = time.Now()
 	)
 
 	for {
 		// Fetch data from the API
 		items, nextPage, err := bp.apiClient.FetchStocks(ctx, bp.jwtToken, lastTicker)
 		if err != nil {
-			return fmt.Errorf("error fetching stocks: %w", err)
+			return total, fmt.Errorf("error fetching stocks: %w", err)
 		}
 
 		if len(items) == 0 {
@@ -71,8 +139,10 @@ func (bp *BatchProcessor) ProcessStocks(ctx context.Context) error {
 			bp.classificationService.ClassifyBatch(batch)
 
 			if err := bp.saveStocksBatch(ctx, batch); err != nil {
-				return fmt.Errorf("error saving batch: %w", err)
+				return total, fmt.Errorf("error saving batch: %w", err)
 			}
+			trackLatest(written, batch)
+			warnings.Add(batch...)
 			batch = batch[:0] // Clear the batch while retaining capacity
 		}
 
@@ -87,7 +157,7 @@ func (bp *BatchProcessor) ProcessStocks(ctx context.Context) error {
 		// Wait before the next request
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return total, ctx.Err()
 		case <-time.After(bp.apiDelay):
 			continue
 		}
@@ -100,16 +170,86 @@ func (bp *BatchProcessor) ProcessStocks(ctx context.Context) error {
 
 		// Save the batch after classification
 		if err := bp.saveStocksBatch(ctx, batch); err != nil {
-			return fmt.Errorf("error saving final batch: %w", err)
+			return total, fmt.Errorf("error saving final batch: %w", err)
 		}
+		trackLatest(written, batch)
+		warnings.Add(batch...)
 	}
 
 	log.Printf("Process completed. Total items processed: %d in %v", total, time.Since(startTime))
-	return nil
+	return total, nil
+}
+
+// trackLatest keeps, for each ticker of the batch, a copy of its most recent stock.
+func trackLatest(latest map[string]domain.Stock, batch []*domain.Stock) {
+	for _, stock := range batch {
+		if current, ok := latest[stock.Ticker]; !ok || stock.Time.After(current.Time) {
+			latest[stock.Ticker] = *stock
+		}
+	}
 }
 
-// saveStocksBatch saves a batch of stocks to the repository
+// recordScores records the scores of the stocks written by the run, if score history is
+// enabled.
+func (bp *BatchProcessor) recordScores(written map[string]domain.Stock) {
+	if bp.scoreRecorder == nil || len(written) == 0 {
+		return
+	}
+
+	stocks := make([]domain.Stock, 0, len(written))
+	for _, stock := range written {
+		stocks = append(stocks, stock)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := bp.scoreRecorder.RecordScores(ctx, stocks, time.Now().UTC()); err != nil {
+		log.Printf("Error recording score history: %v", err)
+	}
+}
+
+// saveStocksBatch flags the warnings of a batch of stocks and saves it to the repository
 func (bp *BatchProcessor) saveStocksBatch(ctx context.Context, batch []*domain.Stock) error {
+	for _, stock := range batch {
+		stock.Warnings = stock.CheckWarnings()
+	}
+
 	log.Printf("Saving batch of %d stocks", len(batch))
-	return bp.repo.SaveBatch(ctx, batch)
+	if err := bp.repo.SaveBatch(ctx, batch); err != nil {
+		return err
+	}
+
+	bp.shadowClassify(ctx, batch)
+	return nil
+}
+
+// shadowClassify runs the shadow classifier, if any, on copies of the saved stocks and
+// records the resulting labels.
+func (bp *BatchProcessor) shadowClassify(ctx context.Context, batch []*domain.Stock) {
+	if bp.shadowClassifier == nil || bp.shadowRepo == nil {
+		return
+	}
+
+	copies := make([]*domain.Stock, len(batch))
+	for i, stock := range batch {
+		stockCopy := *stock
+		copies[i] = &stockCopy
+	}
+	bp.shadowClassifier.ClassifyBatch(copies)
+
+	now := time.Now().UTC()
+	shadows := make([]*domain.ShadowClassification, len(copies))
+	for i, stock := range copies {
+		shadows[i] = &domain.ShadowClassification{
+			StockID:    stock.ID,
+			Ticker:     stock.Ticker,
+			Classifier: bp.shadowName,
+			Labels:     stock.Classifications,
+			CreatedAt:  now,
+		}
+	}
+
+	if err := bp.shadowRepo.SaveBatch(ctx, shadows); err != nil {
+		log.Printf("Error saving shadow classifications for %q: %v", bp.shadowName, err)
+	}
 }
@@ -1,7 +1,16 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -14,12 +23,42 @@ type StockHandler struct {
 	stockService           port.StockService
 	serviceBestInvestments port.BestInvestmentsService
 	workerPool             chan struct{}
+	snapshots              port.RecommendationSnapshotStore
+	weights                port.ScoringWeightsProvider
 }
 
 func NewStockHandler(service port.StockService, service_best_investments port.BestInvestmentsService, maxWorkers int) *StockHandler {
 	return &StockHandler{stockService: service, serviceBestInvestments: service_best_investments, workerPool: make(chan struct{}, maxWorkers)}
 }
 
+// WithScoringWeights picks the top classification of compact lists with the weights
+// supplied by provider instead of the built-in classification points.
+func (h *StockHandler) WithScoringWeights(provider port.ScoringWeightsProvider) *StockHandler {
+	h.weights = provider
+	return h
+}
+
+// classificationPoints returns the classification points in effect.
+func (h *StockHandler) classificationPoints() map[string]float64 {
+	if h.weights == nil {
+		return domain.ClassificationPoints
+	}
+	return h.weights.CurrentScoringWeights().Points
+}
+
+// WorkerPoolOccupancy returns the number of operations running in the worker pool and
+// its capacity.
+func (h *StockHandler) WorkerPoolOccupancy() (inUse, capacity int) {
+	return len(h.workerPool), cap(h.workerPool)
+}
+
+// WithRecommendationSnapshots keeps the latest recommendations in store, so they can be
+// served, flagged as stale, while the stocks cannot be read.
+func (h *StockHandler) WithRecommendationSnapshots(store port.RecommendationSnapshotStore) *StockHandler {
+	h.snapshots = store
+	return h
+}
+
 // FindStocks handles the HTTP request to retrieve a list of stocks.
 // It supports pagination, sorting, and filtering.
 //
@@ -31,11 +70,18 @@ func NewStockHandler(service port.StockService, service_best_investments port.Be
 // @Param page query int false "Page number for pagination"
 // @Param size query int false "Page size for pagination"
 // @Param sort query string false "Sorting criteria (e.g., 'name asc')"
-// @Param filters body domain.Filters false "Filters to apply to the stock search"
+// @Param estimate query bool false "Allow an estimated total for broad queries"
+// @Param compact query bool false "Return the abbreviated stock representation"
+// @Param countOnly query bool false "Only return the total, without fetching rows"
+// @Param asOf query string false "Read the stocks as they stood at this RFC 3339 time or date"
+// @Param filters body domain.FilterRequest false "Filters to apply to the stock search (POST)"
+// @Param filters query string false "JSON-encoded filters to apply to the stock search (GET)"
 // @Success 200 {object} []domain.Stock "List of stocks"
 // @Failure 400 {object} response.ErrorResponse "Invalid parameters"
 // @Failure 500 {object} response.ErrorResponse "Failed to retrieve stocks"
+// @Failure 504 {object} response.ErrorResponse "Request timed out"
 // @Router /stocks [get]
+// @Router /stocks [post]
 func (h *StockHandler) FindStocks(c *gin.Context) {
 	// Retrieves the pagination parameters from the query string
 	// and binds them to the PaginationParams struct.
@@ -47,73 +93,420 @@ func (h *StockHandler) FindStocks(c *gin.Context) {
 		return
 	}
 
-	// Retrieves the filters from the request body and binds them to the Filters struct.
-	// The filters are expected to be in JSON format.
-	// If no filters are provided, an empty Filters struct is created.
-	// The filters are used to filter the stocks based on specific criteria.
-
-	var requestBody domain.FilterRequest
-
-	// Bind the JSON from the request body
-	if err := c.ShouldBindJSON(&requestBody); err != nil {
+	// Retrieves the optional filters from the request body (POST) or the
+	// filters query parameter (GET). If no filters are provided, an empty
+	// Filters struct is used.
+	filters, err := bindFilters(c)
+	if err != nil {
 		response.BadRequest(c, "Invalid filters")
 		return
 	}
 
-	filters := requestBody.Filters
-	if filters == nil {
-		filters = make(domain.Filters) // Initialize if no filters are provided
+	// Optional switches such as ?estimate=true or ?compact=true
+	var opts domain.QueryOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+	if _, err := applyAsOf(c); err != nil {
+		response.BadRequest(c, err.Error())
+		return
 	}
 
 	// Calls the service to find stocks based on the pagination and filters.
-	stocks, total, err := AsyncManyOperation(c, h.workerPool, func() ([]domain.Stock, int, error) {
-		return h.stockService.Find(c.Request.Context(), pagination, filters)
+	page, err := AsyncOperation(c, h.workerPool, func() (domain.StockPage, error) {
+		return h.stockService.FindPage(c.Request.Context(), pagination, filters, opts)
 	})
 
 	if err != nil {
-		response.InternalServerError(c, "Failed to retrieve stocks")
+		respondAsyncError(c, err, "Failed to retrieve stocks")
+		return
+	}
+
+	h.respondStockPage(c, page, opts)
+}
+
+// CountStocks handles HEAD requests on the stock list. It runs only the count path and
+// returns the total in the X-Total-Count header, for UIs that just need badge numbers.
+//
+// Query Parameters:
+// - filters: (optional) JSON-encoded filters, as accepted by GET /stocks.
+// - estimate: (optional) Allow an estimated total for large tables.
+// - asOf: (optional) Count the stocks as they stood at that time, as in GET /stocks.
+//
+// Responses:
+// - 200: The total is in the X-Total-Count header.
+// - 400: The parameters or the filters are invalid.
+// - 500: The total could not be computed.
+// - 504: The total was not computed before the request deadline.
+func (h *StockHandler) CountStocks(c *gin.Context) {
+	var opts domain.QueryOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+	opts.CountOnly = true
+	if _, err := applyAsOf(c); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filters, err := bindFilters(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid filters")
+		return
+	}
+
+	page, err := AsyncOperation(c, h.workerPool, func() (domain.StockPage, error) {
+		return h.stockService.FindPage(c.Request.Context(), domain.PaginationParams{}, filters, opts)
+	})
+	if err != nil {
+		respondAsyncError(c, err, "Failed to count stocks")
 		return
 	}
 
-	resp := response.ToStockResponse(stocks, pagination.PageSize, total, pagination.SortField)
+	h.respondStockPage(c, page, opts)
+}
+
+// bindFilters reads the optional filters of a list request. They are taken from the
+// JSON body ({"filters": {...}}) when there is one, and otherwise from the filters query
+// parameter, which holds the JSON-encoded filters map. An empty body or a missing
+// parameter yields an empty filter set; malformed JSON is an error.
+func bindFilters(c *gin.Context) (domain.Filters, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	filters := make(domain.Filters)
+	if len(bytes.TrimSpace(body)) > 0 {
+		var requestBody domain.FilterRequest
+		if err := json.Unmarshal(body, &requestBody); err != nil {
+			return nil, err
+		}
+		if requestBody.Filters != nil {
+			filters = requestBody.Filters
+		}
+		return filters, nil
+	}
+
+	if raw := c.Query("filters"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filters); err != nil {
+			return nil, err
+		}
+		if filters == nil {
+			filters = make(domain.Filters)
+		}
+	}
+	return filters, nil
+}
+
+// respondStockPage writes a page of stocks in the full or compact representation,
+// together with the pagination applied by the service.
+func (h *StockHandler) respondStockPage(c *gin.Context, page domain.StockPage, opts domain.QueryOptions) {
+	if at, ok := domain.AsOfFrom(c.Request.Context()); ok {
+		warnRevisedSince(c, at, page.Stocks...)
+	}
+
+	// Tell clients when the total comes from a fallback path
+	if page.Approximate {
+		response.Warn(c, response.WarnMiscellaneous, "totalRecords is an estimate")
+	}
+	if page.Stale() {
+		response.MarkStale(c, "totalRecords was served from cache and may be delayed")
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if opts.CountOnly {
+		response.Success(c, 200, response.CountResponse{
+			TotalRecords: page.Total,
+			Approximate:  page.Approximate,
+			Stale:        page.Stale(),
+		})
+		return
+	}
+
+	applied := page.Pagination
+	if opts.Compact {
+		resp := response.ToCompactStockResponse(page.Stocks, h.classificationPoints(), applied.Page, page.Total, applied.SortField)
+		resp.PageSize = applied.PageSize
+		resp.Approximate = page.Approximate
+		resp.Stale = page.Stale()
+		response.Success(c, 200, resp)
+		return
+	}
+
+	resp := response.ToStockResponse(page.Stocks, applied.Page, page.Total, applied.SortField)
+	resp.PageSize = applied.PageSize
+	resp.Approximate = page.Approximate
+	resp.Stale = page.Stale()
 
 	// Returns the list of stocks in the response with a 200 status code.
 	response.Success(c, 200, resp)
 }
 
+// FindStocksByClassification handles the HTTP request to retrieve the stocks tagged
+// with a given classification. It supports pagination and sorting.
+//
+// @Summary Retrieve stocks by classification
+// @Description Retrieves one page of the stocks tagged with the given classification.
+// @Tags stocks
+// @Produce json
+// @Param classification path string true "Classification label (e.g., 'Bullish Signal')"
+// @Param page query int false "Page number for pagination (defaults to 1)"
+// @Param pageSize query int false "Page size for pagination (defaults to 20, capped at 500)"
+// @Param sortField query string false "Field to sort by (defaults to 'time')"
+// @Param sortOrder query int false "1 for ascending, -1 for descending (default)"
+// @Param compact query bool false "Return the abbreviated stock representation"
+// @Param asOf query string false "Read the stocks as they stood at this RFC 3339 time or date"
+// @Success 200 {object} response.StockResponse "Page of stocks"
+// @Failure 400 {object} response.JsonResponse "Invalid parameters"
+// @Failure 500 {object} response.JsonResponse "Failed to retrieve stocks"
+// @Failure 504 {object} response.JsonResponse "Request timed out"
+// @Router /stocks/classifications/{classification} [get]
+func (h *StockHandler) FindStocksByClassification(c *gin.Context) {
+	var pagination domain.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+
+	var opts domain.QueryOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+
+	if _, err := applyAsOf(c); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	classification := c.Param("classification")
+
+	page, err := AsyncOperation(c, h.workerPool, func() (domain.StockPage, error) {
+		return h.stockService.FindByClassification(c.Request.Context(), classification, pagination)
+	})
+
+	if err != nil {
+		respondAsyncError(c, err, "Failed to retrieve stocks")
+		return
+	}
+
+	h.respondStockPage(c, page, opts)
+}
+
 // GetStockRecommendations handles the HTTP request to retrieve stock recommendations.
 // It uses a default limit of 5 recommendations unless specified in the query parameters.
 //
 // Query Parameters:
 // - limit: (optional) The maximum number of recommendations to return.
+// - asOf: (optional) An RFC 3339 time or a date. The recommendations are generated as
+// they would have been then, from the stocks stored by then and with the scoring weights
+// in effect then, for backtesting. Past recommendations are not snapshotted.
 //
 // Responses:
-// - 200: Returns a JSON response with the list of stock recommendations.
+// - 200: Returns a JSON response with the list of stock recommendations. When the stocks
+// cannot be read and a snapshot is configured, the last known list is returned with the
+// X-Stale and Warning headers.
+// - 400: asOf is invalid or cannot be served.
 // - 500: Returns an internal server error if there is an issue retrieving the stocks.
 func (h *StockHandler) GetStockRecommendations(c *gin.Context) {
 	limit := 5
 	if c.Query("limit") != "" {
 		limit, _ = strconv.Atoi(c.Query("limit"))
 	}
+	at, err := applyAsOf(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	pagination := domain.PaginationParams{
-		Page:     1,
-		PageSize: 5000,
+	// Score the most recent events. This internal read is not subject to the page size
+	// cap applied to client pagination.
+	stocks, err := AsyncOperation(c, h.workerPool, func() ([]domain.Stock, error) {
+		return h.stockService.FindAllStocks(c.Request.Context(), "time DESC", 1, domain.RecommendationPoolSize)
+	})
+
+	if errors.Is(err, domain.ErrAsOfUnsupported) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		if at.IsZero() && h.serveRecommendationSnapshot(c, limit) {
+			return
+		}
+		response.InternalServerError(c, "Failed to retrieve stocks")
+		return
 	}
 
-	filters := make(domain.Filters)
+	if !at.IsZero() {
+		h.respondRecommendationsAsOf(c, stocks, limit, at)
+		return
+	}
 
-	// Calls the service to find stocks based on the pagination and filters.
-	stocks, _, err := AsyncManyOperation(c, h.workerPool, func() ([]domain.Stock, int, error) {
-		return h.stockService.Find(c.Request.Context(), pagination, filters)
+	// Snapshots keep the same top recommendations whatever the limit requested
+	ranked := limit
+	if h.snapshots != nil {
+		ranked = max(limit, domain.RecommendationSnapshotSize)
+	}
+
+	var (
+		recommendations []domain.Recommendation
+		weightsVersion  int
+	)
+	if versioned, ok := h.serviceBestInvestments.(port.VersionedRecommender); ok {
+		recommendations, weightsVersion = versioned.GetVersionedRecommendations(stocks, ranked)
+	} else {
+		recommendations = h.serviceBestInvestments.GetStockRecommendations(stocks, ranked)
+	}
+
+	if h.snapshots != nil {
+		snapshot := domain.RecommendationSnapshot{
+			GeneratedAt:     time.Now().UTC(),
+			WeightsVersion:  weightsVersion,
+			Recommendations: recommendations[:min(len(recommendations), domain.RecommendationSnapshotSize)],
+		}
+		if err := h.snapshots.Save(c.Request.Context(), snapshot); err != nil {
+			log.Printf("Error saving recommendation snapshot: %v", err)
+		}
+	}
+
+	response.Success(c, 200, limitRecommendations(recommendations, limit))
+}
+
+// limitRecommendations returns the first limit recommendations. A negative limit keeps
+// them all.
+func limitRecommendations(recommendations []domain.Recommendation, limit int) []domain.Recommendation {
+	if limit >= 0 && limit < len(recommendations) {
+		return recommendations[:limit]
+	}
+	return recommendations
+}
+
+// respondRecommendationsAsOf answers with the recommendations generated from stocks as
+// they would have been at at.
+func (h *StockHandler) respondRecommendationsAsOf(c *gin.Context, stocks []domain.Stock, limit int, at time.Time) {
+	scorer, ok := h.serviceBestInvestments.(port.PointInTimeScorer)
+	if !ok {
+		response.BadRequest(c, domain.ErrAsOfUnsupported.Error())
+		return
+	}
+	recommendations, _, err := scorer.GetRecommendationsAsOf(c.Request.Context(), stocks, limit, at)
+	if err != nil {
+		response.InternalServerError(c, "Failed to score stocks")
+		return
+	}
+
+	warnRevisedSince(c, at, stocks...)
+	response.Success(c, http.StatusOK, recommendations)
+}
+
+// serveRecommendationSnapshot answers with the last known recommendations, flagged as
+// stale. It reports false if there is no snapshot to serve.
+func (h *StockHandler) serveRecommendationSnapshot(c *gin.Context, limit int) bool {
+	if h.snapshots == nil {
+		return false
+	}
+	snapshot, ok, err := h.snapshots.Load(c.Request.Context())
+	if err != nil {
+		log.Printf("Error loading recommendation snapshot: %v", err)
+	}
+	if !ok {
+		return false
+	}
+
+	response.MarkStale(c, "Serving the recommendations computed at "+snapshot.GeneratedAt.Format(time.RFC3339))
+	response.Success(c, http.StatusOK, limitRecommendations(snapshot.Recommendations, limit))
+	return true
+}
+
+// GetTickerScore handles the HTTP request to retrieve the score breakdown of a single ticker,
+// based on its most recent analyst event.
+//
+// Path Parameters:
+// - ticker: The stock ticker (e.g., "AAPL").
+//
+// Query Parameters:
+// - asOf: (optional) An RFC 3339 time or a date. The score is computed as it would have
+// been then, from the most recent event stored by then, with the scoring weights in
+// effect then and the freshness measured then.
+//
+// Responses:
+// - 200: Returns the composite score, risk score, freshness, and classification contributions.
+// - 400: asOf is invalid or cannot be served.
+// - 404: The ticker has no events.
+// - 500: Returns an internal server error if there is an issue retrieving the stock.
+func (h *StockHandler) GetTickerScore(c *gin.Context) {
+	ticker := strings.ToUpper(c.Param("ticker"))
+	at, err := applyAsOf(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	stock, err := AsyncOperation(c, h.workerPool, func() (*domain.Stock, error) {
+		return h.stockService.FindLatestStockByTicker(c.Request.Context(), ticker)
 	})
 
+	if errors.Is(err, domain.ErrStockNotFound) {
+		response.NotFound(c, "Stock not found")
+		return
+	}
+	if errors.Is(err, domain.ErrAsOfUnsupported) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve stock")
+		return
+	}
+
+	if at.IsZero() {
+		response.Success(c, http.StatusOK, h.serviceBestInvestments.GetScoreBreakdown(*stock))
+		return
+	}
+
+	scorer, ok := h.serviceBestInvestments.(port.PointInTimeScorer)
+	if !ok {
+		response.BadRequest(c, domain.ErrAsOfUnsupported.Error())
+		return
+	}
+	breakdown, err := scorer.GetScoreBreakdownAsOf(c.Request.Context(), *stock, at)
 	if err != nil {
-		response.InternalServerError(c, "Failed to retrieve stocks")
+		response.InternalServerError(c, "Failed to score stock")
 		return
 	}
+	warnRevisedSince(c, at, *stock)
+	response.Success(c, http.StatusOK, breakdown)
+}
 
-	recommendations := h.serviceBestInvestments.GetStockRecommendations(stocks, limit)
+// applyAsOf reads the optional asOf query parameter (see domain.ParseAsOf). When it is
+// given, the request goes on reading the data as it stood at that time, which is returned
+// and echoed by the response (see response.MarkAsOf). Otherwise the zero time is
+// returned. Errors wrap domain.ErrInvalidAsOf.
+func applyAsOf(c *gin.Context) (time.Time, error) {
+	raw := c.Query("asOf")
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	at, err := domain.ParseAsOf(raw, time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.Request = c.Request.WithContext(domain.WithAsOf(c.Request.Context(), at))
+	response.MarkAsOf(c, at)
+	return at, nil
+}
 
-	response.Success(c, 200, recommendations)
+// warnRevisedSince warns that the stocks updated after at before revisions were kept are
+// shown with their current values rather than those they had then.
+func warnRevisedSince(c *gin.Context, at time.Time, stocks ...domain.Stock) {
+	if revised := domain.RevisedSince(at, stocks...); revised > 0 {
+		response.Warn(c, response.WarnMiscellaneous, fmt.Sprintf("%d stocks were updated after asOf without a revision and are shown with their current values", revised))
+	}
 }
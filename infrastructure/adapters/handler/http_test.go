@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// fakeStockService records the filters FindPage receives, and the time it reads the data
+// at. Methods not overridden panic.
+type fakeStockService struct {
+	port.StockService
+	filters domain.Filters
+	opts    domain.QueryOptions
+	asOf    time.Time
+	stocks  []domain.Stock
+	err     error
+}
+
+func (f *fakeStockService) FindPage(
+	ctx context.Context,
+	pagination domain.PaginationParams,
+	filters domain.Filters,
+	opts domain.QueryOptions,
+) (domain.StockPage, error) {
+	f.filters, f.opts = filters, opts
+	f.asOf, _ = domain.AsOfFrom(ctx)
+	if f.err != nil {
+		return domain.StockPage{}, f.err
+	}
+	stocks := f.stocks
+	if stocks == nil {
+		stocks = []domain.Stock{{Ticker: "AAPL"}}
+	}
+	return domain.StockPage{Stocks: stocks, Total: len(stocks), Pagination: pagination}, nil
+}
+
+// fakeSnapshotStore serves a fixed recommendation snapshot.
+type fakeSnapshotStore struct {
+	snapshot *domain.RecommendationSnapshot
+}
+
+func (f *fakeSnapshotStore) Save(_ context.Context, snapshot domain.RecommendationSnapshot) error {
+	f.snapshot = &snapshot
+	return nil
+}
+
+func (f *fakeSnapshotStore) Load(_ context.Context) (domain.RecommendationSnapshot, bool, error) {
+	if f.snapshot == nil {
+		return domain.RecommendationSnapshot{}, false, nil
+	}
+	return *f.snapshot, true, nil
+}
+
+// failingStockService fails every read, as when the database is unavailable.
+type failingStockService struct {
+	port.StockService
+}
+
+func (failingStockService) FindAllStocks(_ context.Context, _ string, _ int, _ int) ([]domain.Stock, error) {
+	return nil, errors.New("database unavailable")
+}
+
+// stockListService serves the same stocks to every recommendation read.
+type stockListService struct {
+	port.StockService
+	stocks []domain.Stock
+}
+
+func (s stockListService) FindAllStocks(_ context.Context, _ string, _ int, _ int) ([]domain.Stock, error) {
+	return s.stocks, nil
+}
+
+// rankingRecommender recommends the stocks in the order given.
+type rankingRecommender struct {
+	port.BestInvestmentsService
+}
+
+func (rankingRecommender) GetStockRecommendations(stocks []domain.Stock, limit int) []domain.Recommendation {
+	recommendations := []domain.Recommendation{}
+	for i, stock := range stocks[:min(limit, len(stocks))] {
+		recommendations = append(recommendations, domain.Recommendation{Position: i + 1, Ticker: stock.Ticker})
+	}
+	return recommendations
+}
+
+func TestGetStockRecommendations_Snapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(h *StockHandler) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/recommendations", h.GetStockRecommendations)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recommendations?limit=1", nil))
+		return w
+	}
+
+	t.Run("should serve the last snapshot flagged as stale", func(t *testing.T) {
+		store := &fakeSnapshotStore{snapshot: &domain.RecommendationSnapshot{
+			GeneratedAt:     time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+			Recommendations: []domain.Recommendation{{Position: 1, Ticker: "AAPL"}, {Position: 2, Ticker: "MSFT"}},
+		}}
+		h := NewStockHandler(failingStockService{}, nil, 1).WithRecommendationSnapshots(store)
+
+		w := serve(h)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get("X-Stale"))
+		assert.Contains(t, w.Header().Get("Warning"), "2025-03-01T12:00:00Z")
+		assert.Contains(t, w.Body.String(), "AAPL")
+		assert.NotContains(t, w.Body.String(), "MSFT")
+	})
+
+	t.Run("should snapshot the top recommendations whatever the limit", func(t *testing.T) {
+		stocks := make([]domain.Stock, domain.RecommendationSnapshotSize+10)
+		for i := range stocks {
+			stocks[i].Ticker = fmt.Sprintf("T%d", i)
+		}
+		store := &fakeSnapshotStore{}
+		h := NewStockHandler(stockListService{stocks: stocks}, rankingRecommender{}, 1).WithRecommendationSnapshots(store)
+
+		w := serve(h)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"T0"`)
+		assert.NotContains(t, w.Body.String(), `"T1"`)
+		require.NotNil(t, store.snapshot)
+		assert.Len(t, store.snapshot.Recommendations, domain.RecommendationSnapshotSize)
+	})
+
+	t.Run("should fail without a snapshot", func(t *testing.T) {
+		h := NewStockHandler(failingStockService{}, nil, 1).WithRecommendationSnapshots(&fakeSnapshotStore{})
+		assert.Equal(t, http.StatusInternalServerError, serve(h).Code)
+	})
+}
+
+func TestFindStocks_RequestForms(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tickerFilter := domain.Filters{"ticker": {Value: "AAPL", MatchMode: "equals"}}
+
+	tests := []struct {
+		name            string
+		method          string
+		query           string
+		body            string
+		expectedStatus  int
+		expectedFilters domain.Filters
+	}{
+		{
+			name:            "should treat a missing body as no filters",
+			method:          http.MethodPost,
+			expectedStatus:  http.StatusOK,
+			expectedFilters: domain.Filters{},
+		},
+		{
+			name:            "should treat a blank body as no filters",
+			method:          http.MethodPost,
+			body:            "  \n",
+			expectedStatus:  http.StatusOK,
+			expectedFilters: domain.Filters{},
+		},
+		{
+			name:            "should treat a body without filters as no filters",
+			method:          http.MethodPost,
+			body:            `{}`,
+			expectedStatus:  http.StatusOK,
+			expectedFilters: domain.Filters{},
+		},
+		{
+			name:            "should bind filters from the body",
+			method:          http.MethodPost,
+			body:            `{"filters": {"ticker": {"value": "AAPL", "matchMode": "equals"}}}`,
+			expectedStatus:  http.StatusOK,
+			expectedFilters: tickerFilter,
+		},
+		{
+			name:           "should reject a malformed body",
+			method:         http.MethodPost,
+			body:           `{"filters": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "should accept GET without filters",
+			method:          http.MethodGet,
+			expectedStatus:  http.StatusOK,
+			expectedFilters: domain.Filters{},
+		},
+		{
+			name:            "should bind filters from the query string on GET",
+			method:          http.MethodGet,
+			query:           "filters=" + url.QueryEscape(`{"ticker": {"value": "AAPL", "matchMode": "equals"}}`),
+			expectedStatus:  http.StatusOK,
+			expectedFilters: tickerFilter,
+		},
+		{
+			name:           "should reject malformed filters in the query string",
+			method:         http.MethodGet,
+			query:          "filters=" + url.QueryEscape(`{"ticker"`),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeStockService{}
+			h := NewStockHandler(service, nil, 1)
+			router := gin.New()
+			router.Handle(tt.method, "/stocks", h.FindStocks)
+
+			req := httptest.NewRequest(tt.method, "/stocks?"+tt.query, strings.NewReader(tt.body))
+			if tt.body == "" {
+				req = httptest.NewRequest(tt.method, "/stocks?"+tt.query, http.NoBody)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedFilters, service.filters)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+			}
+		})
+	}
+}
+
+func TestFindStocks_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakeStockService{err: fmt.Errorf("%w: unsupported match mode: \"regex\" on ticker", domain.ErrInvalidFilter)}
+	h := NewStockHandler(service, nil, 1)
+	router := gin.New()
+	router.GET("/stocks", h.FindStocks)
+
+	req := httptest.NewRequest(http.MethodGet, "/stocks?filters="+url.QueryEscape(`{"ticker": {"value": "AAPL", "matchMode": "regex"}}`), http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unsupported match mode")
+}
+
+func TestFindStocks_AsOf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(service *fakeStockService, query string) *httptest.ResponseRecorder {
+		h := NewStockHandler(service, nil, 1)
+		router := gin.New()
+		router.GET("/stocks", h.FindStocks)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stocks?"+query, http.NoBody))
+		return w
+	}
+
+	t.Run("should read the data at the end of the given day", func(t *testing.T) {
+		service := &fakeStockService{}
+		w := serve(service, "asOf=2025-03-01")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		expected := time.Date(2025, 3, 1, 23, 59, 59, 999999999, time.UTC)
+		assert.Equal(t, expected, service.asOf)
+		assert.Equal(t, "2025-03-01T23:59:59.999999999Z", w.Header().Get("X-As-Of"))
+		assert.Contains(t, w.Body.String(), `"asOf": "2025-03-01T23:59:59.999999999Z"`)
+		assert.Empty(t, w.Header().Get("Warning"))
+	})
+
+	t.Run("should warn about stocks updated since", func(t *testing.T) {
+		revised := domain.Stock{Ticker: "AAPL"}
+		revised.UpdatedAt = time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+		service := &fakeStockService{stocks: []domain.Stock{revised, {Ticker: "MSFT"}}}
+		w := serve(service, "asOf="+url.QueryEscape("2025-03-01T12:00:00+01:00"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC), service.asOf)
+		assert.Contains(t, w.Header().Get("Warning"), "1 stocks were updated after asOf")
+	})
+
+	t.Run("should read the current data without asOf", func(t *testing.T) {
+		service := &fakeStockService{}
+		w := serve(service, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, service.asOf.IsZero())
+		assert.Empty(t, w.Header().Get("X-As-Of"))
+	})
+
+	t.Run("should reject invalid and future times", func(t *testing.T) {
+		for _, asOf := range []string{"yesterday", time.Now().Add(time.Hour).Format(time.RFC3339)} {
+			service := &fakeStockService{}
+			w := serve(service, "asOf="+url.QueryEscape(asOf))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, asOf)
+			assert.Nil(t, service.filters, asOf)
+		}
+	})
+}
+
+// slowStockService blocks every page read until its context is done. Reads run on
+// the worker goroutines, so whether they had a deadline is sent on deadlines.
+type slowStockService struct {
+	port.StockService
+	deadlines chan bool
+}
+
+func (s *slowStockService) FindPage(ctx context.Context, _ domain.PaginationParams, _ domain.Filters, _ domain.QueryOptions) (domain.StockPage, error) {
+	_, ok := ctx.Deadline()
+	s.deadlines <- ok
+	<-ctx.Done()
+	return domain.StockPage{}, ctx.Err()
+}
+
+func TestFindStocks_RequestDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &slowStockService{deadlines: make(chan bool, 1)}
+	router := gin.New()
+	router.GET("/stocks", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Millisecond)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}, NewStockHandler(service, nil, 1).FindStocks)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stocks", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.True(t, <-service.deadlines, "the request deadline should reach the service")
+	assert.Less(t, time.Since(start), defaultOperationTimeout)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// readinessTimeout bounds the database checks of the readiness probe.
+const readinessTimeout = 2 * time.Second
+
+type ReadinessHandler struct {
+	schema port.SchemaInspector
+}
+
+// NewReadinessHandler creates a new instance of ReadinessHandler.
+func NewReadinessHandler(schema port.SchemaInspector) *ReadinessHandler {
+	return &ReadinessHandler{schema: schema}
+}
+
+// Readyz handles the readiness probe. Unlike /health, it checks the database and reports
+// the schema version, so deployments can verify the migrations they expect are applied.
+//
+// Responses:
+// - 200: The database is reachable and the schema is clean.
+// - 503: The database is unreachable, or the last migration left the schema dirty.
+func (h *ReadinessHandler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	readiness := domain.Readiness{Status: "not ready"}
+	if err := h.schema.Ping(ctx); err != nil {
+		readiness.Error = "database unreachable"
+		c.IndentedJSON(http.StatusServiceUnavailable, response.JsonResponse{Success: false, Data: readiness, Error: readiness.Error})
+		return
+	}
+
+	version, err := h.schema.SchemaVersion(ctx)
+	readiness.SchemaVersion = version
+	switch {
+	case err != nil:
+		readiness.Error = "schema version unavailable"
+	case version.Dirty:
+		readiness.Error = "schema is dirty"
+	}
+	if readiness.Error != "" {
+		c.IndentedJSON(http.StatusServiceUnavailable, response.JsonResponse{Success: false, Data: readiness, Error: readiness.Error})
+		return
+	}
+
+	readiness.Status = "ready"
+	response.Success(c, http.StatusOK, readiness)
+}
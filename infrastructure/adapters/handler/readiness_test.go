@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// fakeSchemaInspector returns fixed probe results.
+type fakeSchemaInspector struct {
+	pingErr error
+	version domain.SchemaVersion
+}
+
+func (f *fakeSchemaInspector) Ping(_ context.Context) error { return f.pingErr }
+
+func (f *fakeSchemaInspector) SchemaVersion(_ context.Context) (domain.SchemaVersion, error) {
+	return f.version, nil
+}
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	version := uint(6)
+
+	tests := []struct {
+		name     string
+		schema   *fakeSchemaInspector
+		status   int
+		contains string
+	}{
+		{"ready", &fakeSchemaInspector{version: domain.SchemaVersion{Version: &version}}, http.StatusOK, `"schemaVersion": 6`},
+		{"dirty schema", &fakeSchemaInspector{version: domain.SchemaVersion{Version: &version, Dirty: true}}, http.StatusServiceUnavailable, `"error": "schema is dirty"`},
+		{"database down", &fakeSchemaInspector{pingErr: errors.New("connection refused")}, http.StatusServiceUnavailable, `"error": "database unreachable"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/readyz", NewReadinessHandler(tt.schema).Readyz)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// ReclassifyHandler serves the endpoints running the classifier again over stored stocks,
+// as background jobs polled by ID.
+type ReclassifyHandler struct {
+	jobs port.ReclassifyJobManager
+}
+
+// NewReclassifyHandler creates a new instance of ReclassifyHandler.
+func NewReclassifyHandler(jobs port.ReclassifyJobManager) *ReclassifyHandler {
+	return &ReclassifyHandler{jobs: jobs}
+}
+
+// StartJob handles the HTTP request to reclassify the stocks matching a set of filters.
+// The body is a FilterRequest, as accepted by POST /stocks (e.g., only the Energy sector,
+// or only the rows created during an ingestion run); without filters, every stock is
+// reclassified. Custom labels set by admins are kept. Only one job runs at a time.
+//
+// Responses:
+// - 202: The job has started. Returns the job, whose ID is polled for progress.
+// - 400: The filters are malformed or rejected.
+// - 409: A reclassification job is already running.
+// - 500: The matching stocks could not be counted.
+func (h *ReclassifyHandler) StartJob(c *gin.Context) {
+	filters, err := bindFilters(c)
+	if err != nil {
+		response.BadRequest(c, "Invalid filters")
+		return
+	}
+	middleware.SetAuditAction(c, "stocks.reclassify", gin.H{"filters": filters})
+
+	job, err := h.jobs.Start(c.Request.Context(), filters, c.GetString(middleware.ActorKey))
+	switch {
+	case errors.Is(err, domain.ErrInvalidFilter):
+		response.BadRequest(c, err.Error())
+		return
+	case errors.Is(err, domain.ErrJobRunning):
+		response.Error(c, http.StatusConflict, "Reclassification already running")
+		return
+	case err != nil:
+		response.InternalServerError(c, "Failed to start reclassification")
+		return
+	}
+
+	middleware.SetAuditAction(c, "stocks.reclassify", gin.H{"job": job.ID, "filters": filters, "total": job.Total})
+	response.Success(c, http.StatusAccepted, job)
+}
+
+// GetJob handles the HTTP request to read the progress of a reclassification job.
+//
+// Path Parameters:
+// - id: The job ID returned when the job started.
+//
+// Responses:
+// - 200: Returns the job.
+// - 404: The job is unknown to this instance.
+func (h *ReclassifyHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Job(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, "Reclassification job not found")
+		return
+	}
+
+	response.Success(c, http.StatusOK, job)
+}
+
+// ListJobs handles the HTTP request to list the running and recent reclassification
+// jobs, newest first.
+//
+// Responses:
+// - 200: Returns the jobs.
+func (h *ReclassifyHandler) ListJobs(c *gin.Context) {
+	response.Success(c, http.StatusOK, h.jobs.Jobs())
+}
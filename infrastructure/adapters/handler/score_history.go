@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// ScoreHistoryHandler serves the scores recorded for each ticker after ingestion runs.
+type ScoreHistoryHandler struct {
+	repo port.ScoreHistoryRepository
+}
+
+// NewScoreHistoryHandler creates a new instance of ScoreHistoryHandler.
+func NewScoreHistoryHandler(repo port.ScoreHistoryRepository) *ScoreHistoryHandler {
+	return &ScoreHistoryHandler{repo: repo}
+}
+
+// GetScoreHistory handles the HTTP request to chart how a ticker's score evolved.
+//
+// Path Parameters:
+// - ticker: The stock ticker (e.g., "AAPL").
+//
+// Query Parameters:
+// - from: (optional) RFC 3339 time of the earliest entry.
+// - to: (optional) RFC 3339 time of the latest entry.
+// - limit: (optional) The maximum number of entries, the latest ones, 500 by default and at most 5000.
+//
+// Responses:
+// - 200: Returns the entries, oldest first. The list is empty for unknown tickers.
+// - 400: A query parameter is invalid.
+// - 500: The history could not be retrieved.
+func (h *ScoreHistoryHandler) GetScoreHistory(c *gin.Context) {
+	ticker := strings.ToUpper(c.Param("ticker"))
+
+	var from, to time.Time
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			response.BadRequest(c, "Invalid from, expected an RFC 3339 time")
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			response.BadRequest(c, "Invalid to, expected an RFC 3339 time")
+			return
+		}
+	}
+
+	limit := domain.DefaultScoreHistoryLimit
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		limit = min(limit, domain.MaxScoreHistoryLimit)
+	}
+
+	entries, err := h.repo.ListByTicker(c.Request.Context(), ticker, from, to, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve score history")
+		return
+	}
+
+	response.Success(c, http.StatusOK, entries)
+}
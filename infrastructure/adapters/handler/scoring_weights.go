@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// ScoringWeightsHandler serves the endpoints reading and tuning the points each
+// classification adds to a stock's score. Every change creates a new version of the
+// weights and is recorded in the audit log.
+type ScoringWeightsHandler struct {
+	weights port.ScoringWeightsManager
+}
+
+// NewScoringWeightsHandler creates a new instance of ScoringWeightsHandler.
+func NewScoringWeightsHandler(weights port.ScoringWeightsManager) *ScoringWeightsHandler {
+	return &ScoringWeightsHandler{weights: weights}
+}
+
+// scoringWeightInput is the payload accepted when setting the weight of a classification.
+type scoringWeightInput struct {
+	Points *float64 `json:"points" binding:"required"`
+}
+
+// GetWeights handles the HTTP request to read the scoring weights in effect, or those of
+// a past version.
+//
+// Query Parameters:
+// - version: (optional) The version to read, the one in effect by default.
+//
+// Responses:
+// - 200: Returns the version and the points of each classification.
+// - 400: The version is invalid.
+// - 404: No weights were stored with the version.
+// - 500: The weights could not be read.
+func (h *ScoringWeightsHandler) GetWeights(c *gin.Context) {
+	version, err := strconv.Atoi(c.DefaultQuery("version", "0"))
+	if err != nil || version < 0 {
+		response.BadRequest(c, "Invalid scoring weights version")
+		return
+	}
+
+	weights, err := h.weights.GetWeights(c.Request.Context(), version)
+	if err != nil {
+		respondScoringWeightsError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, weights)
+}
+
+// ListChanges handles the HTTP request to read every change made to the scoring weights,
+// oldest first.
+//
+// Responses:
+// - 200: Returns the changes.
+// - 500: The changes could not be read.
+func (h *ScoringWeightsHandler) ListChanges(c *gin.Context) {
+	changes, err := h.weights.ListChanges(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve scoring weight changes")
+		return
+	}
+
+	response.Success(c, http.StatusOK, changes)
+}
+
+// SetWeight handles the HTTP request to create or change the weight of a classification.
+//
+// Path Parameters:
+// - label: The classification (e.g., "Potential Growth").
+//
+// Responses:
+// - 200: Returns the new version of the weights.
+// - 400: The classification is unknown or the points are invalid.
+// - 500: The change could not be stored.
+func (h *ScoringWeightsHandler) SetWeight(c *gin.Context) {
+	var in scoringWeightInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, "Invalid scoring weight")
+		return
+	}
+	label := c.Param("label")
+	middleware.SetAuditAction(c, "scoring_weights.set", gin.H{"label": label, "points": *in.Points})
+
+	weights, err := h.weights.Apply(c.Request.Context(), c.GetString(middleware.ActorKey), &domain.ScoringWeight{Label: label, Points: *in.Points})
+	if err != nil {
+		respondScoringWeightsError(c, err)
+		return
+	}
+
+	middleware.SetAuditAction(c, "scoring_weights.set", gin.H{"label": label, "points": *in.Points, "version": weights.Version})
+	response.Success(c, http.StatusOK, weights)
+}
+
+// DeleteWeight handles the HTTP request to remove the weight of a classification, so it
+// no longer contributes to scores.
+//
+// Path Parameters:
+// - label: The classification (e.g., "Potential Growth").
+//
+// Responses:
+// - 200: Returns the new version of the weights.
+// - 400: The classification is unknown.
+// - 404: The classification has no weight.
+// - 500: The change could not be stored.
+func (h *ScoringWeightsHandler) DeleteWeight(c *gin.Context) {
+	label := c.Param("label")
+	middleware.SetAuditAction(c, "scoring_weights.delete", gin.H{"label": label})
+
+	weights, err := h.weights.Apply(c.Request.Context(), c.GetString(middleware.ActorKey), &domain.ScoringWeight{Label: label, Removed: true})
+	if err != nil {
+		respondScoringWeightsError(c, err)
+		return
+	}
+
+	middleware.SetAuditAction(c, "scoring_weights.delete", gin.H{"label": label, "version": weights.Version})
+	response.Success(c, http.StatusOK, weights)
+}
+
+// respondScoringWeightsError maps validation errors to 400, missing weights or versions
+// to 404, and anything else to 500.
+func respondScoringWeightsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidScoringWeight):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, domain.ErrScoringWeightNotFound), errors.Is(err, domain.ErrScoringWeightsVersionNotFound):
+		response.NotFound(c, err.Error())
+	default:
+		response.InternalServerError(c, "Failed to process scoring weights")
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/response"
+)
+
+// ShadowHandler serves the reports comparing shadow classifiers with the active one.
+type ShadowHandler struct {
+	repo          port.ShadowClassificationRepository
+	defaultShadow string
+}
+
+// NewShadowHandler creates a new instance of ShadowHandler.
+// defaultShadow is the classifier reported when the request does not name one.
+func NewShadowHandler(repo port.ShadowClassificationRepository, defaultShadow string) *ShadowHandler {
+	return &ShadowHandler{repo: repo, defaultShadow: defaultShadow}
+}
+
+// GetReport handles the HTTP request to compare a shadow classifier's labels with the
+// active labels of the same stocks.
+//
+// Query Parameters:
+// - classifier: (optional) The shadow classifier to report on. Defaults to the configured one.
+//
+// Responses:
+// - 200: Returns the comparison report.
+// - 400: No classifier was requested and none is configured.
+// - 500: The report could not be computed.
+func (h *ShadowHandler) GetReport(c *gin.Context) {
+	classifier := c.DefaultQuery("classifier", h.defaultShadow)
+	if classifier == "" {
+		response.BadRequest(c, "No shadow classifier configured or requested")
+		return
+	}
+
+	report, err := h.repo.Report(c.Request.Context(), classifier)
+	if err != nil {
+		response.InternalServerError(c, "Failed to compute shadow report")
+		return
+	}
+
+	response.Success(c, http.StatusOK, report)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/dto"
+	"stock-api/infrastructure/response"
+)
+
+// CreateStock handles the HTTP request to create a single stock event.
+// Supplied classifications are validated against the label registry.
+//
+// Responses:
+// - 201: Returns the created stock.
+// - 400: The payload is malformed or fails validation.
+// - 500: The stock could not be stored.
+func (h *StockHandler) CreateStock(c *gin.Context) {
+	var in dto.StockInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, "Invalid stock")
+		return
+	}
+
+	stock := in.ToDomain()
+	middleware.SetAuditAction(c, "stock.create", gin.H{"ticker": stock.Ticker})
+
+	if err := h.stockService.RegisterStock(c.Request.Context(), stock); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	response.Created(c, response.ToStockItem(stock))
+}
+
+// CreateStocks handles the HTTP request to create several stock events in one batch.
+// Nothing is stored if any item is invalid.
+//
+// Responses:
+// - 201: Returns the number of stocks created.
+// - 400: The payload is malformed or an item fails validation.
+// - 500: The stocks could not be stored.
+func (h *StockHandler) CreateStocks(c *gin.Context) {
+	var in []dto.StockInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, "Invalid stocks")
+		return
+	}
+
+	stocks := make([]*domain.Stock, len(in))
+	for i := range in {
+		stocks[i] = in[i].ToDomain()
+	}
+	middleware.SetAuditAction(c, "stock.batch_create", gin.H{"count": len(stocks)})
+
+	if err := h.stockService.RegisterStocks(c.Request.Context(), stocks); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	response.Created(c, gin.H{"created": len(stocks)})
+}
+
+// UpdateStock handles the HTTP request to partially update a stock event with a JSON
+// Merge Patch (RFC 7386). Omitted fields are left untouched and explicit nulls clear
+// optional fields. The changed fields are recorded in the audit log.
+//
+// Responses:
+// - 200: Returns the updated stock.
+// - 400: The ID, the patch, or the patched stock is invalid.
+// - 404: No stock exists with the given ID.
+// - 415: The request is not sent as application/merge-patch+json.
+// - 500: The stock could not be stored.
+func (h *StockHandler) UpdateStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.BadRequest(c, "Invalid stock ID")
+		return
+	}
+
+	if c.ContentType() != domain.MergePatchContentType {
+		response.Error(c, http.StatusUnsupportedMediaType, "Content-Type must be "+domain.MergePatchContentType)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.BadRequest(c, "Invalid patch")
+		return
+	}
+	patch, err := domain.ParseMergePatch(body)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	middleware.SetAuditAction(c, "stock.update", gin.H{"id": id})
+
+	stock, changes, err := h.stockService.UpdateStock(c.Request.Context(), uint(id), patch)
+	if errors.Is(err, domain.ErrStockNotFound) {
+		response.NotFound(c, "Stock not found")
+		return
+	}
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	middleware.SetAuditAction(c, "stock.update", gin.H{"id": id, "changes": changes})
+	response.Success(c, http.StatusOK, response.ToStockItem(stock))
+}
+
+// respondWriteError maps validation errors to 400 and anything else to 500.
+func respondWriteError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidStock) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, "Failed to save stocks")
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/adapters/middleware"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/core/service"
+	"stock-api/infrastructure/response"
+)
+
+// WebhookHandler serves the CRUD endpoints of webhook subscriptions and their delivery logs.
+type WebhookHandler struct {
+	repo port.WebhookRepository
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler.
+func NewWebhookHandler(repo port.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{repo: repo}
+}
+
+// webhookInput is the payload accepted when creating or replacing a subscription.
+type webhookInput struct {
+	URL             string   `json:"url" binding:"required"`
+	Tickers         []string `json:"tickers"`
+	Classifications []string `json:"classifications"`
+	Active          *bool    `json:"active"` // Defaults to true
+}
+
+// apply copies the payload onto subscription.
+func (in *webhookInput) apply(subscription *domain.WebhookSubscription) {
+	subscription.URL = in.URL
+	subscription.Tickers = in.Tickers
+	subscription.Classifications = in.Classifications
+	subscription.Active = in.Active == nil || *in.Active
+}
+
+// createdWebhook is the response to a subscription creation, the only one disclosing
+// the signing secret.
+type createdWebhook struct {
+	domain.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// CreateSubscription handles the HTTP request to register a webhook subscription.
+// The response contains the secret used to sign deliveries; it is not shown again.
+//
+// Responses:
+// - 201: Returns the subscription and its secret.
+// - 400: The payload is malformed or fails validation.
+// - 500: The subscription could not be stored.
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var in webhookInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, "Invalid webhook subscription")
+		return
+	}
+
+	subscription := &domain.WebhookSubscription{}
+	in.apply(subscription)
+	if err := subscription.Validate(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	secret, err := service.GenerateWebhookSecret()
+	if err != nil {
+		response.InternalServerError(c, "Failed to create webhook subscription")
+		return
+	}
+	subscription.Secret = secret
+
+	if err := h.repo.CreateSubscription(c.Request.Context(), subscription); err != nil {
+		response.InternalServerError(c, "Failed to create webhook subscription")
+		return
+	}
+
+	middleware.SetAuditAction(c, "webhook.create", gin.H{"id": subscription.ID, "url": subscription.URL})
+	response.Created(c, createdWebhook{WebhookSubscription: *subscription, Secret: secret})
+}
+
+// ListSubscriptions handles the HTTP request to list every webhook subscription.
+//
+// Responses:
+// - 200: Returns the subscriptions.
+// - 500: The subscriptions could not be read.
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.repo.ListSubscriptions(c.Request.Context(), false)
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve webhook subscriptions")
+		return
+	}
+
+	response.Success(c, http.StatusOK, subscriptions)
+}
+
+// GetSubscription handles the HTTP request to read a single webhook subscription.
+//
+// Responses:
+// - 200: Returns the subscription.
+// - 400: The ID is invalid.
+// - 404: No subscription has the given ID.
+// - 500: The subscription could not be read.
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.repo.FindSubscription(c.Request.Context(), id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, subscription)
+}
+
+// UpdateSubscription handles the HTTP request to replace the URL, topics, and active
+// flag of a webhook subscription. The signing secret is kept.
+//
+// Responses:
+// - 200: Returns the updated subscription.
+// - 400: The ID or the payload is invalid.
+// - 404: No subscription has the given ID.
+// - 500: The subscription could not be stored.
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var in webhookInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		response.BadRequest(c, "Invalid webhook subscription")
+		return
+	}
+	middleware.SetAuditAction(c, "webhook.update", gin.H{"id": id, "url": in.URL})
+
+	subscription, err := h.repo.FindSubscription(c.Request.Context(), id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	in.apply(subscription)
+	if err := subscription.Validate(); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	subscription.UpdatedAt = time.Now().UTC()
+
+	if err := h.repo.UpdateSubscription(c.Request.Context(), subscription); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, subscription)
+}
+
+// DeleteSubscription handles the HTTP request to remove a webhook subscription and its
+// delivery log.
+//
+// Responses:
+// - 204: The subscription was removed.
+// - 400: The ID is invalid.
+// - 404: No subscription has the given ID.
+// - 500: The subscription could not be removed.
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	middleware.SetAuditAction(c, "webhook.delete", gin.H{"id": id})
+
+	if err := h.repo.DeleteSubscription(c.Request.Context(), id); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles the HTTP request to read the delivery log of a subscription,
+// newest deliveries first.
+//
+// Query Parameters:
+// - page: (optional) The page number, 1 by default.
+// - pageSize: (optional) The number of deliveries per page, 50 by default.
+//
+// Responses:
+// - 200: Returns the requested page of deliveries.
+// - 400: The ID or the pagination parameters are invalid.
+// - 500: The delivery log could not be read.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	page, errPage := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, errSize := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if errPage != nil || errSize != nil || page <= 0 || pageSize <= 0 {
+		response.BadRequest(c, "Invalid parameters")
+		return
+	}
+
+	deliveries, err := h.repo.ListDeliveries(c.Request.Context(), id, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve webhook deliveries")
+		return
+	}
+
+	response.Success(c, http.StatusOK, deliveries)
+}
+
+// webhookID parses the subscription ID path parameter, answering 400 if it is invalid.
+func webhookID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.BadRequest(c, "Invalid webhook subscription ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondWebhookError maps a missing subscription to 404 and anything else to 500.
+func respondWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		response.NotFound(c, "Webhook subscription not found")
+		return
+	}
+	response.InternalServerError(c, "Failed to process webhook subscription")
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/response"
+)
+
+// Context keys set by the authentication middleware.
+const (
+	// ActorKey holds the name of the authenticated admin.
+	ActorKey = "actor"
+	// PrincipalKey holds the domain.Principal describing the authenticated caller.
+	PrincipalKey = "principal"
+)
+
+// AdminAuth returns a Gin middleware that only lets requests carrying a known admin
+// token through. Tokens are sent as "Authorization: Bearer <token>".
+//
+// Parameters:
+// - tokens: a map of admin token to actor name. An empty map rejects every request.
+//
+// The actor name associated with the token is stored in the context under ActorKey,
+// so handlers and the audit log can attribute the action. The full principal, with its
+// roles and rate-limit tier, is stored under PrincipalKey.
+func AdminAuth(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, bearer := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !bearer || token == "" {
+			c.Abort()
+			response.Error(c, http.StatusUnauthorized, "Missing admin token")
+			return
+		}
+
+		actor, ok := lookupActor(tokens, token)
+		if !ok {
+			c.Abort()
+			response.Error(c, http.StatusUnauthorized, "Invalid admin token")
+			return
+		}
+
+		c.Set(ActorKey, actor)
+		c.Set(PrincipalKey, domain.Principal{
+			Name:          actor,
+			Roles:         []string{domain.RoleAdmin},
+			RateLimitTier: domain.RateLimitTierUnlimited,
+		})
+		c.Next()
+	}
+}
+
+// lookupActor finds the actor owning token, comparing every token in constant time.
+func lookupActor(tokens map[string]string, token string) (string, bool) {
+	var (
+		actor string
+		found bool
+	)
+	for candidate, name := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			actor, found = name, true
+		}
+	}
+	return actor, found
+}
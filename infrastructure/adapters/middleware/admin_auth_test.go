@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+type fakeAuditRepository struct {
+	entries []domain.AuditEntry
+}
+
+func (f *fakeAuditRepository) Record(_ context.Context, entry *domain.AuditEntry) error {
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+func (f *fakeAuditRepository) List(_ context.Context, _, _ int) ([]domain.AuditEntry, error) {
+	return f.entries, nil
+}
+
+func TestAdminAuthAndAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	audit := &fakeAuditRepository{}
+	router := gin.New()
+	admin := router.Group("/admin", AdminAuth(map[string]string{"s3cret": "alice"}), AuditLog(audit))
+	admin.POST("/cache/purge", func(c *gin.Context) {
+		SetAuditAction(c, "cache.purge", gin.H{"caches": 2})
+		c.Status(http.StatusOK)
+	})
+	admin.GET("/audit", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var principal domain.Principal
+	admin.GET("/whoami", func(c *gin.Context) {
+		principal = c.MustGet(PrincipalKey).(domain.Principal)
+		c.Status(http.StatusOK)
+	})
+
+	send := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("should reject missing and unknown tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/admin/cache/purge", ""))
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, "/admin/cache/purge", "wrong"))
+		assert.Empty(t, audit.entries)
+	})
+
+	t.Run("should reject tokens without the Bearer scheme", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/cache/purge", http.NoBody)
+		req.Header.Set("Authorization", "s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, audit.entries)
+	})
+
+	t.Run("should record actions with the actor identity", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodPost, "/admin/cache/purge", "s3cret"))
+
+		assert.Len(t, audit.entries, 1)
+		entry := audit.entries[0]
+		assert.Equal(t, "alice", entry.Actor)
+		assert.Equal(t, "cache.purge", entry.Action)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		assert.JSONEq(t, `{"caches": 2}`, entry.Details)
+	})
+
+	t.Run("should not record read-only requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/admin/audit", "s3cret"))
+		assert.Len(t, audit.entries, 1)
+	})
+
+	t.Run("should expose the authenticated principal", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(http.MethodGet, "/admin/whoami", "s3cret"))
+
+		assert.Equal(t, "alice", principal.Name)
+		assert.Equal(t, []string{domain.RoleAdmin}, principal.Roles)
+		assert.Equal(t, domain.RateLimitTierUnlimited, principal.RateLimitTier)
+		assert.Nil(t, principal.Quota)
+	})
+}
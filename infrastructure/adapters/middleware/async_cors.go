@@ -34,6 +34,8 @@ func AsyncCORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 		go func() {
 			defer close(done)
 
+			// The allowed origin is echoed back, so cached responses must be told apart by it
+			c.Writer.Header().Add("Vary", "Origin")
 			if origin := c.Request.Header.Get("Origin"); origin != "" {
 				if _, exists := originSet[origin]; exists {
 					c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
@@ -42,9 +44,10 @@ func AsyncCORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			c.Writer.Header().Set("Access-Control-Allow-Headers",
-				"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+				"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-None-Match")
 			c.Writer.Header().Set("Access-Control-Allow-Methods",
-				"POST, OPTIONS, GET, PUT, DELETE, PATCH")
+				"POST, OPTIONS, GET, HEAD, PUT, DELETE, PATCH")
+			c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Stale, X-Sandbox, X-As-Of, Warning, ETag")
 
 			if c.Request.Method == "OPTIONS" {
 				c.AbortWithStatus(http.StatusNoContent)
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// Context keys used by handlers to describe the audited action.
+const (
+	auditActionKey  = "audit.action"
+	auditDetailsKey = "audit.details"
+)
+
+// SetAuditAction names the action performed by the current request and attaches details
+// (any JSON-serializable value) to its audit entry.
+func SetAuditAction(c *gin.Context, action string, details interface{}) {
+	c.Set(auditActionKey, action)
+	if details != nil {
+		c.Set(auditDetailsKey, details)
+	}
+}
+
+// AuditLog returns a Gin middleware that records every state-changing request
+// (anything but GET, HEAD, and OPTIONS) in the audit log once the handler has finished.
+// It must run after AdminAuth so the actor is known.
+//
+// Entries are written with their own timeout, so a client disconnecting does not
+// prevent the action from being recorded. Failures to write are logged.
+func AuditLog(repo port.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+
+		entry := &domain.AuditEntry{
+			Actor:     c.GetString(ActorKey),
+			Action:    c.GetString(auditActionKey),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			CreatedAt: time.Now().UTC(),
+		}
+		if entry.Action == "" {
+			entry.Action = c.Request.Method + " " + c.FullPath()
+		}
+		if details, ok := c.Get(auditDetailsKey); ok {
+			if b, err := json.Marshal(details); err == nil {
+				entry.Details = string(b)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := repo.Record(ctx, entry); err != nil {
+			log.Printf("Error recording audit entry %q by %q: %v", entry.Action, entry.Actor, err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// CacheHints collects the cache hints the services give while a request is handled, so
+// the response carries a caching policy fitting the data it was built from (see
+// response.Success). Requests whose services give no hint get no caching headers.
+func CacheHints() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(domain.WithCacheHints(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/response"
+)
+
+// Priority is the load-shedding class of a route. Under pressure, lower classes are
+// rejected first so the higher ones keep succeeding.
+type Priority int
+
+const (
+	// PriorityLow is for bulk list queries, which are shed first.
+	PriorityLow Priority = iota
+	// PriorityNormal is for recommendations and single-stock reads.
+	PriorityNormal
+	// PriorityCritical is for health checks and administration, which are never shed.
+	PriorityCritical
+)
+
+// String returns the name of the class used in metrics.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	default:
+		return "critical"
+	}
+}
+
+// Default occupancy of the busiest resource from which requests of each class are shed.
+const (
+	defaultLowShedThreshold    = 0.5
+	defaultNormalShedThreshold = 0.9
+)
+
+// sheddingMetrics counts the admitted and shed requests per class, as
+// "<class>.admitted" and "<class>.shed".
+var sheddingMetrics = expvar.NewMap("load_shedding")
+
+// Occupancy reports how many units of a bounded resource, such as a worker pool or a
+// database connection pool, are in use out of its capacity.
+type Occupancy func() (inUse, capacity int)
+
+// LoadShedder watches the resources requests wait for and rejects low-priority work when
+// they approach their capacity, leaving room for the rest.
+type LoadShedder struct {
+	resources  []Occupancy
+	thresholds map[Priority]float64 // Critical requests have no threshold
+}
+
+// NewLoadShedder creates a shedder measuring the occupancy of the given resources. A
+// request is judged by the busiest of them.
+func NewLoadShedder(resources ...Occupancy) *LoadShedder {
+	return &LoadShedder{
+		resources: resources,
+		thresholds: map[Priority]float64{
+			PriorityLow:    defaultLowShedThreshold,
+			PriorityNormal: defaultNormalShedThreshold,
+		},
+	}
+}
+
+// WithThresholds overrides the occupancy from which low and normal priority requests are
+// shed. It must be called before the middlewares are created with Handle.
+func (s *LoadShedder) WithThresholds(low, normal float64) *LoadShedder {
+	s.thresholds[PriorityLow] = low
+	s.thresholds[PriorityNormal] = normal
+	return s
+}
+
+// Handle returns a Gin middleware admitting requests of the given class. Shed requests
+// are answered with 503 and a Retry-After header.
+func (s *LoadShedder) Handle(priority Priority) gin.HandlerFunc {
+	threshold, sheddable := s.thresholds[priority]
+
+	return func(c *gin.Context) {
+		if sheddable && s.Load() >= threshold {
+			sheddingMetrics.Add(priority.String()+".shed", 1)
+			c.Header("Retry-After", "1")
+			c.Abort()
+			response.Error(c, http.StatusServiceUnavailable, "Server overloaded, please retry")
+			return
+		}
+
+		sheddingMetrics.Add(priority.String()+".admitted", 1)
+		c.Next()
+	}
+}
+
+// Load returns the occupancy of the busiest resource, from 0 (idle) to 1 (full).
+// Resources without capacity are ignored.
+func (s *LoadShedder) Load() float64 {
+	load := 0.0
+	for _, occupancy := range s.resources {
+		inUse, capacity := occupancy()
+		if capacity > 0 {
+			load = max(load, float64(inUse)/float64(capacity))
+		}
+	}
+	return load
+}
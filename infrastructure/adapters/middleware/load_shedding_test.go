@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var workers, connections atomic.Int64
+	shedder := NewLoadShedder(
+		func() (int, int) { return int(workers.Load()), 4 },
+		func() (int, int) { return int(connections.Load()), 10 },
+		func() (int, int) { return 3, 0 }, // Unbounded, ignored
+	)
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/list", shedder.Handle(PriorityLow), ok)
+	router.GET("/recommendations", shedder.Handle(PriorityNormal), ok)
+	router.GET("/health", shedder.Handle(PriorityCritical), ok)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	lowShed := func() int64 {
+		metric := sheddingMetrics.Get("low.shed")
+		if metric == nil {
+			return 0
+		}
+		v, _ := strconv.ParseInt(metric.String(), 10, 64)
+		return v
+	}
+
+	t.Run("should admit every class while the resources are idle", func(t *testing.T) {
+		assert.Equal(t, 0.0, shedder.Load())
+		assert.Equal(t, http.StatusOK, serve("/list").Code)
+	})
+
+	// Occupy half the worker pool
+	workers.Store(2)
+	shedBefore := lowShed()
+
+	t.Run("should shed low-priority requests first", func(t *testing.T) {
+		w := serve("/list")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Equal(t, shedBefore+1, lowShed())
+	})
+
+	t.Run("should admit higher-priority requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/recommendations").Code)
+		assert.Equal(t, http.StatusOK, serve("/health").Code)
+	})
+
+	t.Run("should be judged by the busiest resource", func(t *testing.T) {
+		workers.Store(0)
+		connections.Store(9)
+
+		assert.Equal(t, 0.9, shedder.Load())
+		assert.Equal(t, http.StatusServiceUnavailable, serve("/recommendations").Code)
+		assert.Equal(t, http.StatusOK, serve("/health").Code)
+	})
+
+	t.Run("should admit low-priority requests once the pressure is gone", func(t *testing.T) {
+		connections.Store(0)
+		assert.Equal(t, http.StatusOK, serve("/list").Code)
+	})
+}
+
+func TestLoadShedder_WithThresholds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	shedder := NewLoadShedder(func() (int, int) { return 7, 10 }).WithThresholds(0.8, 0.95)
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/list", shedder.Handle(PriorityLow), ok)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
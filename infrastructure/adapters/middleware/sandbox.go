@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/response"
+)
+
+// Sandbox labels every response of an instance running in sandbox mode, so clients can
+// tell its synthetic data from real data. JSON responses carry "sandbox": true and every
+// response the X-Sandbox header.
+func Sandbox() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.MarkSandbox(c)
+		c.Next()
+	}
+}
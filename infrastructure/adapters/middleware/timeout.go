@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/response"
+)
+
+// RequestTimeout bounds the time spent on each request. The deadline is set on the
+// request context, so it reaches the repositories and the outgoing calls made with it,
+// which give up once it passes. If the handler has not answered by then, the request is
+// answered with 504 Gateway Timeout.
+// A non-positive timeout leaves requests without a deadline.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			response.Error(c, http.StatusGatewayTimeout, "Request timed out")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(timeout time.Duration, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/", RequestTimeout(timeout), handler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	waitForDeadline := func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	}
+
+	t.Run("should answer 504 when the handler misses the deadline", func(t *testing.T) {
+		w := serve(10*time.Millisecond, waitForDeadline)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	})
+
+	t.Run("should keep the response of a handler handling the timeout", func(t *testing.T) {
+		w := serve(10*time.Millisecond, func(c *gin.Context) {
+			waitForDeadline(c)
+			c.Status(http.StatusServiceUnavailable)
+			c.Writer.WriteHeaderNow()
+		})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("should not set a deadline when disabled", func(t *testing.T) {
+		var hasDeadline bool
+		w := serve(0, func(c *gin.Context) {
+			_, hasDeadline = c.Request.Context().Deadline()
+			c.Status(http.StatusOK)
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, hasDeadline)
+	})
+}
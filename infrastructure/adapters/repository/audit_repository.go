@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// AuditRepository stores and retrieves audit log entries.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository.
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record inserts a new audit entry.
+func (r *AuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// List retrieves one page of audit entries, newest first.
+func (r *AuditRepository) List(ctx context.Context, page, pageSize int) ([]domain.AuditEntry, error) {
+	entries := []domain.AuditEntry{}
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Caches derived from the stocks table (such as countCache) are coordinated across
+// replicas through a generation counter stored in the database. Cache keys include the
+// generation they were computed at, and writes to the stocks table bump the counter once
+// they commit, so replicas drop their cached values shortly after a write.
+//
+// Bumping in every write transaction would serialize all writers on the single counter
+// row, and reading it on every count would add a round-trip. Instead, the writes made
+// within cacheGenerationInterval share a single bump, and the generation read is reused
+// for that long, so other replicas see a write within about two intervals. The replica
+// that wrote drops its own cached counts at once. A process exiting right after its
+// writes, such as an ingestion run, flushes the pending bump first.
+
+// stocksCacheGeneration names the generation counter of the stocks table.
+const stocksCacheGeneration = "stocks"
+
+// unknownGeneration is used while the shared generation cannot be read. Entries cached
+// under it are only consistent within this replica.
+const unknownGeneration int64 = -1
+
+// cacheGenerationInterval is how long writes wait for a shared bump of the generation,
+// and how long a generation read is reused.
+const cacheGenerationInterval = time.Second
+
+// cacheGenerationTimeout bounds each deferred bump of the generation.
+const cacheGenerationTimeout = 5 * time.Second
+
+// countCacheGeneration is the generation the entries of countCache belong to.
+var countCacheGeneration atomic.Int64
+
+// cacheGeneration is a row of the cache_generations table.
+type cacheGeneration struct {
+	Name       string `gorm:"primaryKey"`
+	Generation int64
+}
+
+// TableName overrides the table name used by GORM.
+func (cacheGeneration) TableName() string {
+	return "cache_generations"
+}
+
+// cacheGenerations reads and bumps the generation of the stocks table on behalf of a
+// repository, batching bumps and reusing reads for interval.
+type cacheGenerations struct {
+	db       *gorm.DB
+	interval time.Duration
+
+	mu         sync.Mutex
+	generation int64
+	readAt     time.Time     // Zero when the generation must be read again
+	bumpTimer  *time.Timer   // Set while a bump is pending
+	bumpDone   chan struct{} // Closed once the last scheduled bump has run
+
+	reads singleflight.Group
+}
+
+// newCacheGenerations creates the generation tracker of the stocks table stored in db.
+func newCacheGenerations(db *gorm.DB, interval time.Duration) *cacheGenerations {
+	return &cacheGenerations{db: db, interval: interval}
+}
+
+// current returns the generation of the stocks table, read at most once per interval.
+// A table that has never been written has generation 0. If the generation cannot be
+// read, unknownGeneration is returned with the error.
+func (g *cacheGenerations) current(ctx context.Context) (int64, error) {
+	g.mu.Lock()
+	generation, fresh := g.generation, !g.readAt.IsZero() && time.Since(g.readAt) < g.interval
+	g.mu.Unlock()
+	if fresh {
+		return generation, nil
+	}
+
+	// Concurrent counts share a single read
+	val, err, _ := g.reads.Do(stocksCacheGeneration, func() (interface{}, error) {
+		generation, err := readCacheGeneration(ctx, g.db)
+		if err != nil {
+			return unknownGeneration, err
+		}
+		g.mu.Lock()
+		g.generation, g.readAt = generation, time.Now()
+		g.mu.Unlock()
+		return generation, nil
+	})
+	return val.(int64), err
+}
+
+// bumpSoon records a committed write. This replica drops its cached counts at once, and
+// the shared generation is bumped after interval, together with the other writes made
+// in the meantime.
+func (g *cacheGenerations) bumpSoon() {
+	countCache.Clear()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.bumpTimer != nil {
+		return
+	}
+	done := make(chan struct{})
+	g.bumpDone = done
+	g.bumpTimer = time.AfterFunc(g.interval, func() {
+		defer close(done)
+
+		// Writes made from now on need another bump
+		g.mu.Lock()
+		g.bumpTimer = nil
+		g.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cacheGenerationTimeout)
+		defer cancel()
+		if err := g.bump(ctx); err != nil {
+			log.Printf("Error bumping cache generation: %v", err)
+		}
+	})
+}
+
+// flush runs the pending bump of the shared generation at once, or waits for the one
+// running, so the writes made just before the process exits reach the other replicas.
+func (g *cacheGenerations) flush(ctx context.Context) error {
+	g.mu.Lock()
+	timer, done := g.bumpTimer, g.bumpDone
+	stopped := timer != nil && timer.Stop()
+	if stopped {
+		g.bumpTimer = nil
+	}
+	g.mu.Unlock()
+
+	if stopped {
+		defer close(done)
+		return g.bump(ctx)
+	}
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// bump increments the shared generation at once, and makes the next count read it.
+func (g *cacheGenerations) bump(ctx context.Context) error {
+	if err := bumpCacheGeneration(g.db.WithContext(ctx)); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.readAt = time.Time{}
+	g.mu.Unlock()
+	return nil
+}
+
+// readCacheGeneration returns the current generation of the stocks table.
+// A table that has never been written has generation 0.
+func readCacheGeneration(ctx context.Context, db *gorm.DB) (int64, error) {
+	var rows []cacheGeneration
+	err := db.WithContext(ctx).
+		Where("name = ?", stocksCacheGeneration).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil {
+		return unknownGeneration, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Generation, nil
+}
+
+// bumpCacheGeneration increments the generation of the stocks table.
+func bumpCacheGeneration(db *gorm.DB) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"generation": gorm.Expr("cache_generations.generation + 1")}),
+	}).Create(&cacheGeneration{Name: stocksCacheGeneration, Generation: 1}).Error
+}
+
+// syncCountCache drops the entries of countCache when the generation moved, so entries
+// of previous generations do not accumulate.
+func syncCountCache(generation int64) {
+	if countCacheGeneration.Swap(generation) != generation {
+		countCache.Clear()
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"stock-api/infrastructure/adapters/repository/querybuilder"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// pricePattern matches the target price strings that can be safely cast to a number
+// (e.g., "$1,234.50"). It avoids "?" so the expression is never mistaken for a placeholder.
+const pricePattern = `'^[$]{0,1}[0-9,]+([.][0-9]+){0,1}$'`
+
+// stockColumns describes every field of the stocks table that can be filtered or sorted,
+// including the virtual fields computed on the fly, which can only be used for sorting.
+// Its score uses the built-in classification points; queries sort by the score under the
+// weights in effect through scoredColumns.
+var stockColumns = querybuilder.New(
+	querybuilder.Column{Name: "id", Type: querybuilder.TypeNumber},
+	querybuilder.Column{Name: "created_at", Type: querybuilder.TypeTime},
+	querybuilder.Column{Name: "updated_at", Type: querybuilder.TypeTime},
+	querybuilder.Column{Name: "ticker", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "target_from", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "target_to", Type: querybuilder.TypeString},
+	querybuilder.Column{
+		Name:       "company",
+		Type:       querybuilder.TypeString,
+		SearchExpr: "company_normalized",
+		Normalize:  domain.NormalizeCompany,
+	},
+	querybuilder.Column{Name: "action", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "brokerage", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "rating_from", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "rating_to", Type: querybuilder.TypeString},
+	querybuilder.Column{Name: "time", Type: querybuilder.TypeTime},
+	querybuilder.Column{
+		Name:         "classifications",
+		Type:         querybuilder.TypeStringArray,
+		Placeholders: []string{domain.NeutralLabel},
+	},
+	querybuilder.Column{Name: "warnings", Type: querybuilder.TypeStringArray},
+	querybuilder.Column{
+		Name:       "upside",
+		Expr:       upsideSQL(),
+		Type:       querybuilder.TypeNumber,
+		MatchModes: []querybuilder.MatchMode{},
+		Computed:   true,
+	},
+	querybuilder.Column{
+		Name:       "score",
+		Expr:       scoreSQL(domain.ClassificationPoints),
+		Type:       querybuilder.TypeNumber,
+		MatchModes: []querybuilder.MatchMode{},
+		Computed:   true,
+	},
+)
+
+// VirtualFields returns the names of the computed fields supported for sorting.
+func VirtualFields() []string {
+	var fields []string
+	for _, column := range stockColumns.Columns() {
+		if column.Computed {
+			fields = append(fields, column.Name)
+		}
+	}
+	return fields
+}
+
+// priceSQL returns an expression casting a currency column (e.g., "$13.00") to DECIMAL.
+func priceSQL(column string) string {
+	return fmt.Sprintf("CAST(REPLACE(REPLACE(%s, '$', ''), ',', '') AS DECIMAL)", column)
+}
+
+// upsideSQL mirrors domain.Stock.GetUpside: the percentage change between target_from
+// and target_to. Rows whose targets cannot be parsed, or whose target_from is zero,
+// evaluate to NULL. The nested CASE guarantees the casts only run on parsable values.
+func upsideSQL() string {
+	from, to := priceSQL("target_from"), priceSQL("target_to")
+	return fmt.Sprintf(
+		"(CASE WHEN target_from ~ %[1]s AND target_to ~ %[1]s THEN (CASE WHEN %[2]s <> 0 THEN (%[3]s - %[2]s) / %[2]s * 100 END) END)",
+		pricePattern, from, to,
+	)
+}
+
+// scoredColumns returns stockColumns with a score computed from the given classification
+// points.
+func scoredColumns(points map[string]float64) *querybuilder.Builder {
+	return stockColumns.WithExpr("score", scoreSQL(points))
+}
+
+// classificationPoints returns the classification points in effect: those supplied by
+// provider, or the built-in ones without a provider. The points must not be modified.
+func classificationPoints(provider port.ScoringWeightsProvider) map[string]float64 {
+	if provider == nil {
+		return domain.ClassificationPoints
+	}
+	return provider.CurrentScoringWeights().Points
+}
+
+// scoreSQL mirrors the recommendation score: capped upside points plus the given points
+// of every classification and the points of the final rating.
+func scoreSQL(points map[string]float64) string {
+	terms := []string{
+		fmt.Sprintf("LEAST(COALESCE(%s, 0) * 2, %d)", upsideSQL(), domain.MaxUpsidePoints),
+	}
+
+	for _, label := range sortedKeys(points) {
+		terms = append(terms, fmt.Sprintf(
+			"(CASE WHEN %s = ANY(classifications) THEN %g ELSE 0 END)",
+			quoteLiteral(label), points[label],
+		))
+	}
+
+	ratings := make([]string, 0, len(domain.RatingPoints))
+	for _, rating := range sortedKeys(domain.RatingPoints) {
+		ratings = append(ratings, fmt.Sprintf("WHEN %s THEN %g", quoteLiteral(rating), domain.RatingPoints[rating]))
+	}
+	terms = append(terms, fmt.Sprintf("(CASE rating_to %s ELSE 0 END)", strings.Join(ratings, " ")))
+
+	return "(" + strings.Join(terms, " + ") + ")"
+}
+
+// quoteLiteral quotes a string as a SQL literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sortedKeys returns the keys of m in ascending order, so generated SQL is deterministic.
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// defaultBackfillBatchSize is the number of rows normalized per transaction.
+const defaultBackfillBatchSize = 500
+
+// BackfillCompanyNormalized fills company_normalized on the rows written before the column
+// existed, including soft-deleted ones. It processes batchSize rows per transaction, so it
+// can be interrupted and resumed, and rows already normalized are never touched again.
+//
+// Returns:
+//   - The number of rows updated.
+//   - An error if a batch cannot be read or written.
+func (r *StockBDRepository) BackfillCompanyNormalized(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+
+	total := 0
+	for {
+		var rows []domain.Stock
+		err := r.db.WithContext(ctx).Unscoped().
+			Select("id", "company").
+			Where("company_normalized IS NULL").
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return total, fmt.Errorf("error reading rows to backfill: %w", err)
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				// UpdateColumn skips hooks and leaves updated_at unchanged
+				err := tx.Unscoped().Model(&domain.Stock{}).
+					Where("id = ?", row.ID).
+					UpdateColumn("company_normalized", domain.NormalizeCompany(row.Company)).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, fmt.Errorf("error backfilling normalized company names: %w", err)
+		}
+		r.generations.bumpSoon()
+		total += len(rows)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// estimateThreshold is the estimated number of rows from which a query is considered
+// broad enough to return the planner estimate instead of running an exact COUNT(*).
+// Below it, an exact count is cheap and always preferred.
+const estimateThreshold = 10000
+
+// explainPlan is the subset of the EXPLAIN (FORMAT JSON) output needed to read the
+// row estimate of the top-level plan node.
+type explainPlan struct {
+	Plan struct {
+		PlanRows float64 `json:"Plan Rows"`
+	} `json:"Plan"`
+}
+
+// EstimateCount returns the number of stocks matching the filters, using planner
+// statistics when the result set is large. Unfiltered queries use pg_class.reltuples,
+// filtered ones the row estimate of EXPLAIN.
+//
+// Returns:
+//   - count: The estimated or exact number of matching stocks.
+//   - approximate: True when count is an estimate.
+//   - err: An error if the exact count fails.
+//
+// If the estimate is below estimateThreshold, or statistics are not available (for
+// example on databases without PostgreSQL's EXPLAIN JSON format), it falls back to Count.
+func (r *StockBDRepository) EstimateCount(ctx context.Context, filters domain.Filters) (count int, approximate bool, err error) {
+	estimate, err := r.estimateRows(ctx, filters)
+	if err != nil || estimate < estimateThreshold {
+		count, err = r.Count(ctx, filters)
+		return count, false, err
+	}
+	return estimate, true, nil
+}
+
+// estimateRows asks the database for its row estimate for the filtered query.
+func (r *StockBDRepository) estimateRows(ctx context.Context, filters domain.Filters) (int, error) {
+	if len(filters) == 0 {
+		return r.estimateTableRows(ctx)
+	}
+
+	// Build the filtered query without executing it
+	query := r.db.Session(&gorm.Session{DryRun: true}).WithContext(ctx).Model(&domain.Stock{})
+	for field, filter := range filters {
+		query = applyFilter(query, field, filter)
+	}
+	query = query.Find(&[]domain.Stock{})
+	if query.Error != nil {
+		return 0, query.Error
+	}
+	stmt := query.Statement
+
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var raw []byte
+	row := sqlDB.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...)
+	if err := row.Scan(&raw); err != nil {
+		return 0, fmt.Errorf("error explaining count query: %w", err)
+	}
+
+	var plans []explainPlan
+	if err := json.Unmarshal(raw, &plans); err != nil || len(plans) == 0 {
+		return 0, errors.New("unexpected EXPLAIN output")
+	}
+	return int(plans[0].Plan.PlanRows), nil
+}
+
+// estimateTableRows reads the table row estimate maintained by ANALYZE/autovacuum.
+func (r *StockBDRepository) estimateTableRows(ctx context.Context) (int, error) {
+	var reltuples float64
+	err := r.db.WithContext(ctx).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = 'stocks'::regclass").
+		Scan(&reltuples).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// -1 means the table has never been analyzed
+	if reltuples < 0 {
+		return 0, errors.New("table statistics not available")
+	}
+	return int(reltuples), nil
+}
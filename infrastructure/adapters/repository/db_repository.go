@@ -4,14 +4,20 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/lib/pq"
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
 	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/eventbus"
 )
 
 // In-memory cache for Count results
@@ -23,26 +29,46 @@ var (
 // StockBDRepository is the repository responsible for interacting with the database
 // for operations related to the Stock model.
 type StockBDRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	bus         *eventbus.Bus
+	generations *cacheGenerations
+	weights     port.ScoringWeightsProvider
 }
 
 // NewStockBDRepository creates a new instance of StockBDRepository.
-// It takes a GORM database instance as a parameter.
-func NewStockBDRepository(db *gorm.DB) *StockBDRepository {
-	repository := &StockBDRepository{db: db}
+// It takes a GORM database instance and an optional event bus, which receives
+// a StockWriteEvent after every successful write. A nil bus disables events.
+func NewStockBDRepository(db *gorm.DB, bus *eventbus.Bus) *StockBDRepository {
+	repository := &StockBDRepository{db: db, bus: bus, generations: newCacheGenerations(db, cacheGenerationInterval)}
 	return repository
 }
 
+// WithScoringWeights sorts by score with the weights supplied by provider instead of the
+// built-in classification points, so the score matches the recommendations.
+func (r *StockBDRepository) WithScoringWeights(provider port.ScoringWeightsProvider) *StockBDRepository {
+	r.weights = provider
+	return r
+}
+
 // Create inserts a new stock record into the database.
 // It takes a context and a pointer to a Stock object as parameters.
 func (r *StockBDRepository) Create(ctx context.Context, stock *domain.Stock) error {
-	return r.db.WithContext(ctx).Create(stock).Error
+	if err := r.db.WithContext(ctx).Create(stock).Error; err != nil {
+		return err
+	}
+	r.publishWrite(domain.WriteCreate, stock)
+	return nil
 }
 
 // Delete removes a stock record from the database by its ID.
 // It takes a context, a pointer to a Stock object, and the ID of the stock to delete.
 func (r *StockBDRepository) Delete(ctx context.Context, stock *domain.Stock, id uint) error {
-	return r.db.WithContext(ctx).Delete(stock, id).Error
+	if err := r.db.WithContext(ctx).Delete(stock, id).Error; err != nil {
+		return err
+	}
+	stock.ID = id
+	r.publishWrite(domain.WriteDelete, stock)
+	return nil
 }
 
 // Find retrieves a list of stocks from the database based on the provided pagination
@@ -60,7 +86,27 @@ func (r *StockBDRepository) Delete(ctx context.Context, stock *domain.Stock, id
 // Returns:
 //   - []domain.Stock: A slice of domain.Stock objects that match the query criteria.
 //   - error: An error object if the query fails, or nil if the operation is successful.
+//
+// Identical concurrent queries (same normalized pagination and filters) are coalesced,
+// so they share one DB round-trip. Each caller still honors its own context and receives
+// its own copy of the result, and the shared query runs until the last caller gives up.
 func (r *StockBDRepository) Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
+	key := getFindKey(pagination, filters)
+
+	stocks, shared, err := findQueries.do(ctx, key, func(queryCtx context.Context) ([]domain.Stock, error) {
+		return r.find(queryCtx, pagination, filters)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		stocks = copyStocks(stocks)
+	}
+	return stocks, nil
+}
+
+// find runs the filtered, ordered, and paginated query.
+func (r *StockBDRepository) find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
 	var stocks []domain.Stock
 	query := r.db.WithContext(ctx)
 
@@ -68,7 +114,7 @@ func (r *StockBDRepository) Find(ctx context.Context, pagination domain.Paginati
 		query = applyFilter(query, field, filter)
 	}
 
-	query = applyOrder(query, pagination)
+	query = r.applyOrder(query, pagination)
 	query = applyPagination(query, pagination)
 
 	if err := query.Find(&stocks).Error; err != nil {
@@ -88,6 +134,34 @@ func (r *StockBDRepository) FindAll(ctx context.Context, order string, page, lim
 	return stocks, nil
 }
 
+// FindRecentByTickers retrieves the events of the given tickers that happened at or after
+// since, ordered by ticker and time, in a single query.
+func (r *StockBDRepository) FindRecentByTickers(ctx context.Context, tickers []string, since time.Time) ([]domain.Stock, error) {
+	stocks := []domain.Stock{}
+	if len(tickers) == 0 {
+		return stocks, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("ticker IN ? AND time >= ?", tickers, since).
+		Order("ticker, time").
+		Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// Sample retrieves up to n stocks chosen at random.
+// Ordering by random() scans the whole table, so it is meant for occasional checks such
+// as the startup sanity report, not for request paths.
+func (r *StockBDRepository) Sample(ctx context.Context, n int) ([]domain.Stock, error) {
+	stocks := []domain.Stock{}
+	if err := r.db.WithContext(ctx).Order("random()").Limit(n).Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
 // FindByTicker retrieves a stock record from the database by its ticker.
 // It takes a context and the ticker string as parameters.
 // Returns a pointer to a Stock object and an error if any.
@@ -99,9 +173,172 @@ func (r *StockBDRepository) FindByTicker(ctx context.Context, ticker string) (*d
 	return &stock, nil
 }
 
+// FindLatestByTicker retrieves the most recent stock event for a ticker.
+// It returns domain.ErrStockNotFound if the ticker has no events.
+func (r *StockBDRepository) FindLatestByTicker(ctx context.Context, ticker string) (*domain.Stock, error) {
+	var stock domain.Stock
+	err := r.db.WithContext(ctx).Where("ticker = ?", ticker).Order("time DESC").Order("id DESC").First(&stock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrStockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// FindAsOf retrieves the stocks matching the filters as they stood at at, ordered and
+// paginated like Find. Unlike Find, identical queries are not coalesced.
+func (r *StockBDRepository) FindAsOf(ctx context.Context, at time.Time, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
+	var stocks []domain.Stock
+	query := r.readAt(ctx, at)
+
+	for field, filter := range filters {
+		query = applyFilter(query, field, filter)
+	}
+
+	query = r.applyOrder(query, pagination)
+	query = applyPagination(query, pagination)
+
+	if err := query.Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// CountAsOf returns the number of stocks matching the filters as they stood at at.
+// Past counts are not cached.
+func (r *StockBDRepository) CountAsOf(ctx context.Context, at time.Time, filters domain.Filters) (int, error) {
+	var count int64
+	query := r.readAt(ctx, at)
+	for field, filter := range filters {
+		query = applyFilter(query, field, filter)
+	}
+	err := query.Model(&domain.Stock{}).Count(&count).Error
+	return int(count), err
+}
+
+// FindAllAsOf retrieves a page of the stocks as they stood at at, in the given order.
+func (r *StockBDRepository) FindAllAsOf(ctx context.Context, at time.Time, order string, page, limit int) ([]domain.Stock, error) {
+	stocks := []domain.Stock{}
+	if err := r.readAt(ctx, at).Order(order).Offset((page - 1) * limit).Limit(limit).Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// FindLatestByTickerAsOf retrieves the most recent event of a ticker stored by at.
+// It returns domain.ErrStockNotFound if the ticker had no events then.
+func (r *StockBDRepository) FindLatestByTickerAsOf(ctx context.Context, at time.Time, ticker string) (*domain.Stock, error) {
+	var stock domain.Stock
+	err := r.readAt(ctx, at).Where("ticker = ?", ticker).Order("time DESC").Order("id DESC").First(&stock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrStockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// readAt starts a query over the rows as they stood at at: those created by then and not
+// deleted yet, including soft-deleted rows deleted since, with the values they had then
+// (see stock_revisions.go).
+func (r *StockBDRepository) readAt(ctx context.Context, at time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).Unscoped().Table("(?) AS stocks", stocksAt(r.db, at))
+}
+
+// FindByID retrieves a stock record by its ID.
+// It returns domain.ErrStockNotFound if no such record exists.
+func (r *StockBDRepository) FindByID(ctx context.Context, id uint) (*domain.Stock, error) {
+	var stock domain.Stock
+	err := r.db.WithContext(ctx).First(&stock, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrStockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// Update writes the given columns of an existing stock record. Only the listed columns
+// are written, including zero values, so cleared fields are persisted too. Writing the
+// company also writes its normalized form. The values the record had are kept as a
+// revision, in the same transaction.
+// It returns domain.ErrStockNotFound if the record no longer exists.
+func (r *StockBDRepository) Update(ctx context.Context, stock *domain.Stock, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	if slices.Contains(columns, "company") && !slices.Contains(columns, "company_normalized") {
+		columns = append(slices.Clip(columns), "company_normalized")
+	}
+	if !slices.Contains(columns, "updated_at") {
+		columns = append(slices.Clip(columns), "updated_at")
+	}
+
+	now := time.Now()
+	stock.UpdatedAt = now
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := recordRevisions(tx, now, stock.ID); err != nil {
+			return err
+		}
+		result := tx.Model(stock).Select(columns).Updates(stock)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrStockNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.publishWrite(domain.WriteUpdate, stock)
+	return nil
+}
+
+// UpdateClassifications writes the classifications of several stocks in a single
+// transaction, keeping the values they had as revisions, and publishes a single update
+// event for all of them. Stocks deleted in the meantime are skipped.
+func (r *StockBDRepository) UpdateClassifications(ctx context.Context, stocks []*domain.Stock) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(stocks))
+	for i, stock := range stocks {
+		ids[i] = stock.ID
+	}
+
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := recordRevisions(tx, now, ids...); err != nil {
+			return err
+		}
+		for _, stock := range stocks {
+			stock.UpdatedAt = now
+			if err := tx.Model(stock).Select("classifications", "updated_at").Updates(stock).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.publishWrite(domain.WriteUpdate, stocks...)
+	return nil
+}
+
 // FindByClassification retrieves all stocks that match a specific classification.
 // It takes a context and the classification string as parameters.
 // Returns a slice of Stock objects and an error if any.
+//
+// Deprecated: FindByClassification loads every matching row without ordering or limits.
+// Use FindByClassificationPaginated instead.
 func (r *StockBDRepository) FindByClassification(ctx context.Context, classification string) ([]domain.Stock, error) {
 	var stocks []domain.Stock
 	err := r.db.WithContext(ctx).
@@ -110,22 +347,117 @@ func (r *StockBDRepository) FindByClassification(ctx context.Context, classifica
 	return stocks, err
 }
 
+// FindByClassificationPaginated retrieves one page of the stocks that match a specific
+// classification, ordered and paginated according to the provided parameters.
+func (r *StockBDRepository) FindByClassificationPaginated(
+	ctx context.Context,
+	classification string,
+	pagination domain.PaginationParams,
+) ([]domain.Stock, error) {
+	var stocks []domain.Stock
+	query := r.db.WithContext(ctx).Where("classifications @> ?", pq.StringArray{classification})
+
+	query = r.applyOrder(query, pagination)
+	query = applyPagination(query, pagination)
+
+	if err := query.Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// CountByClassification returns the number of stocks that match a specific classification.
+func (r *StockBDRepository) CountByClassification(ctx context.Context, classification string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Stock{}).
+		Where("classifications @> ?", pq.StringArray{classification}).
+		Count(&count).Error
+	return int(count), err
+}
+
 // SaveBatch inserts multiple stock records into the database in batches.
 // It takes a context and a slice of pointers to Stock objects as parameters.
 func (r *StockBDRepository) SaveBatch(ctx context.Context, data []*domain.Stock) error {
-	return r.db.WithContext(ctx).CreateInBatches(data, len(data)).Error
+	if err := r.db.WithContext(ctx).CreateInBatches(data, len(data)).Error; err != nil {
+		return err
+	}
+	r.publishWrite(domain.WriteBatch, data...)
+	return nil
+}
+
+// Flush publishes the pending bump of the cache generation at once, so other replicas
+// drop their cached counts before this process exits. It must be called before the
+// database connection is closed.
+func (r *StockBDRepository) Flush(ctx context.Context) error {
+	if err := r.generations.flush(ctx); err != nil {
+		return fmt.Errorf("error bumping cache generation: %w", err)
+	}
+	return nil
+}
+
+// publishWrite notifies the event bus about a successful write, and schedules the bump
+// of the cache generation it requires.
+func (r *StockBDRepository) publishWrite(operation domain.WriteOperation, stocks ...*domain.Stock) {
+	r.generations.bumpSoon()
+	publishStockWrite(r.bus, operation, stocks...)
+}
+
+// publishStockWrite publishes a StockWriteEvent on bus, if any.
+// The stocks are copied so subscribers never observe later mutations by the caller.
+func publishStockWrite(bus *eventbus.Bus, operation domain.WriteOperation, stocks ...*domain.Stock) {
+	if bus == nil {
+		return
+	}
+
+	copies := make([]domain.Stock, len(stocks))
+	for i, stock := range stocks {
+		copies[i] = stock.Clone()
+	}
+
+	eventbus.Publish(bus, eventbus.StockWrites, domain.StockWriteEvent{
+		Operation:  operation,
+		Stocks:     copies,
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+// countEntry is a cached Count result together with the time it was computed.
+type countEntry struct {
+	count    int
+	storedAt time.Time
 }
 
 // Count returns the number of stocks in the database that match the provided filters.
-// It uses an in-memory cache with the serialized and hashed filters as the key.
+// It uses an in-memory cache with the serialized and hashed filters, and the cache
+// generation of the stocks table, as the key, so cached counts are consistent across
+// replicas (see cache_generation.go).
 // Uses singleflight to avoid duplicate DB queries for the same key under concurrency.
 func (r *StockBDRepository) Count(ctx context.Context, filters domain.Filters) (int, error) {
-	cacheKey := getCacheKey(filters)
+	count, _, err := r.CountCached(ctx, filters)
+	return count, err
+}
+
+// CountCached behaves like Count and also reports when the returned count was computed,
+// if it may be outdated. cachedAt is the zero time when the count was just computed by
+// the database or is known to reflect the writes made until about two
+// cacheGenerationInterval ago. Only counts cached while the shared cache generation could
+// not be read may be outdated for longer.
+func (r *StockBDRepository) CountCached(ctx context.Context, filters domain.Filters) (count int, cachedAt time.Time, err error) {
+	generation, err := r.generations.current(ctx)
+	if err != nil {
+		log.Printf("Error reading cache generation, using the local count cache: %v", err)
+	}
+	syncCountCache(generation)
+	cacheKey := getCacheKey(generation, filters)
 
 	// Try to get from cache
 	if v, ok := countCache.Load(cacheKey); ok {
-		if cachedCount, ok := v.(int); ok {
-			return cachedCount, nil
+		if entry, ok := v.(countEntry); ok {
+			if generation == unknownGeneration {
+				return entry.count, entry.storedAt, nil
+			}
+			return entry.count, time.Time{}, nil
 		}
 	}
 
@@ -138,49 +470,101 @@ func (r *StockBDRepository) Count(ctx context.Context, filters domain.Filters) (
 		}
 		err := query.Model(&domain.Stock{}).Count(&count).Error
 		if err == nil {
-			countCache.Store(cacheKey, int(count))
+			countCache.Store(cacheKey, countEntry{count: int(count), storedAt: time.Now()})
 		}
 		return int(count), err
 	})
 	if err != nil {
-		return 0, err
+		return 0, time.Time{}, err
 	}
-	return val.(int), nil
+	return val.(int), time.Time{}, nil
 }
 
-// getCacheKey serializes and hashes the filters to generate a unique cache key.
-func getCacheKey(filters domain.Filters) string {
-	b, _ := json.Marshal(filters)
+// PurgeCache drops every cached Count result. It also bumps the shared cache
+// generation, so the other replicas drop their cached counts too.
+func (r *StockBDRepository) PurgeCache() {
+	countCache.Clear()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheGenerationTimeout)
+	defer cancel()
+	if err := r.generations.bump(ctx); err != nil {
+		log.Printf("Error bumping cache generation: %v", err)
+	}
+}
+
+// getCacheKey serializes and hashes the filters, together with the cache generation
+// they are counted at, to generate a unique cache key.
+func getCacheKey(generation int64, filters domain.Filters) string {
+	b, _ := json.Marshal(struct {
+		Generation int64
+		Filters    domain.Filters
+	}{generation, filters})
+	hash := sha256.Sum256(b)
+	return fmt.Sprintf("%x", hash)
+}
+
+// getFindKey builds the coalescing key of a Find query. Field names are normalized to
+// their column names, so "TargetFrom" and "target_from" share a key; the filters are
+// serialized with sorted keys.
+func getFindKey(pagination domain.PaginationParams, filters domain.Filters) string {
+	normalized := make(domain.Filters, len(filters))
+	for field, filter := range filters {
+		normalized[canonicalField(field)] = filter
+	}
+	pagination.SortField = canonicalField(pagination.SortField)
+
+	b, _ := json.Marshal(struct {
+		Pagination domain.PaginationParams
+		Filters    domain.Filters
+	}{pagination, normalized})
 	hash := sha256.Sum256(b)
 	return fmt.Sprintf("%x", hash)
 }
 
+// canonicalField returns the column name of field, or field itself if it is unknown.
+func canonicalField(field string) string {
+	if column, ok := stockColumns.Column(field); ok {
+		return column.Name
+	}
+	return field
+}
+
+// copyStocks deep-copies stocks so callers sharing a coalesced result cannot observe
+// each other's mutations.
+func copyStocks(stocks []domain.Stock) []domain.Stock {
+	if stocks == nil {
+		return nil
+	}
+	copies := make([]domain.Stock, len(stocks))
+	for i := range stocks {
+		copies[i] = stocks[i].Clone()
+	}
+	return copies
+}
+
+// applyFilter adds the parameterized condition for a single filter to the query.
+// Invalid filters (unknown field or unsupported match mode) are recorded as query errors.
 func applyFilter(query *gorm.DB, field string, filter domain.Filter) *gorm.DB {
-	switch filter.MatchMode {
-	case "equals":
-		query = query.Where(fmt.Sprintf("%s = ?", field), filter.Value)
-	case "contains":
-		query = query.Where(fmt.Sprintf("%s LIKE ?", field), fmt.Sprintf("%%%v%%", filter.Value))
-	case "startsWith":
-		query = query.Where(fmt.Sprintf("%s LIKE ?", field), fmt.Sprintf("%v%%", filter.Value))
-	case "endsWith":
-		query = query.Where(fmt.Sprintf("%s LIKE ?", field), fmt.Sprintf("%%%v", filter.Value))
-	case "greaterThan":
-		query = query.Where(fmt.Sprintf("%s > ?", field), filter.Value)
-	case "lessThan":
-		query = query.Where(fmt.Sprintf("%s < ?", field), filter.Value)
+	clause, err := stockColumns.Where(field, filter)
+	if err != nil {
+		_ = query.AddError(err)
+		return query
 	}
 
-	return query
+	return query.Where(clause.SQL, clause.Args...)
 }
 
-func applyOrder(query *gorm.DB, pagination domain.PaginationParams) *gorm.DB {
+// applyOrder adds the ORDER BY term requested by the pagination parameters, if any.
+// The score is computed with the weights in effect.
+func (r *StockBDRepository) applyOrder(query *gorm.DB, pagination domain.PaginationParams) *gorm.DB {
 	if pagination.SortField != "" {
-		order := "ASC"
-		if pagination.SortOrder == -1 {
-			order = "DESC"
+		order, err := scoredColumns(classificationPoints(r.weights)).OrderBy(pagination.SortField, pagination.SortOrder == -1)
+		if err != nil {
+			_ = query.AddError(err)
+			return query
 		}
-		query = query.Order(fmt.Sprintf("%s %s", pagination.SortField, order))
+
+		query = query.Order(order)
 	}
 
 	return query
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestGetFindKey(t *testing.T) {
+	pagination := domain.PaginationParams{Page: 1, PageSize: 10, SortField: "target_from", SortOrder: -1}
+	filters := domain.Filters{"ticker": {Value: "AAPL", MatchMode: "equals"}}
+
+	t.Run("should share a key across field name spellings", func(t *testing.T) {
+		respelled := pagination
+		respelled.SortField = "TargetFrom"
+
+		assert.Equal(t,
+			getFindKey(pagination, filters),
+			getFindKey(respelled, domain.Filters{"Ticker": {Value: "AAPL", MatchMode: "equals"}}),
+		)
+	})
+
+	t.Run("should distinguish different queries", func(t *testing.T) {
+		nextPage := pagination
+		nextPage.Page = 2
+
+		assert.NotEqual(t, getFindKey(pagination, filters), getFindKey(nextPage, filters))
+		assert.NotEqual(t,
+			getFindKey(pagination, filters),
+			getFindKey(pagination, domain.Filters{"ticker": {Value: "MSFT", MatchMode: "equals"}}),
+		)
+	})
+}
+
+func TestCopyStocks(t *testing.T) {
+	original := []domain.Stock{{Ticker: "AAPL", Classifications: domain.StringArray{"Tech"}, Warnings: domain.StringArray{domain.WarningUnknownRating}}}
+
+	copies := copyStocks(original)
+	copies[0].Ticker = "MSFT"
+	copies[0].Classifications[0] = "Bank"
+	copies[0].Warnings[0] = domain.WarningLargeTargetChange
+
+	assert.Equal(t, "AAPL", original[0].Ticker)
+	assert.Equal(t, domain.StringArray{"Tech"}, original[0].Classifications)
+	assert.Equal(t, domain.StringArray{domain.WarningUnknownRating}, original[0].Warnings)
+}
+
+func TestFindCoalescer(t *testing.T) {
+	coalescer := &findCoalescer{queries: make(map[string]*findQuery)}
+	stocks := []domain.Stock{{Ticker: "AAPL"}}
+
+	t.Run("should share one query among concurrent callers", func(t *testing.T) {
+		release := make(chan struct{})
+		var runs atomic.Int32
+		query := func(context.Context) ([]domain.Stock, error) {
+			runs.Add(1)
+			<-release
+			return stocks, nil
+		}
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, shared, err := coalescer.do(context.Background(), "key", query)
+				assert.NoError(t, err)
+				assert.True(t, shared)
+				assert.Equal(t, stocks, result)
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			coalescer.mu.Lock()
+			defer coalescer.mu.Unlock()
+			return coalescer.queries["key"] != nil && coalescer.queries["key"].waiters == 3
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), runs.Load())
+	})
+
+	t.Run("should keep the query running until the latest caller gives up", func(t *testing.T) {
+		queryCtx := make(chan context.Context, 1)
+		query := func(ctx context.Context) ([]domain.Stock, error) {
+			queryCtx <- ctx
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		early, cancelEarly := context.WithCancel(context.Background())
+		late, cancelLate := context.WithCancel(context.Background())
+		errs := make(chan error, 2)
+		go func() {
+			_, _, err := coalescer.do(early, "key", query)
+			errs <- err
+		}()
+		ctx := <-queryCtx
+		go func() {
+			_, _, err := coalescer.do(late, "key", query)
+			errs <- err
+		}()
+		assert.Eventually(t, func() bool {
+			coalescer.mu.Lock()
+			defer coalescer.mu.Unlock()
+			return coalescer.queries["key"].waiters == 2
+		}, time.Second, time.Millisecond)
+
+		cancelEarly()
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		assert.NoError(t, ctx.Err(), "the query should outlive the caller that started it")
+
+		cancelLate()
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		assert.Eventually(t, func() bool { return ctx.Err() != nil }, time.Second, time.Millisecond)
+	})
+}
+
+func TestCountCacheGeneration(t *testing.T) {
+	filters := domain.Filters{"ticker": {Value: "AAPL", MatchMode: "equals"}}
+
+	t.Run("should key cached counts by generation", func(t *testing.T) {
+		assert.Equal(t, getCacheKey(3, filters), getCacheKey(3, filters))
+		assert.NotEqual(t, getCacheKey(3, filters), getCacheKey(4, filters))
+	})
+
+	t.Run("should drop cached counts when the generation moves", func(t *testing.T) {
+		syncCountCache(3)
+		countCache.Store(getCacheKey(3, filters), countEntry{count: 1})
+
+		syncCountCache(3)
+		_, ok := countCache.Load(getCacheKey(3, filters))
+		assert.True(t, ok)
+
+		syncCountCache(4)
+		_, ok = countCache.Load(getCacheKey(3, filters))
+		assert.False(t, ok)
+	})
+}
+
+func TestCacheGenerations(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	generations := newCacheGenerations(db, 50*time.Millisecond)
+
+	t.Run("should reuse a generation read within the interval", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cache_generations"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "generation"}).AddRow(stocksCacheGeneration, 7))
+
+		for range 3 {
+			generation, err := generations.current(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), generation)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should share a single bump among the writes of an interval", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "cache_generations"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		countCache.Store("key", countEntry{count: 1})
+		for range 3 {
+			generations.bumpSoon()
+		}
+		_, ok := countCache.Load("key")
+		assert.False(t, ok, "the writing replica drops its counts at once")
+
+		assert.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// The bump makes the next count read the generation again
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "cache_generations"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "generation"}).AddRow(stocksCacheGeneration, 8))
+		generation, err := generations.current(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), generation)
+	})
+
+	t.Run("should run the pending bump at once when flushed", func(t *testing.T) {
+		generations := newCacheGenerations(db, time.Hour)
+		require.NoError(t, generations.flush(context.Background()), "nothing to flush")
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "cache_generations"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		generations.bumpSoon()
+		require.NoError(t, generations.flush(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// The flushed bump does not run again
+		require.NoError(t, generations.flush(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestScoreHistoryRepository(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	repo := NewScoreHistoryRepository(db)
+
+	t.Run("should return the latest entries, oldest first", func(t *testing.T) {
+		now := time.Now().UTC()
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY computed_at DESC, id DESC LIMIT $2`)).
+			WithArgs("AAPL", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "ticker", "score", "computed_at"}).
+				AddRow(3, "AAPL", 3.0, now).
+				AddRow(2, "AAPL", 2.0, now.Add(-time.Hour)))
+
+		entries, err := repo.ListByTicker(context.Background(), "AAPL", time.Time{}, time.Time{}, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, 2.0, entries[0].Score)
+		assert.Equal(t, 3.0, entries[1].Score)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStockRevisions(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	repo := NewStockBDRepository(db, nil)
+	ctx := context.Background()
+
+	t.Run("should keep the values of an updated stock in the transaction of the update", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stock_revisions (stock_id, revised_at, updated_at, ticker, `)).
+			WithArgs(sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stocks" SET "updated_at"=$1,"rating_to"=$2`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		stock := &domain.Stock{RatingTo: "Buy"}
+		stock.ID = 7
+		require.NoError(t, repo.Update(ctx, stock, []string{"rating_to"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should store cleared classifications as Neutral and cleared warnings as NULL", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stock_revisions`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stocks" SET "updated_at"=$1,"classifications"=$2,"warnings"=$3`)).
+			WithArgs(sqlmock.AnyArg(), `{"Neutral"}`, nil, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		stock := &domain.Stock{}
+		stock.ID = 5
+		require.NoError(t, repo.Update(ctx, stock, []string{"classifications", "warnings"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should drop the revision of a missing stock", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stock_revisions`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stocks"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		stock := &domain.Stock{RatingTo: "Buy"}
+		stock.ID = 8
+		assert.ErrorIs(t, repo.Update(ctx, stock, []string{"rating_to"}), domain.ErrStockNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should keep the values of reclassified stocks", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO stock_revisions`)).
+			WithArgs(sqlmock.AnyArg(), 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stocks" SET "updated_at"=$1,"classifications"=$2`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stocks" SET "updated_at"=$1,"classifications"=$2`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		stocks := []*domain.Stock{{Classifications: domain.StringArray{"Tech"}}, {Classifications: domain.StringArray{"Energy"}}}
+		stocks[0].ID, stocks[1].ID = 1, 2
+		require.NoError(t, repo.UpdateClassifications(ctx, stocks))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should read past views with the values of the first revision after the time", func(t *testing.T) {
+		at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM \(.+ UNION ALL .+`+
+			regexp.QuoteMeta(`revised_at > $6 ORDER BY revised_at, id LIMIT 1)) AS stocks WHERE rating_to = $7`)).
+			WithArgs(at, at, at, at, at, at, "Hold").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repo.CountAsOf(ctx, at, domain.Filters{"rating_to": {Value: "Hold", MatchMode: "equals"}})
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestScoringWeightVersions(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	repo := NewScoringWeightRepository(db)
+
+	expectClaim := func(latest int) *sqlmock.ExpectedExec {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(version), 0) FROM scoring_weight_versions`)).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(latest))
+		return mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO scoring_weight_versions (version) VALUES ($1)`)).
+			WithArgs(latest + 1)
+	}
+
+	t.Run("should retry with the next version when a concurrent append claimed it", func(t *testing.T) {
+		expectClaim(1).WillReturnError(&pgconn.PgError{Code: "23505"})
+		mock.ExpectRollback()
+		expectClaim(2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "scoring_weights"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		change := &domain.ScoringWeight{Label: "Tech", Points: 12, Actor: "admin"}
+		version, err := repo.Append(context.Background(), []*domain.ScoringWeight{change})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, version)
+		assert.Equal(t, 3, change.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should not retry other errors", func(t *testing.T) {
+		expectClaim(1).WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		_, err := repo.Append(context.Background(), []*domain.ScoringWeight{{Label: "Tech", Points: 12, Actor: "admin"}})
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
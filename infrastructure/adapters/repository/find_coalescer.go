@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// findQuery is a Find query shared by the concurrent callers asking for the same page.
+type findQuery struct {
+	done    chan struct{}
+	stocks  []domain.Stock
+	err     error
+	cancel  context.CancelFunc
+	waiters int  // Callers still waiting for the result
+	shared  bool // Whether several callers received the result
+}
+
+// findCoalescer coalesces identical concurrent Find queries into a single DB round-trip.
+// A shared query runs detached from the caller that started it, so callers giving up do
+// not fail the others. It is canceled once every caller waiting for it has given up, so
+// it is bounded by the latest deadline among them, which the request timeout sets.
+type findCoalescer struct {
+	mu      sync.Mutex
+	queries map[string]*findQuery
+}
+
+// findQueries coalesces the Find queries of every StockBDRepository.
+var findQueries = &findCoalescer{queries: make(map[string]*findQuery)}
+
+// do runs query under key, or joins the query already running under it, and waits for
+// its result until ctx is done. The returned stocks are shared with other callers when
+// shared is true.
+func (c *findCoalescer) do(
+	ctx context.Context,
+	key string,
+	query func(ctx context.Context) ([]domain.Stock, error),
+) (stocks []domain.Stock, shared bool, err error) {
+	c.mu.Lock()
+	q, running := c.queries[key]
+	if running {
+		q.shared = true
+	} else {
+		queryCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		q = &findQuery{done: make(chan struct{}), cancel: cancel}
+		c.queries[key] = q
+		go func() {
+			defer cancel()
+			q.stocks, q.err = query(queryCtx)
+			c.forget(key, q)
+			close(q.done)
+		}()
+	}
+	q.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-q.done:
+		c.mu.Lock()
+		shared = q.shared
+		c.mu.Unlock()
+		return q.stocks, shared, q.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if q.waiters--; q.waiters == 0 {
+			// Nobody needs the result any longer
+			if c.queries[key] == q {
+				delete(c.queries, key)
+			}
+			q.cancel()
+		}
+		return nil, false, ctx.Err()
+	}
+}
+
+// forget stops new callers from joining q.
+func (c *findCoalescer) forget(key string, q *findQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queries[key] == q {
+		delete(c.queries, key)
+	}
+}
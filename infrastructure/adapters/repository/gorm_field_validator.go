@@ -7,16 +7,31 @@ import (
 )
 
 type GormFieldValidator struct {
-	model     interface{}
-	cache     map[string]bool
-	cacheLock sync.RWMutex
+	model         interface{}
+	virtualFields map[string]struct{}
+	cache         map[string]bool
+	cacheLock     sync.RWMutex
 }
 
-func NewGormFieldValidator(model interface{}) *GormFieldValidator {
-	return &GormFieldValidator{
-		model: model,
-		cache: make(map[string]bool),
+// NewGormFieldValidator creates a validator for the fields of model.
+// virtualFields lists computed fields (e.g., "score") that are not part of the model
+// but are still accepted, for sorting only.
+func NewGormFieldValidator(model interface{}, virtualFields ...string) *GormFieldValidator {
+	v := &GormFieldValidator{
+		model:         model,
+		virtualFields: make(map[string]struct{}, len(virtualFields)),
+		cache:         make(map[string]bool),
 	}
+	for _, field := range virtualFields {
+		v.virtualFields[field] = struct{}{}
+	}
+	return v
+}
+
+// IsVirtualField reports whether field is a computed field that does not exist in the model.
+func (v *GormFieldValidator) IsVirtualField(field string) bool {
+	_, ok := v.virtualFields[field]
+	return ok
 }
 
 // IsValidField checks if the given field is valid by first looking it up in a cache.
@@ -72,16 +87,20 @@ func (v *GormFieldValidator) GetAllValidFields() []string {
 	return validFields
 }
 
-// checkField checks if a given field exists in the model associated with the GormFieldValidator.
-// It verifies the presence of the field by inspecting the struct's fields and their "gorm" tags.
+// checkField checks if a given field exists in the model associated with the GormFieldValidator,
+// or is one of its virtual fields. It verifies the presence of the field by inspecting the struct's fields and their "gorm" tags.
 //
 // Parameters:
 //   - field: The name of the field to check.
 //
 // Returns:
-//   - bool: True if the field exists in the model, either as a struct field name
+//   - bool: True if the field is virtual or exists in the model, either as a struct field name
 //     or as a column name specified in the "gorm" tag; otherwise, false.
 func (v *GormFieldValidator) checkField(field string) bool {
+	if v.IsVirtualField(field) {
+		return true
+	}
+
 	modelType := reflect.TypeOf(v.model)
 	if modelType.Kind() == reflect.Ptr {
 		modelType = modelType.Elem()
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"stock-api/infrastructure/adapters/repository/querybuilder"
+	"stock-api/infrastructure/core/domain"
+)
+
+// ErrUnknownColumn is returned by the memory backend when an update names a column the
+// stocks table does not have.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// priceRegexp is pricePattern compiled for Go, so the memory backend parses exactly the
+// target prices the SQL expressions cast.
+var priceRegexp = regexp.MustCompile(strings.Trim(pricePattern, "'"))
+
+// stockMatcher reports whether a stock satisfies a filter.
+type stockMatcher func(*domain.Stock) bool
+
+// orderTerm is a single sort key of the memory backend.
+type orderTerm struct {
+	field     string
+	desc      bool
+	nullsLast bool
+	points    map[string]float64 // Classification points of the score
+}
+
+// compileFilters turns filters into matchers. Filters are validated by the query builder
+// first, so the memory backend rejects exactly the filters the SQL backend rejects.
+func compileFilters(filters domain.Filters) ([]stockMatcher, error) {
+	matchers := make([]stockMatcher, 0, len(filters))
+	for field, filter := range filters {
+		clause, err := stockColumns.Where(field, filter)
+		if err != nil {
+			return nil, err
+		}
+		column, _ := stockColumns.Column(field)
+
+		matcher, err := compileFilter(column, querybuilder.MatchMode(filter.MatchMode), filter.Value, clause)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// compileFilter builds the matcher of a single validated filter. Array filters reuse the
+// labels parsed into the clause argument.
+func compileFilter(column querybuilder.Column, mode querybuilder.MatchMode, value interface{}, clause querybuilder.Clause) (stockMatcher, error) {
+	if column.Type == querybuilder.TypeStringArray {
+		labels, _ := clause.Args[0].(pq.StringArray)
+		return arrayMatcher(column.Name, mode, labels), nil
+	}
+
+	field := column.Name
+	if column.SearchExpr != "" && (mode == querybuilder.Contains || mode == querybuilder.StartsWith || mode == querybuilder.EndsWith) {
+		field = column.SearchExpr
+		value = column.Normalize(fmt.Sprint(value))
+	}
+
+	want, err := filterValue(column, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(stock *domain.Stock) bool {
+		got := stockValue(stock, field)
+		switch mode {
+		case querybuilder.Contains:
+			return strings.Contains(got.(string), want.(string))
+		case querybuilder.StartsWith:
+			return strings.HasPrefix(got.(string), want.(string))
+		case querybuilder.EndsWith:
+			return strings.HasSuffix(got.(string), want.(string))
+		case querybuilder.GreaterThan:
+			return compareValues(got, want) > 0
+		case querybuilder.LessThan:
+			return compareValues(got, want) < 0
+		default: // Equals
+			return compareValues(got, want) == 0
+		}
+	}, nil
+}
+
+// arrayMatcher builds the matcher of a filter on the array field, such as the
+// classifications.
+func arrayMatcher(field string, mode querybuilder.MatchMode, labels []string) stockMatcher {
+	return func(stock *domain.Stock) bool {
+		values, _ := stockValue(stock, field).([]string)
+		switch mode {
+		case querybuilder.ContainsAny:
+			return slices.ContainsFunc(labels, func(label string) bool {
+				return slices.Contains(values, label)
+			})
+		case querybuilder.HasNone:
+			// labels are the placeholders, which do not count as labels
+			return !slices.ContainsFunc(values, func(label string) bool {
+				return !slices.Contains(labels, label)
+			})
+		default: // Contains, ContainsAll
+			return !slices.ContainsFunc(labels, func(label string) bool {
+				return !slices.Contains(values, label)
+			})
+		}
+	}
+}
+
+// matchesAll reports whether stock satisfies every matcher.
+func matchesAll(stock *domain.Stock, matchers []stockMatcher) bool {
+	for _, matches := range matchers {
+		if !matches(stock) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValue converts a filter value to the Go type stockValue returns for the column.
+func filterValue(column querybuilder.Column, value interface{}) (interface{}, error) {
+	switch column.Type {
+	case querybuilder.TypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case json.Number:
+			return v.Float64()
+		case string:
+			number, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w for %s: %q is not a number", querybuilder.ErrInvalidFilterValue, column.Name, v)
+			}
+			return number, nil
+		}
+		return nil, fmt.Errorf("%w for %s: expected a number, got %T", querybuilder.ErrInvalidFilterValue, column.Name, value)
+	case querybuilder.TypeTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("%w for %s: %q is not a timestamp", querybuilder.ErrInvalidFilterValue, column.Name, v)
+		}
+		return nil, fmt.Errorf("%w for %s: expected a timestamp, got %T", querybuilder.ErrInvalidFilterValue, column.Name, value)
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// compileOrder validates a sort field like the SQL backend and returns its sort key.
+// Computed fields sort with NULLS LAST, as in SQL, and the score is computed with the
+// given classification points.
+func compileOrder(field string, desc bool, points map[string]float64) (orderTerm, error) {
+	if _, err := stockColumns.OrderBy(field, desc); err != nil {
+		return orderTerm{}, err
+	}
+	column, _ := stockColumns.Column(field)
+	return orderTerm{field: column.Name, desc: desc, nullsLast: column.Computed, points: points}, nil
+}
+
+// sortStocks sorts stocks by the given terms. Stocks comparing equal keep their order.
+func sortStocks(stocks []domain.Stock, terms ...orderTerm) {
+	slices.SortStableFunc(stocks, func(a, b domain.Stock) int {
+		for _, term := range terms {
+			if c := compareByTerm(&a, &b, term); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// compareByTerm compares two stocks by a single sort key.
+func compareByTerm(a, b *domain.Stock, term orderTerm) int {
+	va, vb := term.value(a), term.value(b)
+
+	// Computed values may be NULL, which sorts last in both directions
+	if pa, ok := va.(*float64); ok {
+		pb := vb.(*float64)
+		switch {
+		case pa == nil && pb == nil:
+			return 0
+		case pa == nil:
+			return 1
+		case pb == nil:
+			return -1
+		}
+		va, vb = *pa, *pb
+	}
+
+	c := compareValues(va, vb)
+	if term.desc {
+		return -c
+	}
+	return c
+}
+
+// value returns the sort key of stock.
+func (t orderTerm) value(stock *domain.Stock) interface{} {
+	if t.field == "score" {
+		return stockScore(stock, t.points)
+	}
+	return stockValue(stock, t.field)
+}
+
+// compareValues compares two values of the same type returned by stockValue.
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
+
+// stockValue returns the value of a column of the stocks table, or of the upside. The
+// score depends on the weights in effect, so orderTerm.value computes it.
+// Numbers are float64, timestamps time.Time, and the upside a *float64 that is nil where
+// the SQL expression evaluates to NULL.
+func stockValue(stock *domain.Stock, column string) interface{} {
+	switch column {
+	case "id":
+		return float64(stock.ID)
+	case "created_at":
+		return stock.CreatedAt
+	case "updated_at":
+		return stock.UpdatedAt
+	case "ticker":
+		return stock.Ticker
+	case "target_from":
+		return stock.TargetFrom
+	case "target_to":
+		return stock.TargetTo
+	case "company":
+		return stock.Company
+	case "company_normalized":
+		return stock.CompanyNormalized
+	case "action":
+		return stock.Action
+	case "brokerage":
+		return stock.Brokerage
+	case "rating_from":
+		return stock.RatingFrom
+	case "rating_to":
+		return stock.RatingTo
+	case "time":
+		return stock.Time
+	case "classifications":
+		return []string(stock.Classifications)
+	case "warnings":
+		return []string(stock.Warnings)
+	case "upside":
+		return stockUpside(stock)
+	default:
+		return nil
+	}
+}
+
+// stockUpside mirrors upsideSQL.
+func stockUpside(stock *domain.Stock) *float64 {
+	if !priceRegexp.MatchString(stock.TargetFrom) || !priceRegexp.MatchString(stock.TargetTo) {
+		return nil
+	}
+	upside, err := stock.GetUpside()
+	if err != nil {
+		return nil
+	}
+	return &upside
+}
+
+// stockScore mirrors scoreSQL.
+func stockScore(stock *domain.Stock, points map[string]float64) float64 {
+	score := 0.0
+	if upside := stockUpside(stock); upside != nil {
+		score = *upside * 2
+	}
+	score = min(score, domain.MaxUpsidePoints)
+
+	for label, labelPoints := range points {
+		if slices.Contains(stock.Classifications, label) {
+			score += labelPoints
+		}
+	}
+	return score + domain.RatingPoints[stock.RatingTo]
+}
+
+// setStockColumn copies a column of src to dst, as an update writing that column would.
+func setStockColumn(dst, src *domain.Stock, column string) error {
+	switch column {
+	case "ticker":
+		dst.Ticker = src.Ticker
+	case "target_from":
+		dst.TargetFrom = src.TargetFrom
+	case "target_to":
+		dst.TargetTo = src.TargetTo
+	case "company":
+		dst.Company = src.Company
+	case "company_normalized":
+		dst.CompanyNormalized = src.CompanyNormalized
+	case "action":
+		dst.Action = src.Action
+	case "brokerage":
+		dst.Brokerage = src.Brokerage
+	case "rating_from":
+		dst.RatingFrom = src.RatingFrom
+	case "rating_to":
+		dst.RatingTo = src.RatingTo
+	case "time":
+		dst.Time = src.Time
+	case "classifications":
+		dst.Classifications = slices.Clone(src.Classifications)
+	case "warnings":
+		dst.Warnings = slices.Clone(src.Warnings)
+	case "created_at":
+		dst.CreatedAt = src.CreatedAt
+	case "updated_at":
+		dst.UpdatedAt = src.UpdatedAt
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+	}
+	return nil
+}
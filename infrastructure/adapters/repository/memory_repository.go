@@ -0,0 +1,619 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+	"stock-api/infrastructure/eventbus"
+)
+
+// StockMemoryRepository is a stock repository kept in process memory. It mirrors the
+// behavior of StockBDRepository, including filters, sorting on computed fields, soft
+// deletes, revisions, and write events, without a database, so sandbox mode and tests can
+// run with no infrastructure. It is not meant for deployments: nothing survives a restart,
+// and each process has its own stocks.
+//
+// Filters and sort fields are validated against the same column registry as the SQL
+// backend, so both reject the same requests.
+type StockMemoryRepository struct {
+	mu        sync.RWMutex
+	stocks    []domain.Stock           // Ordered by ID
+	revisions map[uint][]stockRevision // By stock ID, oldest first
+	nextID    uint
+	bus       *eventbus.Bus
+	weights   port.ScoringWeightsProvider
+}
+
+// stockRevision holds the values a stock had until it was updated at revisedAt, like the
+// rows of the stock_revisions table.
+type stockRevision struct {
+	revisedAt time.Time
+	stock     domain.Stock
+}
+
+// NewStockMemoryRepository creates a new, empty instance of StockMemoryRepository.
+// It takes an optional event bus, which receives a StockWriteEvent after every
+// successful write. A nil bus disables events.
+func NewStockMemoryRepository(bus *eventbus.Bus) *StockMemoryRepository {
+	return &StockMemoryRepository{revisions: make(map[uint][]stockRevision), nextID: 1, bus: bus}
+}
+
+// WithScoringWeights sorts by score with the weights supplied by provider instead of the
+// built-in classification points, like StockBDRepository.WithScoringWeights.
+func (r *StockMemoryRepository) WithScoringWeights(provider port.ScoringWeightsProvider) *StockMemoryRepository {
+	r.weights = provider
+	return r
+}
+
+// Create stores a new stock, assigning its ID and timestamps.
+func (r *StockMemoryRepository) Create(ctx context.Context, stock *domain.Stock) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.insert(stock, time.Now())
+	r.mu.Unlock()
+
+	r.publishWrite(domain.WriteCreate, stock)
+	return nil
+}
+
+// SaveBatch stores several stocks at once.
+func (r *StockMemoryRepository) SaveBatch(ctx context.Context, data []*domain.Stock) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	for _, stock := range data {
+		r.insert(stock, now)
+	}
+	r.mu.Unlock()
+
+	r.publishWrite(domain.WriteBatch, data...)
+	return nil
+}
+
+// insert applies the hooks GORM would run and appends a copy of stock.
+// The caller must hold the write lock.
+func (r *StockMemoryRepository) insert(stock *domain.Stock, now time.Time) {
+	_ = stock.BeforeCreate(nil)
+	_ = stock.BeforeSave(nil)
+
+	stock.ID = r.nextID
+	r.nextID++
+	if stock.CreatedAt.IsZero() {
+		stock.CreatedAt = now
+	}
+	stock.UpdatedAt = now
+
+	r.stocks = append(r.stocks, stock.Clone())
+}
+
+// Delete soft-deletes the stock with the given ID, as GORM does for models embedding
+// gorm.Model. Deleting a missing stock is not an error.
+func (r *StockMemoryRepository) Delete(ctx context.Context, stock *domain.Stock, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if i, ok := r.index(id); ok {
+		r.stocks[i].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	r.mu.Unlock()
+
+	stock.ID = id
+	r.publishWrite(domain.WriteDelete, stock)
+	return nil
+}
+
+// Update writes the given columns of an existing stock. Writing the company also writes
+// its normalized form. The values the stock had are kept as a revision.
+// It returns domain.ErrStockNotFound if the stock does not exist, and an error wrapping
+// ErrUnknownColumn for columns it does not know.
+func (r *StockMemoryRepository) Update(ctx context.Context, stock *domain.Stock, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	i, ok := r.index(stock.ID)
+	if !ok {
+		r.mu.Unlock()
+		return domain.ErrStockNotFound
+	}
+
+	updated := r.stocks[i].Clone()
+	for _, column := range columns {
+		if err := setStockColumn(&updated, stock, column); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+	}
+	if slices.Contains(columns, "company") {
+		updated.CompanyNormalized = domain.NormalizeCompany(updated.Company)
+		stock.CompanyNormalized = updated.CompanyNormalized
+	}
+	updated.UpdatedAt = time.Now()
+	r.revise(i, updated.UpdatedAt)
+	r.stocks[i] = updated
+	r.mu.Unlock()
+
+	r.publishWrite(domain.WriteUpdate, stock)
+	return nil
+}
+
+// UpdateClassifications writes the classifications of several stocks, keeping the values
+// they had as revisions, and publishes a single update event for all of them. Stocks
+// deleted in the meantime are skipped.
+func (r *StockMemoryRepository) UpdateClassifications(ctx context.Context, stocks []*domain.Stock) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	for _, stock := range stocks {
+		i, ok := r.index(stock.ID)
+		if !ok {
+			continue
+		}
+		r.revise(i, now)
+		r.stocks[i].Classifications = append(domain.StringArray(nil), stock.Classifications...)
+		r.stocks[i].UpdatedAt = now
+		stock.UpdatedAt = now
+	}
+	r.mu.Unlock()
+
+	r.publishWrite(domain.WriteUpdate, stocks...)
+	return nil
+}
+
+// Find retrieves the stocks matching the filters, ordered and paginated according to the
+// pagination parameters. Without a sort field, stocks are returned in insertion order.
+// Invalid filters or sort fields are rejected with the same errors as the SQL backend.
+func (r *StockMemoryRepository) Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matchers, err := compileFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectLive(func(stock *domain.Stock) bool { return matchesAll(stock, matchers) })
+	r.mu.RUnlock()
+
+	if pagination.SortField != "" {
+		order, err := compileOrder(pagination.SortField, pagination.SortOrder == -1, classificationPoints(r.weights))
+		if err != nil {
+			return nil, err
+		}
+		sortStocks(stocks, order)
+	}
+
+	return paginate(stocks, pagination), nil
+}
+
+// FindAll retrieves a page of stocks in the given order.
+// The order uses SQL syntax restricted to a comma-separated list of sortable fields with
+// an optional direction (e.g., "time DESC, id"), which is what callers pass to the SQL
+// backend.
+func (r *StockMemoryRepository) FindAll(ctx context.Context, order string, page, limit int) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	terms, err := parseOrder(order, classificationPoints(r.weights))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectLive(nil)
+	r.mu.RUnlock()
+
+	sortStocks(stocks, terms...)
+	return paginate(stocks, domain.PaginationParams{Page: page, PageSize: limit}), nil
+}
+
+// FindByTicker retrieves the first stored event of a ticker.
+// It returns domain.ErrStockNotFound if the ticker has no events.
+func (r *StockMemoryRepository) FindByTicker(ctx context.Context, ticker string) (*domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.stocks {
+		if r.stocks[i].Ticker == ticker && !r.stocks[i].DeletedAt.Valid {
+			stock := r.stocks[i].Clone()
+			return &stock, nil
+		}
+	}
+	return nil, domain.ErrStockNotFound
+}
+
+// FindLatestByTicker retrieves the most recent event of a ticker.
+// It returns domain.ErrStockNotFound if the ticker has no events.
+func (r *StockMemoryRepository) FindLatestByTicker(ctx context.Context, ticker string) (*domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *domain.Stock
+	for i := range r.stocks {
+		stock := &r.stocks[i]
+		if stock.Ticker != ticker || stock.DeletedAt.Valid {
+			continue
+		}
+		// Later IDs win ties, as with "time DESC, id DESC"
+		if latest == nil || !stock.Time.Before(latest.Time) {
+			latest = stock
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrStockNotFound
+	}
+
+	stock := latest.Clone()
+	return &stock, nil
+}
+
+// FindAsOf retrieves the stocks matching the filters as they stood at at, ordered and
+// paginated like Find.
+func (r *StockMemoryRepository) FindAsOf(ctx context.Context, at time.Time, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matchers, err := compileFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectAt(at, func(stock *domain.Stock) bool { return matchesAll(stock, matchers) })
+	r.mu.RUnlock()
+
+	if pagination.SortField != "" {
+		order, err := compileOrder(pagination.SortField, pagination.SortOrder == -1, classificationPoints(r.weights))
+		if err != nil {
+			return nil, err
+		}
+		sortStocks(stocks, order)
+	}
+
+	return paginate(stocks, pagination), nil
+}
+
+// CountAsOf returns the number of stocks matching the filters as they stood at at.
+func (r *StockMemoryRepository) CountAsOf(ctx context.Context, at time.Time, filters domain.Filters) (int, error) {
+	stocks, err := r.FindAsOf(ctx, at, domain.PaginationParams{}, filters)
+	return len(stocks), err
+}
+
+// FindAllAsOf retrieves a page of the stocks as they stood at at, in the given order,
+// which uses the syntax accepted by FindAll.
+func (r *StockMemoryRepository) FindAllAsOf(ctx context.Context, at time.Time, order string, page, limit int) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	terms, err := parseOrder(order, classificationPoints(r.weights))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectAt(at, nil)
+	r.mu.RUnlock()
+
+	sortStocks(stocks, terms...)
+	return paginate(stocks, domain.PaginationParams{Page: page, PageSize: limit}), nil
+}
+
+// FindLatestByTickerAsOf retrieves the most recent event of a ticker stored by at.
+// It returns domain.ErrStockNotFound if the ticker had no events then.
+func (r *StockMemoryRepository) FindLatestByTickerAsOf(ctx context.Context, at time.Time, ticker string) (*domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *domain.Stock
+	for i := range r.stocks {
+		stock, ok := r.stockAt(&r.stocks[i], at)
+		if !ok || stock.Ticker != ticker {
+			continue
+		}
+		// Later IDs win ties, as with "time DESC, id DESC"
+		if latest == nil || !stock.Time.Before(latest.Time) {
+			latest = &stock
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrStockNotFound
+	}
+	return latest, nil
+}
+
+// FindByID retrieves a stock by its ID.
+// It returns domain.ErrStockNotFound if no such stock exists.
+func (r *StockMemoryRepository) FindByID(ctx context.Context, id uint) (*domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.index(id)
+	if !ok {
+		return nil, domain.ErrStockNotFound
+	}
+	stock := r.stocks[i].Clone()
+	return &stock, nil
+}
+
+// FindByClassification retrieves all stocks that have a specific classification.
+//
+// Deprecated: FindByClassification loads every matching row without ordering or limits.
+// Use FindByClassificationPaginated instead.
+func (r *StockMemoryRepository) FindByClassification(ctx context.Context, classification string) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.selectLive(hasClassification(classification)), nil
+}
+
+// FindByClassificationPaginated retrieves one page of the stocks that have a specific
+// classification, ordered and paginated according to the provided parameters.
+func (r *StockMemoryRepository) FindByClassificationPaginated(
+	ctx context.Context,
+	classification string,
+	pagination domain.PaginationParams,
+) ([]domain.Stock, error) {
+	return r.Find(ctx, pagination, domain.Filters{
+		"classifications": {Value: classification, MatchMode: "contains"},
+	})
+}
+
+// CountByClassification returns the number of stocks that have a specific classification.
+func (r *StockMemoryRepository) CountByClassification(ctx context.Context, classification string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.countLive(hasClassification(classification)), nil
+}
+
+// Count returns the number of stocks matching the filters.
+func (r *StockMemoryRepository) Count(ctx context.Context, filters domain.Filters) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	matchers, err := compileFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.countLive(func(stock *domain.Stock) bool { return matchesAll(stock, matchers) }), nil
+}
+
+// EstimateCount returns the exact number of stocks matching the filters; counting in
+// memory is always cheap.
+func (r *StockMemoryRepository) EstimateCount(ctx context.Context, filters domain.Filters) (count int, approximate bool, err error) {
+	count, err = r.Count(ctx, filters)
+	return count, false, err
+}
+
+// FindRecentByTickers retrieves the events of the given tickers that happened at or after
+// since, ordered by ticker and time.
+func (r *StockMemoryRepository) FindRecentByTickers(ctx context.Context, tickers []string, since time.Time) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectLive(func(stock *domain.Stock) bool {
+		return slices.Contains(tickers, stock.Ticker) && !stock.Time.Before(since)
+	})
+	r.mu.RUnlock()
+
+	sortStocks(stocks, orderTerm{field: "ticker"}, orderTerm{field: "time"})
+	return stocks, nil
+}
+
+// Sample retrieves up to n stocks chosen at random.
+func (r *StockMemoryRepository) Sample(ctx context.Context, n int) ([]domain.Stock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	stocks := r.selectLive(nil)
+	r.mu.RUnlock()
+
+	rand.Shuffle(len(stocks), func(i, j int) { stocks[i], stocks[j] = stocks[j], stocks[i] })
+	if n < len(stocks) {
+		stocks = stocks[:n]
+	}
+	return stocks, nil
+}
+
+// PurgeCache does nothing: the memory backend computes every result on demand, so there
+// is no cache to drop.
+func (r *StockMemoryRepository) PurgeCache() {}
+
+// publishWrite notifies the event bus about a successful write.
+func (r *StockMemoryRepository) publishWrite(operation domain.WriteOperation, stocks ...*domain.Stock) {
+	publishStockWrite(r.bus, operation, stocks...)
+}
+
+// index returns the position of the live stock with the given ID.
+// The caller must hold the lock.
+func (r *StockMemoryRepository) index(id uint) (int, bool) {
+	i, ok := slices.BinarySearchFunc(r.stocks, id, func(stock domain.Stock, id uint) int {
+		return cmp.Compare(stock.ID, id)
+	})
+	if !ok || r.stocks[i].DeletedAt.Valid {
+		return 0, false
+	}
+	return i, true
+}
+
+// selectLive returns copies of the stocks that are not deleted and satisfy keep.
+// A nil keep selects every live stock. The caller must hold the lock.
+func (r *StockMemoryRepository) selectLive(keep func(*domain.Stock) bool) []domain.Stock {
+	stocks := []domain.Stock{}
+	for i := range r.stocks {
+		stock := &r.stocks[i]
+		if stock.DeletedAt.Valid || (keep != nil && !keep(stock)) {
+			continue
+		}
+		stocks = append(stocks, stock.Clone())
+	}
+	return stocks
+}
+
+// selectAt returns copies of the stocks that were stored and not deleted at at, with the
+// values they had then, that satisfy keep. A nil keep selects every such stock.
+// The caller must hold the lock.
+func (r *StockMemoryRepository) selectAt(at time.Time, keep func(*domain.Stock) bool) []domain.Stock {
+	stocks := []domain.Stock{}
+	for i := range r.stocks {
+		stock, ok := r.stockAt(&r.stocks[i], at)
+		if !ok || (keep != nil && !keep(&stock)) {
+			continue
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks
+}
+
+// stockAt returns a copy of stock with the values it had at at: those of its first
+// revision after at, or its current values if it was not updated since. It reports false
+// if the stock was not stored or already deleted then. The caller must hold the lock.
+func (r *StockMemoryRepository) stockAt(stock *domain.Stock, at time.Time) (domain.Stock, bool) {
+	if !stock.VisibleAt(at) {
+		return domain.Stock{}, false
+	}
+	for _, revision := range r.revisions[stock.ID] {
+		if revision.revisedAt.After(at) {
+			past := revision.stock.Clone()
+			past.DeletedAt = stock.DeletedAt
+			return past, true
+		}
+	}
+	return stock.Clone(), true
+}
+
+// revise keeps the values of the stock at position i as a revision, before it is updated
+// at revisedAt. The caller must hold the write lock.
+func (r *StockMemoryRepository) revise(i int, revisedAt time.Time) {
+	stock := &r.stocks[i]
+	r.revisions[stock.ID] = append(r.revisions[stock.ID], stockRevision{revisedAt: revisedAt, stock: stock.Clone()})
+}
+
+// countLive counts the stocks that are not deleted and satisfy keep.
+// The caller must hold the lock.
+func (r *StockMemoryRepository) countLive(keep func(*domain.Stock) bool) int {
+	count := 0
+	for i := range r.stocks {
+		if !r.stocks[i].DeletedAt.Valid && keep(&r.stocks[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// hasClassification selects the stocks labeled with classification.
+func hasClassification(classification string) func(*domain.Stock) bool {
+	return func(stock *domain.Stock) bool {
+		return slices.Contains(stock.Classifications, classification)
+	}
+}
+
+// paginate returns the requested page of stocks. Non-positive pages or page sizes return
+// every stock, as the SQL backend does.
+func paginate(stocks []domain.Stock, pagination domain.PaginationParams) []domain.Stock {
+	if pagination.Page <= 0 || pagination.PageSize <= 0 {
+		return stocks
+	}
+
+	start := (pagination.Page - 1) * pagination.PageSize
+	if start >= len(stocks) {
+		return []domain.Stock{}
+	}
+	end := min(start+pagination.PageSize, len(stocks))
+	return stocks[start:end]
+}
+
+// parseOrder parses an SQL-style order list such as "time DESC, id". The score is
+// computed with the given classification points.
+func parseOrder(order string, points map[string]float64) ([]orderTerm, error) {
+	var terms []orderTerm
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		desc := false
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				desc = true
+			default:
+				return nil, fmt.Errorf("invalid order %q", order)
+			}
+		} else if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid order %q", order)
+		}
+
+		term, err := compileOrder(fields[0], desc, points)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
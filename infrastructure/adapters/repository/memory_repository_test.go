@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/adapters/repository/querybuilder"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/eventbus"
+)
+
+func TestStockMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	seed := func(t *testing.T) *StockMemoryRepository {
+		repo := NewStockMemoryRepository(nil)
+		require.NoError(t, repo.SaveBatch(ctx, []*domain.Stock{
+			{Ticker: "AAPL", Company: "Apple Inc.", TargetFrom: "$100.00", TargetTo: "$150.00", RatingTo: "Buy", Classifications: domain.StringArray{"Tech", "Potential Growth"}, Time: now.Add(-2 * time.Hour)},
+			{Ticker: "AAPL", Company: "Apple Inc.", TargetFrom: "$150.00", TargetTo: "$160.00", Classifications: domain.StringArray{"Tech"}, Time: now},
+			{Ticker: "XOM", Company: "Exxon Mobil Corp.", TargetFrom: "n/a", TargetTo: "$90.00", Classifications: domain.StringArray{"Energy"}, Time: now.Add(-time.Hour)},
+			{Ticker: "MSFT", Company: "Microsoft Corporation", TargetFrom: "$300.00", TargetTo: "$330.00", Time: now.Add(-3 * time.Hour)},
+		}))
+		return repo
+	}
+
+	tickers := func(stocks []domain.Stock) []string {
+		result := make([]string, len(stocks))
+		for i := range stocks {
+			result[i] = stocks[i].Ticker
+		}
+		return result
+	}
+
+	t.Run("should assign IDs and apply the model defaults on save", func(t *testing.T) {
+		repo := seed(t)
+
+		msft, err := repo.FindByID(ctx, 4)
+		require.NoError(t, err)
+		assert.Equal(t, "MSFT", msft.Ticker)
+		assert.Equal(t, domain.StringArray{domain.NeutralLabel}, msft.Classifications)
+		assert.Equal(t, "microsoft", msft.CompanyNormalized)
+		assert.False(t, msft.CreatedAt.IsZero())
+	})
+
+	t.Run("should filter with every match mode the SQL backend supports", func(t *testing.T) {
+		repo := seed(t)
+
+		cases := []struct {
+			name     string
+			filters  domain.Filters
+			expected []string
+		}{
+			{"equals", domain.Filters{"ticker": {Value: "XOM", MatchMode: "equals"}}, []string{"XOM"}},
+			{"normalized company search", domain.Filters{"company": {Value: "EXXON MOBIL", MatchMode: "startsWith"}}, []string{"XOM"}},
+			{"exact company", domain.Filters{"company": {Value: "Apple", MatchMode: "equals"}}, []string{}},
+			{"time range", domain.Filters{"time": {Value: now.Add(-90 * time.Minute).Format(time.RFC3339), MatchMode: "greaterThan"}}, []string{"AAPL", "XOM"}},
+			{"numeric id", domain.Filters{"id": {Value: float64(2), MatchMode: "lessThan"}}, []string{"AAPL"}},
+			{"has label", domain.Filters{"classifications": {Value: "Tech", MatchMode: "contains"}}, []string{"AAPL", "AAPL"}},
+			{"all labels", domain.Filters{"classifications": {Value: []interface{}{"Tech", "Potential Growth"}, MatchMode: "containsAll"}}, []string{"AAPL"}},
+			{"any label", domain.Filters{"classifications": {Value: []interface{}{"Energy", "Potential Growth"}, MatchMode: "containsAny"}}, []string{"AAPL", "XOM"}},
+			{"no label", domain.Filters{"classifications": {MatchMode: "hasNone"}}, []string{"MSFT"}},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				stocks, err := repo.Find(ctx, domain.PaginationParams{}, tc.filters)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, tickers(stocks))
+
+				count, err := repo.Count(ctx, tc.filters)
+				require.NoError(t, err)
+				assert.Equal(t, len(tc.expected), count)
+			})
+		}
+	})
+
+	t.Run("should reject the filters and sort fields the SQL backend rejects", func(t *testing.T) {
+		repo := seed(t)
+
+		_, err := repo.Find(ctx, domain.PaginationParams{}, domain.Filters{"unknown": {Value: "x", MatchMode: "equals"}})
+		assert.ErrorIs(t, err, querybuilder.ErrUnknownField)
+
+		_, err = repo.Find(ctx, domain.PaginationParams{}, domain.Filters{"score": {Value: 1, MatchMode: "equals"}})
+		assert.ErrorIs(t, err, querybuilder.ErrUnsupportedMatchMode)
+
+		_, err = repo.Find(ctx, domain.PaginationParams{}, domain.Filters{"time": {Value: "yesterday", MatchMode: "lessThan"}})
+		assert.ErrorIs(t, err, querybuilder.ErrInvalidFilterValue)
+
+		_, err = repo.Find(ctx, domain.PaginationParams{SortField: "classifications", SortOrder: 1}, nil)
+		assert.ErrorIs(t, err, querybuilder.ErrNotSortable)
+	})
+
+	t.Run("should sort computed fields with nulls last and paginate", func(t *testing.T) {
+		repo := seed(t)
+
+		byUpside, err := repo.Find(ctx, domain.PaginationParams{SortField: "upside", SortOrder: -1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "MSFT", "AAPL", "XOM"}, tickers(byUpside))
+
+		byUpside, err = repo.Find(ctx, domain.PaginationParams{SortField: "upside", SortOrder: 1}, nil)
+		require.NoError(t, err)
+		ids := make([]uint, len(byUpside))
+		for i := range byUpside {
+			ids[i] = byUpside[i].ID
+		}
+		assert.Equal(t, []uint{2, 4, 1, 3}, ids)
+
+		page, err := repo.Find(ctx, domain.PaginationParams{Page: 2, PageSize: 2, SortField: "score", SortOrder: -1}, nil)
+		require.NoError(t, err)
+		assert.Len(t, page, 2)
+
+		all, err := repo.FindAll(ctx, "time DESC", 1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "XOM", "AAPL"}, tickers(all))
+
+		_, err = repo.FindAll(ctx, "time; DROP TABLE stocks", 1, 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("should sort by the score under the weights in effect", func(t *testing.T) {
+		repo := seed(t).WithScoringWeights(fixedWeights{Points: map[string]float64{"Energy": 1000}})
+
+		byScore, err := repo.Find(ctx, domain.PaginationParams{SortField: "score", SortOrder: -1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "XOM", byScore[0].Ticker)
+
+		byScore, err = repo.FindAll(ctx, "score DESC", 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "XOM", byScore[0].Ticker)
+	})
+
+	t.Run("should find the latest event of a ticker", func(t *testing.T) {
+		repo := seed(t)
+
+		latest, err := repo.FindLatestByTicker(ctx, "AAPL")
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), latest.ID)
+
+		_, err = repo.FindLatestByTicker(ctx, "TSLA")
+		assert.ErrorIs(t, err, domain.ErrStockNotFound)
+	})
+
+	t.Run("should update only the given columns", func(t *testing.T) {
+		repo := seed(t)
+
+		patch := domain.Stock{Company: "Exxon Mobil Corporation", RatingTo: "Sell"}
+		patch.ID = 3
+		require.NoError(t, repo.Update(ctx, &patch, []string{"company"}))
+
+		stored, err := repo.FindByID(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "Exxon Mobil Corporation", stored.Company)
+		assert.Equal(t, "exxon mobil", stored.CompanyNormalized)
+		assert.Equal(t, "", stored.RatingTo)
+		assert.Equal(t, "XOM", stored.Ticker)
+
+		assert.ErrorIs(t, repo.Update(ctx, &patch, []string{"deleted_at"}), ErrUnknownColumn)
+
+		patch.ID = 42
+		assert.ErrorIs(t, repo.Update(ctx, &patch, []string{"company"}), domain.ErrStockNotFound)
+	})
+
+	t.Run("should soft-delete stocks", func(t *testing.T) {
+		repo := seed(t)
+
+		require.NoError(t, repo.Delete(ctx, &domain.Stock{}, 3))
+
+		_, err := repo.FindByID(ctx, 3)
+		assert.ErrorIs(t, err, domain.ErrStockNotFound)
+		count, err := repo.Count(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("should not expose stored stocks to mutations by callers", func(t *testing.T) {
+		repo := seed(t)
+
+		stocks, err := repo.Find(ctx, domain.PaginationParams{}, nil)
+		require.NoError(t, err)
+		stocks[0].Classifications[0] = "Mutated"
+
+		stored, err := repo.FindByID(ctx, stocks[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Tech", stored.Classifications[0])
+	})
+
+	t.Run("should filter by warnings", func(t *testing.T) {
+		repo := seed(t)
+		require.NoError(t, repo.Create(ctx, &domain.Stock{Ticker: "ODD", Warnings: domain.StringArray{domain.WarningUnknownRating}, Time: now}))
+
+		pagination := domain.PaginationParams{Page: 1, PageSize: 10, SortField: "id", SortOrder: 1}
+		flagged, err := repo.Find(ctx, pagination, domain.Filters{"warnings": {Value: domain.WarningUnknownRating, MatchMode: "contains"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ODD"}, tickers(flagged))
+
+		clean, err := repo.Count(ctx, domain.Filters{"warnings": {MatchMode: "hasNone"}})
+		require.NoError(t, err)
+		assert.Equal(t, 4, clean)
+	})
+
+	t.Run("should read the stocks as they stood at a past time", func(t *testing.T) {
+		stocks := []*domain.Stock{
+			{Ticker: "AAPL", Time: now.Add(-2 * time.Hour)},
+			{Ticker: "AAPL", Time: now},
+			{Ticker: "MSFT", Time: now.Add(-3 * time.Hour)},
+		}
+		for _, stock := range stocks {
+			stock.CreatedAt = stock.Time
+		}
+		repo := NewStockMemoryRepository(nil)
+		require.NoError(t, repo.SaveBatch(ctx, stocks))
+		require.NoError(t, repo.Delete(ctx, &domain.Stock{}, stocks[2].ID))
+
+		// MSFT was deleted after the past time, and the latest AAPL event stored after it
+		past := now.Add(-time.Hour)
+		pagination := domain.PaginationParams{Page: 1, PageSize: 10, SortField: "time", SortOrder: -1}
+		found, err := repo.FindAsOf(ctx, past, pagination, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "MSFT"}, tickers(found))
+		assert.Equal(t, now.Add(-2*time.Hour), found[0].Time)
+
+		count, err := repo.CountAsOf(ctx, past, domain.Filters{"ticker": {Value: "MSFT", MatchMode: "equals"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		all, err := repo.FindAllAsOf(ctx, past, "time ASC", 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"MSFT"}, tickers(all))
+
+		latest, err := repo.FindLatestByTickerAsOf(ctx, past, "AAPL")
+		require.NoError(t, err)
+		assert.Equal(t, stocks[0].ID, latest.ID)
+
+		_, err = repo.FindLatestByTickerAsOf(ctx, now.Add(-4*time.Hour), "AAPL")
+		assert.ErrorIs(t, err, domain.ErrStockNotFound)
+
+		// Once deleted, MSFT is no longer read
+		count, err = repo.CountAsOf(ctx, time.Now().Add(time.Hour), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("should read the values stocks had at a past time", func(t *testing.T) {
+		repo := NewStockMemoryRepository(nil)
+		stock := &domain.Stock{Ticker: "AAPL", RatingTo: "Hold", Classifications: domain.StringArray{"Tech"}, Time: now}
+		require.NoError(t, repo.Create(ctx, stock))
+		created := time.Now()
+
+		edited := stock.Clone()
+		edited.RatingTo = "Buy"
+		require.NoError(t, repo.Update(ctx, &edited, []string{"rating_to"}))
+		updated := time.Now()
+
+		reclassified := edited.Clone()
+		reclassified.Classifications = domain.StringArray{"Tech", "Bullish Signal"}
+		require.NoError(t, repo.UpdateClassifications(ctx, []*domain.Stock{&reclassified}))
+
+		// Filters match the values of then
+		pagination := domain.PaginationParams{Page: 1, PageSize: 10}
+		found, err := repo.FindAsOf(ctx, created, pagination, domain.Filters{"rating_to": {Value: "Hold", MatchMode: "equals"}})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, stock.ID, found[0].ID)
+		assert.Equal(t, domain.StringArray{"Tech"}, found[0].Classifications)
+		assert.Zero(t, domain.RevisedSince(created, found...))
+
+		count, err := repo.CountAsOf(ctx, created, domain.Filters{"rating_to": {Value: "Buy", MatchMode: "equals"}})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		latest, err := repo.FindLatestByTickerAsOf(ctx, updated, "AAPL")
+		require.NoError(t, err)
+		assert.Equal(t, "Buy", latest.RatingTo)
+		assert.Equal(t, domain.StringArray{"Tech"}, latest.Classifications)
+
+		current, err := repo.FindAllAsOf(ctx, time.Now(), "id", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, current, 1)
+		assert.Equal(t, domain.StringArray{"Tech", "Bullish Signal"}, current[0].Classifications)
+	})
+
+	t.Run("should update classifications after an ID", func(t *testing.T) {
+		repo := seed(t)
+
+		after := domain.Filters{"id": {Value: 2, MatchMode: "greaterThan"}}
+		stocks, err := repo.Find(ctx, domain.PaginationParams{Page: 1, PageSize: 10, SortField: "id", SortOrder: 1}, after)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"XOM", "MSFT"}, tickers(stocks))
+
+		stocks[0].Classifications = domain.StringArray{"Energy", "Stable"}
+		missing := &domain.Stock{Ticker: "GONE"}
+		missing.ID = 99
+		require.NoError(t, repo.UpdateClassifications(ctx, []*domain.Stock{&stocks[0], missing}))
+
+		xom, err := repo.FindByID(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StringArray{"Energy", "Stable"}, xom.Classifications)
+		assert.Equal(t, now.Add(-time.Hour), xom.Time)
+	})
+
+	t.Run("should publish write events", func(t *testing.T) {
+		bus := eventbus.New(4)
+		events := make(chan domain.StockWriteEvent, 4)
+		eventbus.Subscribe(bus, eventbus.StockWrites, func(event domain.StockWriteEvent) { events <- event })
+
+		repo := NewStockMemoryRepository(bus)
+		require.NoError(t, repo.Create(ctx, &domain.Stock{Ticker: "AAPL", Time: now}))
+		require.NoError(t, bus.Close(ctx))
+
+		event := <-events
+		assert.Equal(t, domain.WriteCreate, event.Operation)
+		assert.Equal(t, uint(1), event.Stocks[0].ID)
+	})
+}
+
+// fixedWeights supplies the same scoring weights at all times.
+type fixedWeights domain.ScoringWeights
+
+func (w fixedWeights) CurrentScoringWeights() domain.ScoringWeights {
+	return domain.ScoringWeights(w)
+}
+
+func TestMemoryStores(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should list audit entries newest first", func(t *testing.T) {
+		repo := NewAuditMemoryRepository()
+		for _, action := range []string{"ingest", "purge", "update"} {
+			require.NoError(t, repo.Record(ctx, &domain.AuditEntry{Action: action}))
+		}
+
+		page, err := repo.List(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, "update", page[0].Action)
+		assert.Equal(t, uint(3), page[0].ID)
+
+		page, err = repo.List(ctx, 2, 2)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, "ingest", page[0].Action)
+	})
+
+	t.Run("should list score history within the range, oldest first", func(t *testing.T) {
+		repo := NewScoreHistoryMemoryRepository()
+		require.NoError(t, repo.SaveBatch(ctx, []*domain.ScoreHistoryEntry{
+			{Ticker: "AAPL", Score: 3, ComputedAt: now},
+			{Ticker: "AAPL", Score: 1, ComputedAt: now.Add(-2 * time.Hour)},
+			{Ticker: "XOM", Score: 5, ComputedAt: now},
+			{Ticker: "AAPL", Score: 2, ComputedAt: now.Add(-time.Hour)},
+		}))
+
+		entries, err := repo.ListByTicker(ctx, "AAPL", now.Add(-90*time.Minute), time.Time{}, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, 2.0, entries[0].Score)
+		assert.Equal(t, 3.0, entries[1].Score)
+
+		entries, err = repo.ListByTicker(ctx, "AAPL", time.Time{}, time.Time{}, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "the limit should keep the latest entries")
+		assert.Equal(t, 2.0, entries[0].Score)
+		assert.Equal(t, 3.0, entries[1].Score)
+	})
+}
+
+func TestScoringWeightMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewScoringWeightMemoryRepository()
+
+	initial, err := repo.ListChanges(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultScoringWeights().Points, domain.ReplayScoringWeights(initial, 0).Points)
+	assert.Equal(t, 1, domain.ReplayScoringWeights(initial, 0).Version)
+
+	version, err := repo.Append(ctx, []*domain.ScoringWeight{{Label: "Tech", Points: 12}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	changes, err := repo.ListChanges(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, initial, changes)
+
+	changes, err = repo.ListChanges(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, domain.ReplayScoringWeights(changes, 0).Points["Tech"])
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// AuditMemoryRepository keeps audit log entries in process memory, for runs without a
+// database such as sandbox mode.
+type AuditMemoryRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry // Oldest first
+	nextID  uint
+}
+
+// NewAuditMemoryRepository creates a new, empty instance of AuditMemoryRepository.
+func NewAuditMemoryRepository() *AuditMemoryRepository {
+	return &AuditMemoryRepository{nextID: 1}
+}
+
+// Record stores a new audit entry, assigning its ID.
+func (r *AuditMemoryRepository) Record(_ context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = r.nextID
+	r.nextID++
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// List retrieves one page of audit entries, newest first.
+func (r *AuditMemoryRepository) List(_ context.Context, page, pageSize int) ([]domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for i := len(r.entries) - 1 - (page-1)*pageSize; i >= 0 && len(entries) < pageSize; i-- {
+		entries = append(entries, r.entries[i])
+	}
+	return entries, nil
+}
+
+// ScoreHistoryMemoryRepository keeps score history entries in process memory, for runs
+// without a database such as sandbox mode.
+type ScoreHistoryMemoryRepository struct {
+	mu       sync.RWMutex
+	byTicker map[string][]domain.ScoreHistoryEntry // In insertion order
+	nextID   uint
+}
+
+// NewScoreHistoryMemoryRepository creates a new, empty instance of
+// ScoreHistoryMemoryRepository.
+func NewScoreHistoryMemoryRepository() *ScoreHistoryMemoryRepository {
+	return &ScoreHistoryMemoryRepository{byTicker: make(map[string][]domain.ScoreHistoryEntry), nextID: 1}
+}
+
+// SaveBatch stores multiple score history entries.
+func (r *ScoreHistoryMemoryRepository) SaveBatch(_ context.Context, entries []*domain.ScoreHistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.ID = r.nextID
+		r.nextID++
+		r.byTicker[entry.Ticker] = append(r.byTicker[entry.Ticker], *entry)
+	}
+	return nil
+}
+
+// ListByTicker returns the latest limit entries of a ticker computed within [from, to],
+// oldest first. A zero from or to leaves that end of the range open.
+func (r *ScoreHistoryMemoryRepository) ListByTicker(_ context.Context, ticker string, from, to time.Time, limit int) ([]domain.ScoreHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []domain.ScoreHistoryEntry{}
+	for _, entry := range r.byTicker[ticker] {
+		if (!from.IsZero() && entry.ComputedAt.Before(from)) || (!to.IsZero() && entry.ComputedAt.After(to)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	// Runs record entries in order, but keep the contract of the SQL backend
+	slices.SortStableFunc(entries, func(a, b domain.ScoreHistoryEntry) int {
+		return cmp.Or(a.ComputedAt.Compare(b.ComputedAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// ScoringWeightMemoryRepository keeps the changes to the scoring weights in process
+// memory, for runs without a database such as sandbox mode. Like the migration of the SQL
+// table, it starts with the built-in weights as version 1.
+type ScoringWeightMemoryRepository struct {
+	mu      sync.RWMutex
+	changes []domain.ScoringWeight // In the order they were made
+	nextID  uint
+}
+
+// NewScoringWeightMemoryRepository creates a new instance of ScoringWeightMemoryRepository
+// holding the built-in weights.
+func NewScoringWeightMemoryRepository() *ScoringWeightMemoryRepository {
+	r := &ScoringWeightMemoryRepository{nextID: 1}
+
+	defaults := domain.DefaultScoringWeights().Points
+	labels := make([]string, 0, len(defaults))
+	for label := range defaults {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	changes := make([]*domain.ScoringWeight, len(labels))
+	for i, label := range labels {
+		changes[i] = &domain.ScoringWeight{Label: label, Points: defaults[label], Actor: "system", CreatedAt: time.Now().UTC()}
+	}
+	_, _ = r.Append(context.Background(), changes)
+	return r
+}
+
+// ListChanges returns the changes up to version, in the order they were made. A zero
+// version returns every change.
+func (r *ScoringWeightMemoryRepository) ListChanges(_ context.Context, version int) ([]domain.ScoringWeight, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []domain.ScoringWeight{}
+	for _, change := range r.changes {
+		if version > 0 && change.Version > version {
+			break
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Append stores changes as the version following the latest one and returns it.
+func (r *ScoringWeightMemoryRepository) Append(_ context.Context, changes []*domain.ScoringWeight) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version := 1
+	if len(r.changes) > 0 {
+		version = r.changes[len(r.changes)-1].Version + 1
+	}
+	for _, change := range changes {
+		change.ID = r.nextID
+		change.Version = version
+		r.nextID++
+		r.changes = append(r.changes, *change)
+	}
+	return version, nil
+}
+
+// MemorySchemaInspector is the SchemaInspector of runs without a database: the store is
+// always reachable and has no migrations.
+type MemorySchemaInspector struct{}
+
+// Ping always succeeds.
+func (MemorySchemaInspector) Ping(_ context.Context) error {
+	return nil
+}
+
+// SchemaVersion reports that no migration was applied.
+func (MemorySchemaInspector) SchemaVersion(_ context.Context) (domain.SchemaVersion, error) {
+	return domain.SchemaVersion{}, nil
+}
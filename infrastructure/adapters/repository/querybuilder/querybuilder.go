@@ -0,0 +1,277 @@
+// Package querybuilder turns API filters and sort parameters into parameterized SQL
+// fragments. Every queryable field is described by a Column, which declares the SQL
+// expression behind the field, its type, and the match modes it supports, so no user
+// input is ever formatted into the SQL text.
+//
+// The builder does not depend on a database connection; its output is meant to be
+// passed to gorm's Where/Order methods.
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// ColumnType describes the kind of value stored in a column.
+type ColumnType int
+
+const (
+	TypeString ColumnType = iota
+	TypeNumber
+	TypeTime
+	TypeStringArray
+)
+
+// MatchMode is the comparison applied by a filter.
+type MatchMode string
+
+// Scalar match modes.
+const (
+	Equals      MatchMode = "equals"
+	Contains    MatchMode = "contains"
+	StartsWith  MatchMode = "startsWith"
+	EndsWith    MatchMode = "endsWith"
+	GreaterThan MatchMode = "greaterThan"
+	LessThan    MatchMode = "lessThan"
+)
+
+// Array match modes. Contains is shared with scalars and means "has the label" on arrays.
+// HasNone ignores the filter value and matches arrays that are NULL, empty, or hold only
+// the column's placeholder labels.
+const (
+	ContainsAll MatchMode = "containsAll"
+	ContainsAny MatchMode = "containsAny"
+	HasNone     MatchMode = "hasNone"
+)
+
+// Errors returned by the builder. Every error returned by Where also wraps
+// domain.ErrInvalidFilter, so callers can reject the filter as a client error.
+var (
+	ErrUnknownField         = errors.New("unknown field")
+	ErrUnsupportedMatchMode = fmt.Errorf("%w: unsupported match mode", domain.ErrInvalidFilter)
+	ErrNotSortable          = errors.New("field is not sortable")
+	ErrInvalidFilterValue   = fmt.Errorf("%w: invalid value", domain.ErrInvalidFilter)
+)
+
+// defaultMatchModes lists the match modes allowed by default for each column type.
+var defaultMatchModes = map[ColumnType][]MatchMode{
+	TypeString:      {Equals, Contains, StartsWith, EndsWith, GreaterThan, LessThan},
+	TypeNumber:      {Equals, GreaterThan, LessThan},
+	TypeTime:        {Equals, GreaterThan, LessThan},
+	TypeStringArray: {Contains, ContainsAll, ContainsAny, HasNone},
+}
+
+// DefaultMatchModes returns the match modes allowed by default on columns of type t.
+func DefaultMatchModes(t ColumnType) []MatchMode {
+	return append([]MatchMode(nil), defaultMatchModes[t]...)
+}
+
+// Column describes a queryable field.
+//
+// Fields:
+//   - Name: The public field name used by API clients (e.g., "target_from").
+//   - Expr: The SQL expression behind the field. Defaults to Name.
+//   - Type: The kind of value the expression produces.
+//   - MatchModes: The filter match modes allowed on the field. Nil means the defaults
+//     for Type; an empty, non-nil slice makes the field sort-only.
+//   - Computed: True for virtual fields computed from other columns. Computed values may
+//     be NULL, so they sort with NULLS LAST.
+//   - SearchExpr: Optional. When set, Contains, StartsWith, and EndsWith match the value,
+//     passed through Normalize, against SearchExpr instead of Expr. It lets searches
+//     target a normalized copy of the column while Equals stays exact.
+//   - Normalize: The normalization applied to search values. Required with SearchExpr.
+//   - Placeholders: For array columns, the labels that stand for "no label" (e.g., a
+//     default written when nothing else applies). HasNone treats them as absent.
+type Column struct {
+	Name         string
+	Expr         string
+	Type         ColumnType
+	MatchModes   []MatchMode
+	Computed     bool
+	SearchExpr   string
+	Normalize    func(string) string
+	Placeholders []string
+}
+
+// Clause is a parameterized SQL fragment, with "?" placeholders bound to Args.
+type Clause struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Builder builds clauses for a fixed set of columns.
+type Builder struct {
+	columns map[string]Column
+	order   []string
+}
+
+// New creates a Builder for the given columns.
+// Field lookups ignore case and underscores, so "target_from" and "TargetFrom" refer to
+// the same column.
+func New(columns ...Column) *Builder {
+	b := &Builder{columns: make(map[string]Column, len(columns))}
+	for _, column := range columns {
+		if column.Expr == "" {
+			column.Expr = column.Name
+		}
+		if column.MatchModes == nil {
+			column.MatchModes = defaultMatchModes[column.Type]
+		}
+		b.columns[normalize(column.Name)] = column
+		b.order = append(b.order, column.Name)
+	}
+	return b
+}
+
+// Column returns the column registered under field.
+func (b *Builder) Column(field string) (Column, bool) {
+	column, ok := b.columns[normalize(field)]
+	return column, ok
+}
+
+// Columns returns every registered column in registration order.
+func (b *Builder) Columns() []Column {
+	columns := make([]Column, 0, len(b.order))
+	for _, name := range b.order {
+		columns = append(columns, b.columns[normalize(name)])
+	}
+	return columns
+}
+
+// WithExpr returns a copy of the builder in which the column registered under field
+// evaluates expr, for expressions depending on settings that change at runtime.
+// The builder is left unchanged, and so is the copy when field is unknown.
+func (b *Builder) WithExpr(field, expr string) *Builder {
+	copied := &Builder{columns: maps.Clone(b.columns), order: b.order}
+	if column, ok := copied.Column(field); ok {
+		column.Expr = expr
+		copied.columns[normalize(field)] = column
+	}
+	return copied
+}
+
+// Where builds the condition for filtering field with the given filter.
+func (b *Builder) Where(field string, filter domain.Filter) (Clause, error) {
+	column, ok := b.Column(field)
+	if !ok {
+		return Clause{}, fmt.Errorf("%w: %w: %s", domain.ErrInvalidFilter, ErrUnknownField, field)
+	}
+
+	mode := MatchMode(filter.MatchMode)
+	if !column.allows(mode) {
+		return Clause{}, fmt.Errorf("%w: %q on %s", ErrUnsupportedMatchMode, filter.MatchMode, column.Name)
+	}
+
+	if column.Type == TypeStringArray {
+		return arrayClause(column, mode, filter.Value)
+	}
+	return scalarClause(column, mode, filter.Value), nil
+}
+
+// OrderBy builds the ORDER BY term for field. Descending order is used when desc is true.
+func (b *Builder) OrderBy(field string, desc bool) (string, error) {
+	column, ok := b.Column(field)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+	if column.Type == TypeStringArray {
+		return "", fmt.Errorf("%w: %s", ErrNotSortable, column.Name)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	if column.Computed {
+		return fmt.Sprintf("%s %s NULLS LAST", column.Expr, direction), nil
+	}
+	return fmt.Sprintf("%s %s", column.Expr, direction), nil
+}
+
+// allows reports whether mode is one of the column's match modes.
+func (c Column) allows(mode MatchMode) bool {
+	for _, m := range c.MatchModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func scalarClause(column Column, mode MatchMode, value interface{}) Clause {
+	if column.SearchExpr != "" && (mode == Contains || mode == StartsWith || mode == EndsWith) {
+		column.Expr = column.SearchExpr
+		value = column.Normalize(fmt.Sprint(value))
+	}
+
+	switch mode {
+	case Contains:
+		return Clause{SQL: column.Expr + " LIKE ?", Args: []interface{}{fmt.Sprintf("%%%v%%", value)}}
+	case StartsWith:
+		return Clause{SQL: column.Expr + " LIKE ?", Args: []interface{}{fmt.Sprintf("%v%%", value)}}
+	case EndsWith:
+		return Clause{SQL: column.Expr + " LIKE ?", Args: []interface{}{fmt.Sprintf("%%%v", value)}}
+	case GreaterThan:
+		return Clause{SQL: column.Expr + " > ?", Args: []interface{}{value}}
+	case LessThan:
+		return Clause{SQL: column.Expr + " < ?", Args: []interface{}{value}}
+	default: // Equals
+		return Clause{SQL: column.Expr + " = ?", Args: []interface{}{value}}
+	}
+}
+
+func arrayClause(column Column, mode MatchMode, value interface{}) (Clause, error) {
+	if mode == HasNone {
+		// An array contained by the placeholders holds no other label; the empty array
+		// is contained by any array
+		placeholders := pq.StringArray(append([]string{}, column.Placeholders...))
+		return Clause{SQL: fmt.Sprintf("(%[1]s IS NULL OR %[1]s <@ ?)", column.Expr), Args: []interface{}{placeholders}}, nil
+	}
+
+	labels, err := toStringArray(value)
+	if err != nil {
+		return Clause{}, fmt.Errorf("%w for %s: %v", ErrInvalidFilterValue, column.Name, err)
+	}
+
+	switch mode {
+	case ContainsAny:
+		return Clause{SQL: column.Expr + " && ?", Args: []interface{}{labels}}, nil
+	default: // Contains, ContainsAll
+		return Clause{SQL: column.Expr + " @> ?", Args: []interface{}{labels}}, nil
+	}
+}
+
+// toStringArray converts a filter value (a string or a JSON array of strings) to a
+// PostgreSQL text array.
+func toStringArray(value interface{}) (pq.StringArray, error) {
+	switch v := value.(type) {
+	case string:
+		return pq.StringArray{v}, nil
+	case []string:
+		return pq.StringArray(v), nil
+	case []interface{}:
+		labels := make(pq.StringArray, 0, len(v))
+		for _, item := range v {
+			label, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", item)
+			}
+			labels = append(labels, label)
+		}
+		return labels, nil
+	default:
+		return nil, fmt.Errorf("expected string or array of strings, got %T", value)
+	}
+}
+
+// normalize folds case and removes underscores from a field name.
+func normalize(field string) string {
+	return strings.ToLower(strings.ReplaceAll(field, "_", ""))
+}
@@ -0,0 +1,139 @@
+package querybuilder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func newTestBuilder() *Builder {
+	return New(
+		Column{Name: "ticker", Type: TypeString},
+		Column{Name: "target_from", Type: TypeString},
+		Column{Name: "company", Type: TypeString, SearchExpr: "company_normalized", Normalize: strings.ToLower},
+		Column{Name: "time", Type: TypeTime},
+		Column{Name: "classifications", Type: TypeStringArray, Placeholders: []string{"Neutral"}},
+		Column{Name: "tags", Type: TypeStringArray},
+		Column{Name: "score", Expr: "(a + b)", Type: TypeNumber, MatchModes: []MatchMode{}, Computed: true},
+	)
+}
+
+func TestBuilder_Where(t *testing.T) {
+	b := newTestBuilder()
+
+	tests := []struct {
+		name     string
+		field    string
+		filter   domain.Filter
+		expected Clause
+	}{
+		{"equals", "ticker", domain.Filter{Value: "AAPL", MatchMode: "equals"}, Clause{"ticker = ?", []interface{}{"AAPL"}}},
+		{"contains", "ticker", domain.Filter{Value: "AP", MatchMode: "contains"}, Clause{"ticker LIKE ?", []interface{}{"%AP%"}}},
+		{"startsWith", "ticker", domain.Filter{Value: "AA", MatchMode: "startsWith"}, Clause{"ticker LIKE ?", []interface{}{"AA%"}}},
+		{"endsWith", "ticker", domain.Filter{Value: "PL", MatchMode: "endsWith"}, Clause{"ticker LIKE ?", []interface{}{"%PL"}}},
+		{"greaterThan", "time", domain.Filter{Value: "2025-01-01", MatchMode: "greaterThan"}, Clause{"time > ?", []interface{}{"2025-01-01"}}},
+		{"lessThan", "time", domain.Filter{Value: "2025-01-01", MatchMode: "lessThan"}, Clause{"time < ?", []interface{}{"2025-01-01"}}},
+		{"search column contains", "company", domain.Filter{Value: "Acme", MatchMode: "contains"}, Clause{"company_normalized LIKE ?", []interface{}{"%acme%"}}},
+		{"search column equals", "company", domain.Filter{Value: "Acme", MatchMode: "equals"}, Clause{"company = ?", []interface{}{"Acme"}}},
+		{"field aliases", "TargetFrom", domain.Filter{Value: "$1", MatchMode: "equals"}, Clause{"target_from = ?", []interface{}{"$1"}}},
+		{
+			"array contains", "classifications", domain.Filter{Value: "Tech", MatchMode: "contains"},
+			Clause{"classifications @> ?", []interface{}{pq.StringArray{"Tech"}}},
+		},
+		{
+			"array containsAll", "classifications", domain.Filter{Value: []interface{}{"Tech", "Bullish Signal"}, MatchMode: "containsAll"},
+			Clause{"classifications @> ?", []interface{}{pq.StringArray{"Tech", "Bullish Signal"}}},
+		},
+		{
+			"array containsAny", "classifications", domain.Filter{Value: []interface{}{"Tech", "Biotech"}, MatchMode: "containsAny"},
+			Clause{"classifications && ?", []interface{}{pq.StringArray{"Tech", "Biotech"}}},
+		},
+		{
+			"array hasNone", "classifications", domain.Filter{MatchMode: "hasNone"},
+			Clause{"(classifications IS NULL OR classifications <@ ?)", []interface{}{pq.StringArray{"Neutral"}}},
+		},
+		{
+			"array hasNone without placeholders", "tags", domain.Filter{Value: true, MatchMode: "hasNone"},
+			Clause{"(tags IS NULL OR tags <@ ?)", []interface{}{pq.StringArray{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, err := b.Where(tt.field, tt.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, clause)
+		})
+	}
+}
+
+func TestBuilder_WhereErrors(t *testing.T) {
+	b := newTestBuilder()
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		_, err := b.Where("ticker; DROP TABLE stocks", domain.Filter{Value: "x", MatchMode: "equals"})
+		assert.ErrorIs(t, err, ErrUnknownField)
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+
+	t.Run("should reject match modes not allowed on the column", func(t *testing.T) {
+		_, err := b.Where("time", domain.Filter{Value: "x", MatchMode: "contains"})
+		assert.ErrorIs(t, err, ErrUnsupportedMatchMode)
+
+		_, err = b.Where("ticker", domain.Filter{Value: "x", MatchMode: "regex"})
+		assert.ErrorIs(t, err, ErrUnsupportedMatchMode)
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+
+	t.Run("should reject filters on sort-only columns", func(t *testing.T) {
+		_, err := b.Where("score", domain.Filter{Value: 10, MatchMode: "greaterThan"})
+		assert.ErrorIs(t, err, ErrUnsupportedMatchMode)
+	})
+
+	t.Run("should reject non-string array values", func(t *testing.T) {
+		_, err := b.Where("classifications", domain.Filter{Value: []interface{}{1.0}, MatchMode: "containsAny"})
+		assert.ErrorIs(t, err, ErrInvalidFilterValue)
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+}
+
+func TestBuilder_OrderBy(t *testing.T) {
+	b := newTestBuilder()
+
+	order, err := b.OrderBy("time", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "time DESC", order)
+
+	order, err = b.OrderBy("ticker", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ticker ASC", order)
+
+	order, err = b.OrderBy("score", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a + b) DESC NULLS LAST", order)
+
+	_, err = b.OrderBy("classifications", false)
+	assert.ErrorIs(t, err, ErrNotSortable)
+
+	_, err = b.OrderBy("unknown", false)
+	assert.ErrorIs(t, err, ErrUnknownField)
+}
+
+func TestBuilder_WithExpr(t *testing.T) {
+	b := newTestBuilder()
+	weighted := b.WithExpr("Score", "(a + 2 * b)")
+
+	order, err := weighted.OrderBy("score", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a + 2 * b) ASC NULLS LAST", order)
+
+	order, _ = b.OrderBy("score", false)
+	assert.Equal(t, "(a + b) ASC NULLS LAST", order, "the original builder should be unchanged")
+
+	order, _ = weighted.WithExpr("unknown", "x").OrderBy("score", false)
+	assert.Equal(t, "(a + 2 * b) ASC NULLS LAST", order)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// schemaMigrationsTable is the table where golang-migrate records the schema version.
+const schemaMigrationsTable = "schema_migrations"
+
+// SchemaRepository inspects the database connection and the migration state.
+type SchemaRepository struct {
+	db *gorm.DB
+}
+
+// NewSchemaRepository creates a new instance of SchemaRepository.
+func NewSchemaRepository(db *gorm.DB) *SchemaRepository {
+	return &SchemaRepository{db: db}
+}
+
+// Ping verifies the database is reachable.
+func (r *SchemaRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// SchemaVersion reads the version recorded by the last migration run. A missing or empty
+// migrations table means no migration was applied.
+func (r *SchemaRepository) SchemaVersion(ctx context.Context) (domain.SchemaVersion, error) {
+	if !r.db.WithContext(ctx).Migrator().HasTable(schemaMigrationsTable) {
+		return domain.SchemaVersion{}, nil
+	}
+
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	err := r.db.WithContext(ctx).Table(schemaMigrationsTable).Select("version", "dirty").Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.SchemaVersion{}, nil
+	}
+	if err != nil {
+		return domain.SchemaVersion{}, fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	// golang-migrate records -1 when every migration was rolled back
+	if row.Version < 0 {
+		return domain.SchemaVersion{Dirty: row.Dirty}, nil
+	}
+	version := uint(row.Version)
+	return domain.SchemaVersion{Version: &version, Dirty: row.Dirty}, nil
+}
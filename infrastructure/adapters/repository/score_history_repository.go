@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// ScoreHistoryRepository stores the scores computed after each ingestion run.
+type ScoreHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewScoreHistoryRepository creates a new instance of ScoreHistoryRepository.
+func NewScoreHistoryRepository(db *gorm.DB) *ScoreHistoryRepository {
+	return &ScoreHistoryRepository{db: db}
+}
+
+// SaveBatch inserts multiple score history entries in a single batch.
+func (r *ScoreHistoryRepository) SaveBatch(ctx context.Context, entries []*domain.ScoreHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(entries, len(entries)).Error
+}
+
+// ListByTicker returns the latest limit entries of a ticker computed within [from, to],
+// oldest first. A zero from or to leaves that end of the range open.
+func (r *ScoreHistoryRepository) ListByTicker(ctx context.Context, ticker string, from, to time.Time, limit int) ([]domain.ScoreHistoryEntry, error) {
+	query := r.db.WithContext(ctx).Where("ticker = ?", ticker)
+	if !from.IsZero() {
+		query = query.Where("computed_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("computed_at <= ?", to)
+	}
+
+	// The limit keeps the latest entries, which are then returned in chronological order
+	entries := []domain.ScoreHistoryEntry{}
+	if err := query.Order("computed_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// ScoringWeightRepository stores the changes to the scoring weights. Rows are only ever
+// inserted, so the weights of every past version can be rebuilt.
+type ScoringWeightRepository struct {
+	db *gorm.DB
+}
+
+// NewScoringWeightRepository creates a new instance of ScoringWeightRepository.
+func NewScoringWeightRepository(db *gorm.DB) *ScoringWeightRepository {
+	return &ScoringWeightRepository{db: db}
+}
+
+// ListChanges returns the changes up to version, in the order they were made. A zero
+// version returns every change.
+func (r *ScoringWeightRepository) ListChanges(ctx context.Context, version int) ([]domain.ScoringWeight, error) {
+	query := r.db.WithContext(ctx)
+	if version > 0 {
+		query = query.Where("version <= ?", version)
+	}
+
+	changes := []domain.ScoringWeight{}
+	if err := query.Order("version ASC, id ASC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// appendAttempts is the number of times Append tries to claim the next version.
+const appendAttempts = 3
+
+// Append stores changes as the version following the latest one, in a single
+// transaction, and returns that version. Each version is claimed by inserting it in the
+// scoring_weight_versions table, whose primary key makes a concurrent append of the same
+// version fail; the losing append is retried with the next version instead of merging
+// its changes into the winner's.
+func (r *ScoringWeightRepository) Append(ctx context.Context, changes []*domain.ScoringWeight) (int, error) {
+	var err error
+	for range appendAttempts {
+		var version int
+		version, err = r.append(ctx, changes)
+		if err == nil {
+			return version, nil
+		}
+		if !isVersionConflict(err) {
+			return 0, err
+		}
+	}
+	return 0, fmt.Errorf("error claiming a scoring weights version after %d attempts: %w", appendAttempts, err)
+}
+
+// append makes a single attempt at storing changes as the next version.
+func (r *ScoringWeightRepository) append(ctx context.Context, changes []*domain.ScoringWeight) (int, error) {
+	var version int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT COALESCE(MAX(version), 0) FROM scoring_weight_versions").Scan(&version).Error; err != nil {
+			return err
+		}
+		version++
+		if err := tx.Exec("INSERT INTO scoring_weight_versions (version) VALUES (?)", version).Error; err != nil {
+			return err
+		}
+		for _, change := range changes {
+			change.Version = version
+		}
+		return tx.Create(changes).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// isVersionConflict reports whether err comes from a concurrent append of the same
+// version: a unique violation, or a serialization failure under serializable isolation.
+func isVersionConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "23505" || pgErr.Code == "40001"
+}
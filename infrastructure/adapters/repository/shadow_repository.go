@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// ShadowClassificationRepository stores the labels produced by shadow classifiers and
+// compares them with the active labels of the stocks table.
+type ShadowClassificationRepository struct {
+	db *gorm.DB
+}
+
+// NewShadowClassificationRepository creates a new instance of ShadowClassificationRepository.
+func NewShadowClassificationRepository(db *gorm.DB) *ShadowClassificationRepository {
+	return &ShadowClassificationRepository{db: db}
+}
+
+// SaveBatch inserts multiple shadow classifications in a single batch.
+func (r *ShadowClassificationRepository) SaveBatch(ctx context.Context, data []*domain.ShadowClassification) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(data, len(data)).Error
+}
+
+// labelCount is a row of the per-label aggregation queries.
+type labelCount struct {
+	Label string
+	Count int
+}
+
+// Report compares the labels recorded for the given shadow classifier with the current
+// labels of the same stocks.
+func (r *ShadowClassificationRepository) Report(ctx context.Context, classifier string) (domain.ShadowReport, error) {
+	report := domain.ShadowReport{Classifier: classifier, Labels: []domain.LabelComparison{}}
+	compared := r.db.WithContext(ctx).
+		Table("shadow_classifications sc").
+		Joins("JOIN stocks s ON s.id = sc.stock_id AND s.deleted_at IS NULL").
+		Where("sc.classifier = ?", classifier)
+
+	// Overall agreement: identical label sets, regardless of order
+	var totals struct {
+		Compared   int
+		Agreements int
+	}
+	err := compared.Session(&gorm.Session{}).
+		Select("COUNT(*) AS compared, " +
+			"COALESCE(SUM(CASE WHEN s.classifications @> sc.labels AND sc.labels @> s.classifications THEN 1 ELSE 0 END), 0) AS agreements").
+		Scan(&totals).Error
+	if err != nil {
+		return report, err
+	}
+	report.Compared, report.Agreements = totals.Compared, totals.Agreements
+	if report.Compared > 0 {
+		report.AgreementRate = float64(report.Agreements) / float64(report.Compared)
+	}
+
+	// Per-label counts on each side
+	var active, shadow []labelCount
+	err = r.db.WithContext(ctx).
+		Table("(?) AS active_labels", compared.Session(&gorm.Session{}).Select("unnest(s.classifications) AS label")).
+		Select("label, COUNT(*) AS count").
+		Group("label").
+		Scan(&active).Error
+	if err != nil {
+		return report, err
+	}
+	err = r.db.WithContext(ctx).
+		Table("(?) AS shadow_labels", compared.Session(&gorm.Session{}).Select("unnest(sc.labels) AS label")).
+		Select("label, COUNT(*) AS count").
+		Group("label").
+		Scan(&shadow).Error
+	if err != nil {
+		return report, err
+	}
+
+	report.Labels = mergeLabelCounts(active, shadow)
+	return report, nil
+}
+
+// mergeLabelCounts combines the per-label counts of both classifiers, sorted by label.
+func mergeLabelCounts(active, shadow []labelCount) []domain.LabelComparison {
+	byLabel := make(map[string]*domain.LabelComparison)
+	get := func(label string) *domain.LabelComparison {
+		if byLabel[label] == nil {
+			byLabel[label] = &domain.LabelComparison{Label: label}
+		}
+		return byLabel[label]
+	}
+	for _, c := range active {
+		get(c.Label).Active = c.Count
+	}
+	for _, c := range shadow {
+		get(c.Label).Shadow = c.Count
+	}
+
+	labels := make([]domain.LabelComparison, 0, len(byLabel))
+	for _, c := range byLabel {
+		labels = append(labels, *c)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Label < labels[j].Label })
+	return labels
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"stock-api/infrastructure/atomicfile"
+	"stock-api/infrastructure/core/domain"
+)
+
+// FileSnapshotStore keeps the latest recommendation snapshot in memory and in a JSON file,
+// so it survives restarts. Every snapshot saved with a different list or weights version
+// replaces the previous one as computed, with its generation time; saving the same list
+// again writes nothing.
+type FileSnapshotStore struct {
+	path string
+
+	mu       sync.RWMutex
+	snapshot *domain.RecommendationSnapshot
+}
+
+// NewFileSnapshotStore creates a store persisting the snapshot at path.
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+// Save replaces the current snapshot, unless it holds the same list.
+func (s *FileSnapshotStore) Save(_ context.Context, snapshot domain.RecommendationSnapshot) error {
+	// Recommendations are saved on every read, and rarely change
+	s.mu.RLock()
+	unchanged := s.unchanged(snapshot)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unchanged(snapshot) {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error encoding recommendation snapshot: %w", err)
+	}
+	if err := atomicfile.Write(s.path, data); err != nil {
+		return fmt.Errorf("error writing recommendation snapshot: %w", err)
+	}
+	s.snapshot = &snapshot
+	return nil
+}
+
+// unchanged reports whether the current snapshot holds the list of snapshot, computed with
+// the same weights. The caller must hold the lock.
+func (s *FileSnapshotStore) unchanged(snapshot domain.RecommendationSnapshot) bool {
+	return s.snapshot != nil &&
+		s.snapshot.WeightsVersion == snapshot.WeightsVersion &&
+		slices.Equal(s.snapshot.Recommendations, snapshot.Recommendations)
+}
+
+// Load returns the current snapshot, reading it from the file after a restart.
+func (s *FileSnapshotStore) Load(_ context.Context) (domain.RecommendationSnapshot, bool, error) {
+	s.mu.RLock()
+	snapshot := s.snapshot
+	s.mu.RUnlock()
+	if snapshot != nil {
+		return *snapshot, true, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.RecommendationSnapshot{}, false, nil
+	}
+	if err != nil {
+		return domain.RecommendationSnapshot{}, false, fmt.Errorf("error reading recommendation snapshot: %w", err)
+	}
+
+	var loaded domain.RecommendationSnapshot
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return domain.RecommendationSnapshot{}, false, fmt.Errorf("error decoding recommendation snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.snapshot == nil {
+		s.snapshot = &loaded
+	}
+	s.mu.Unlock()
+	return loaded, true, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestFileSnapshotStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshots", "recommendations.json")
+	generatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	top := []domain.Recommendation{
+		{Position: 1, Ticker: "AAPL", Score: 80},
+		{Position: 2, Ticker: "MSFT", Score: 70},
+	}
+
+	t.Run("should report a missing snapshot", func(t *testing.T) {
+		_, ok, err := NewFileSnapshotStore(path).Load(ctx)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should survive a restart", func(t *testing.T) {
+		assert.NoError(t, NewFileSnapshotStore(path).Save(ctx, domain.RecommendationSnapshot{GeneratedAt: generatedAt, Recommendations: top}))
+
+		snapshot, ok, err := NewFileSnapshotStore(path).Load(ctx)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, top, snapshot.Recommendations)
+		assert.True(t, generatedAt.Equal(snapshot.GeneratedAt))
+	})
+
+	t.Run("should store exactly the latest snapshot", func(t *testing.T) {
+		store := NewFileSnapshotStore(path)
+		later := domain.RecommendationSnapshot{GeneratedAt: generatedAt.Add(time.Minute), WeightsVersion: 3, Recommendations: top[:1]}
+		assert.NoError(t, store.Save(ctx, domain.RecommendationSnapshot{GeneratedAt: generatedAt, WeightsVersion: 2, Recommendations: top}))
+		assert.NoError(t, store.Save(ctx, later))
+
+		snapshot, _, _ := store.Load(ctx)
+		assert.Equal(t, later, snapshot)
+
+		reloaded, _, _ := NewFileSnapshotStore(path).Load(ctx)
+		assert.Equal(t, later.Recommendations, reloaded.Recommendations)
+		assert.Equal(t, 3, reloaded.WeightsVersion)
+		assert.True(t, later.GeneratedAt.Equal(reloaded.GeneratedAt))
+
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		assert.Len(t, entries, 1, "no temporary file should be left behind")
+	})
+
+	t.Run("should keep the snapshot while the list is unchanged", func(t *testing.T) {
+		store := NewFileSnapshotStore(path)
+		first := domain.RecommendationSnapshot{GeneratedAt: generatedAt, WeightsVersion: 4, Recommendations: top}
+		assert.NoError(t, store.Save(ctx, first))
+		require.NoError(t, os.Remove(path))
+
+		assert.NoError(t, store.Save(ctx, domain.RecommendationSnapshot{GeneratedAt: generatedAt.Add(time.Hour), WeightsVersion: 4, Recommendations: top}))
+		snapshot, _, _ := store.Load(ctx)
+		assert.True(t, generatedAt.Equal(snapshot.GeneratedAt))
+		assert.NoFileExists(t, path, "an unchanged list is not written again")
+
+		assert.NoError(t, store.Save(ctx, domain.RecommendationSnapshot{GeneratedAt: generatedAt.Add(time.Hour), WeightsVersion: 5, Recommendations: top}))
+		assert.FileExists(t, path)
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rows of the stocks table are updated in place, by admin edits and reclassification
+// jobs. Before each update, the values the row had are copied to the stock_revisions
+// table, in the same transaction, so reads of a past time can rebuild the row as it stood
+// then: with the values of its first revision after that time, or its current values if
+// it was not updated since.
+
+// revisedColumns are the columns of the stocks table kept by each revision: all those
+// updates may write. The ID and the creation and deletion times are those of the row.
+var revisedColumns = []string{
+	"updated_at",
+	"ticker",
+	"target_from",
+	"target_to",
+	"company",
+	"company_normalized",
+	"action",
+	"brokerage",
+	"rating_from",
+	"rating_to",
+	"time",
+	"classifications",
+	"warnings",
+}
+
+// recordRevisions copies the current values of the live stocks with the given IDs to the
+// stock_revisions table, as the values they had until revisedAt. It must run in the
+// transaction of the update.
+func recordRevisions(tx *gorm.DB, revisedAt time.Time, ids ...uint) error {
+	columns := strings.Join(revisedColumns, ", ")
+	return tx.Exec(
+		"INSERT INTO stock_revisions (stock_id, revised_at, "+columns+") "+
+			"SELECT id, ?, "+columns+" FROM stocks WHERE id IN ? AND deleted_at IS NULL",
+		revisedAt, ids,
+	).Error
+}
+
+// stocksAtSQL selects the rows of the stocks table as they stood at @at: those created by
+// then and not deleted yet, including rows deleted since. Rows updated since take the
+// values of their first revision after @at.
+var stocksAtSQL = "SELECT " + qualifyColumns("stocks", "id", "created_at", "deleted_at") + ", " + qualifyColumns("stocks", revisedColumns...) +
+	" FROM stocks" +
+	" WHERE stocks.created_at <= @at AND (stocks.deleted_at IS NULL OR stocks.deleted_at > @at)" +
+	" AND NOT EXISTS (SELECT 1 FROM stock_revisions WHERE stock_id = stocks.id AND revised_at > @at)" +
+	" UNION ALL " +
+	"SELECT " + qualifyColumns("stocks", "id", "created_at", "deleted_at") + ", " + qualifyColumns("revision", revisedColumns...) +
+	" FROM stocks JOIN stock_revisions AS revision ON revision.stock_id = stocks.id" +
+	" WHERE stocks.created_at <= @at AND (stocks.deleted_at IS NULL OR stocks.deleted_at > @at)" +
+	" AND revision.id = (SELECT id FROM stock_revisions WHERE stock_id = stocks.id AND revised_at > @at ORDER BY revised_at, id LIMIT 1)"
+
+// stocksAt returns the rows of the stocks table as they stood at at, as a subquery to be
+// read under the name of the table.
+func stocksAt(db *gorm.DB, at time.Time) *gorm.DB {
+	return db.Raw(stocksAtSQL, sql.Named("at", at))
+}
+
+// qualifyColumns prefixes each column with table, and joins them into a select list.
+func qualifyColumns(table string, columns ...string) string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = table + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
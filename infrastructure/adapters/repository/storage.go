@@ -0,0 +1,14 @@
+package repository
+
+import "stock-api/infrastructure/core/port"
+
+// Stocks are stored in the SQL database by StockBDRepository. StockMemoryRepository keeps
+// them in process memory for sandbox mode and tests: it shares the query semantics of the
+// SQL backend, but nothing survives a restart and it does not scale past a single process,
+// so it is not a deployment option.
+
+// Compile-time checks that both stores are complete stock stores.
+var (
+	_ port.StockStore = (*StockBDRepository)(nil)
+	_ port.StockStore = (*StockMemoryRepository)(nil)
+)
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// WebhookRepository stores webhook subscriptions and their delivery logs.
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository.
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateSubscription inserts a new webhook subscription.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+// FindSubscription retrieves a subscription by its ID.
+// It returns domain.ErrWebhookNotFound if no such subscription exists.
+func (r *WebhookRepository) FindSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	err := r.db.WithContext(ctx).First(&subscription, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions retrieves every subscription, or only the active ones.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, activeOnly bool) ([]domain.WebhookSubscription, error) {
+	subscriptions := []domain.WebhookSubscription{}
+	query := r.db.WithContext(ctx).Order("id")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription writes the URL, topics, and active flag of a subscription.
+// The secret is never changed. It returns domain.ErrWebhookNotFound if the
+// subscription does not exist.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	result := r.db.WithContext(ctx).
+		Model(subscription).
+		Select("url", "tickers", "classifications", "active", "updated_at").
+		Updates(subscription)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription and, through the foreign key, its
+// delivery log. It returns domain.ErrWebhookNotFound if the subscription does not exist.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&domain.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// RecordDelivery inserts the outcome of a delivery.
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+// ListDeliveries retrieves one page of a subscription's deliveries, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, page, pageSize int) ([]domain.WebhookDelivery, error) {
+	deliveries := []domain.WebhookDelivery{}
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
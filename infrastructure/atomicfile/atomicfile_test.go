@@ -0,0 +1,33 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "out.json")
+
+	t.Run("should create the directory and the file", func(t *testing.T) {
+		assert.NoError(t, Write(path, []byte("first")))
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", string(data))
+
+		info, _ := os.Stat(path)
+		assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+	})
+
+	t.Run("should replace the file without leaving temporary files behind", func(t *testing.T) {
+		assert.NoError(t, Write(path, []byte("second")))
+
+		data, _ := os.ReadFile(path)
+		assert.Equal(t, "second", string(data))
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		assert.Len(t, entries, 1)
+	})
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// asOfKey is the context key of the time a request reads the data at.
+type asOfKey struct{}
+
+// WithAsOf returns a context reading the stocks as they stood at at, read back with
+// AsOfFrom. Services serve past views through repositories implementing port.AsOfReader.
+func WithAsOf(ctx context.Context, at time.Time) context.Context {
+	return context.WithValue(ctx, asOfKey{}, at)
+}
+
+// AsOfFrom returns the time the data is read at with ctx. It reports false when the
+// current data is read.
+func AsOfFrom(ctx context.Context) (time.Time, bool) {
+	at, ok := ctx.Value(asOfKey{}).(time.Time)
+	return at, ok
+}
+
+// ParseAsOf parses the asOf parameter of a read: an RFC 3339 timestamp, or a date
+// (2006-01-02) standing for the end of that day in UTC, so a date reads what the API
+// showed once the day was over. Today stands for now. Times after now are rejected, as
+// the future cannot be read. Errors wrap ErrInvalidAsOf.
+func ParseAsOf(raw string, now time.Time) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		day, dayErr := time.Parse(time.DateOnly, raw)
+		if dayErr != nil {
+			return time.Time{}, fmt.Errorf("%w: %q is neither an RFC 3339 timestamp nor a date", ErrInvalidAsOf, raw)
+		}
+		at = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if at.After(now) && !day.After(now) {
+			at = now
+		}
+	}
+	if at.After(now) {
+		return time.Time{}, fmt.Errorf("%w: %s is in the future", ErrInvalidAsOf, raw)
+	}
+	return at.UTC(), nil
+}
+
+// VisibleAt reports whether the stock was in the data read at at: stored by then and not
+// deleted yet.
+func (s *Stock) VisibleAt(at time.Time) bool {
+	if s.CreatedAt.After(at) {
+		return false
+	}
+	return !s.DeletedAt.Valid || s.DeletedAt.Time.After(at)
+}
+
+// RevisedSince returns the number of stocks of a past view that were updated after at.
+// Past views hold the values stocks had at at, last updated by then, except for updates
+// made before revisions were kept: such stocks are shown with their current values rather
+// than those they had then.
+func RevisedSince(at time.Time, stocks ...Stock) int {
+	revised := 0
+	for i := range stocks {
+		if stocks[i].UpdatedAt.After(at) {
+			revised++
+		}
+	}
+	return revised
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// AuditEntry records an administrative action that changed system behavior.
+// It contains who performed the action, what was done, and how it ended.
+type AuditEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Actor     string    `gorm:"size:100;not null;index" json:"actor"` // Identity of the admin who performed the action
+	Action    string    `gorm:"size:100;not null" json:"action"`      // Action name (e.g., "cache.purge")
+	Method    string    `gorm:"size:10;not null" json:"method"`       // HTTP method of the request
+	Path      string    `gorm:"size:255;not null" json:"path"`        // Request path
+	Status    int       `gorm:"not null" json:"status"`               // HTTP status returned to the actor
+	Details   string    `gorm:"type:text" json:"details,omitempty"`   // JSON-encoded action details
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`     // When the action was performed
+}
+
+// TableName overrides the table name used by GORM.
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
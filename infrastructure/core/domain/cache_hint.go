@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache lifetimes the services hint for the data they read.
+const (
+	// StockCacheMaxAge is how long responses listing or describing stocks may be reused,
+	// which bounds how long new analyst events take to reach clients.
+	StockCacheMaxAge = 30 * time.Second
+	// CountCacheMaxAge is how long count-only totals, which feed badges and stats, may be
+	// reused.
+	CountCacheMaxAge = 5 * time.Minute
+)
+
+// CacheHint describes how long a response built from some data may be reused by clients
+// and shared caches.
+//
+// Fields:
+// - MaxAge: How long the response stays fresh, after which it must be revalidated.
+// - Revalidate: When true, the response may be stored but must be revalidated with its
+// ETag before each reuse, for data that is valid until an unpredictable change such as
+// the next ingestion.
+// - NoStore: When true, the response must not be stored at all.
+//
+// Hinted responses depend on their URL only, as the services never read request headers.
+type CacheHint struct {
+	MaxAge     time.Duration
+	Revalidate bool
+	NoStore    bool
+}
+
+// Merge combines two hints for a response built from both of their data, keeping the
+// most restrictive policy: the shortest lifetime, and any revalidation or no-store.
+func (h CacheHint) Merge(other CacheHint) CacheHint {
+	return CacheHint{
+		MaxAge:     min(h.MaxAge, other.MaxAge),
+		Revalidate: h.Revalidate || other.Revalidate,
+		NoStore:    h.NoStore || other.NoStore,
+	}
+}
+
+// cacheHintsKey is the context key of the hints collected for the current response.
+type cacheHintsKey struct{}
+
+// cacheHints collects the hints given while a response is built. Services may run on
+// other goroutines than the handler, so it is guarded by a lock.
+type cacheHints struct {
+	mu    sync.Mutex
+	hint  CacheHint
+	given bool
+}
+
+// WithCacheHints returns a context collecting the cache hints given while a response is
+// built, read back with CacheHintFrom.
+func WithCacheHints(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheHintsKey{}, &cacheHints{})
+}
+
+// HintCache records how long the data read with ctx may be cached. Hints given for a
+// single response are merged with CacheHint.Merge. Outside a context created by
+// WithCacheHints, such as background jobs, it does nothing.
+func HintCache(ctx context.Context, hint CacheHint) {
+	hints, ok := ctx.Value(cacheHintsKey{}).(*cacheHints)
+	if !ok {
+		return
+	}
+
+	hints.mu.Lock()
+	defer hints.mu.Unlock()
+	if hints.given {
+		hint = hints.hint.Merge(hint)
+	}
+	hints.hint, hints.given = hint, true
+}
+
+// CacheHintFrom returns the merged hints given with ctx. It reports false if none were
+// given, in which case the response carries no caching policy.
+func CacheHintFrom(ctx context.Context) (CacheHint, bool) {
+	hints, ok := ctx.Value(cacheHintsKey{}).(*cacheHints)
+	if !ok {
+		return CacheHint{}, false
+	}
+
+	hints.mu.Lock()
+	defer hints.mu.Unlock()
+	return hints.hint, hints.given
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// companySuffixes lists the legal-form suffixes stripped from company names, in their
+// normalized form (lowercase, punctuation removed, so "S.A." is "sa").
+var companySuffixes = map[string]struct{}{
+	"inc": {}, "incorporated": {}, "corp": {}, "corporation": {}, "co": {}, "company": {},
+	"ltd": {}, "limited": {}, "plc": {}, "llc": {}, "lp": {}, "llp": {},
+	"sa": {}, "sab": {}, "cv": {}, "de": {}, "nv": {}, "bv": {}, "ag": {}, "se": {}, "spa": {},
+	"gmbh": {}, "kgaa": {}, "ab": {}, "asa": {}, "oyj": {}, "as": {}, "kk": {},
+	"sarl": {}, "sas": {}, "srl": {}, "pte": {}, "pty": {}, "bhd": {}, "tbk": {},
+}
+
+// NormalizeCompany returns the searchable form of a company name: compatibility-folded,
+// without diacritics, lowercase, with punctuation collapsed to single spaces and trailing
+// legal-form suffixes removed. For example, "Société Générale S.A." becomes
+// "societe generale" and "ＡＣＭＥ Holdings Co., Ltd." becomes "acme holdings".
+func NormalizeCompany(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop the combining marks left by the decomposition
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case r == '.' || r == '\'' || r == '’':
+			// Join abbreviations and possessives ("S.A.", "Macy's")
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	// "S.A. de C.V." and "Co., Ltd." stack several suffixes; keep at least one word
+	for len(words) > 1 {
+		if _, ok := companySuffixes[words[len(words)-1]]; !ok {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// CompanyHasKeyword reports whether any word of the normalized company name starts with
+// keyword, which must be lowercase. Matching word prefixes lets "tech" match
+// "Technologies" without matching "Biotech".
+func CompanyHasKeyword(normalized, keyword string) bool {
+	for _, word := range strings.Fields(normalized) {
+		if strings.HasPrefix(word, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrStockNotFound is returned when no stock matches the requested criteria.
+	ErrStockNotFound = errors.New("stock not found")
+	// ErrInvalidStock is returned when a stock supplied by a client fails validation.
+	ErrInvalidStock = errors.New("invalid stock")
+	// ErrInvalidFilter is returned when filters supplied by a client are rejected.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrJobNotFound is returned when no background job has the requested ID.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobRunning is returned when a job is started while another one of the same kind is running.
+	ErrJobRunning = errors.New("job already running")
+	// ErrWebhookNotFound is returned when no webhook subscription has the requested ID.
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidWebhook is returned when a webhook subscription fails validation.
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
+	// ErrScoringWeightsVersionNotFound is returned when no scoring weights were stored with the requested version.
+	ErrScoringWeightsVersionNotFound = errors.New("scoring weights version not found")
+	// ErrScoringWeightNotFound is returned when removing the weight of a classification that has none.
+	ErrScoringWeightNotFound = errors.New("scoring weight not found")
+	// ErrInvalidScoringWeight is returned when a scoring weight change fails validation.
+	ErrInvalidScoringWeight = errors.New("invalid scoring weight")
+	// ErrInvalidAsOf is returned when the time a read is made at cannot be parsed or is in the future.
+	ErrInvalidAsOf = errors.New("invalid asOf")
+	// ErrAsOfUnsupported is returned when the data cannot be read as it stood at a past time.
+	ErrAsOfUnsupported = errors.New("asOf is not supported")
+)
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// WriteOperation identifies the kind of write applied to the stocks table.
+type WriteOperation string
+
+const (
+	WriteCreate WriteOperation = "create"
+	WriteBatch  WriteOperation = "batch"
+	WriteUpdate WriteOperation = "update"
+	WriteDelete WriteOperation = "delete"
+)
+
+// StockWriteEvent is published after a successful write to the stocks table.
+// Stocks holds copies of the written rows, so subscribers can read them without
+// racing against the writer.
+type StockWriteEvent struct {
+	Operation  WriteOperation `json:"operation"`
+	Stocks     []Stock        `json:"stocks"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// IngestionRunEvent is published when the ingestion pipeline finishes a run,
+// successfully or not. Warnings counts the stored stocks raising soft validation warnings.
+type IngestionRunEvent struct {
+	Total      int           `json:"total"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   time.Duration `json:"duration"`
+	Warnings   WarningTally  `json:"warnings"`
+	Error      string        `json:"error,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
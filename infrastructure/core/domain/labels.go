@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Label limits applied to client-supplied classifications.
+const (
+	MaxLabelsPerStock = 20
+	MaxLabelLength    = 50
+)
+
+// NeutralLabel is the label assigned to stocks no classifier matched. It stands for "not
+// classified yet" when filtering.
+const NeutralLabel = "Neutral"
+
+// CustomLabelPrefix namespaces client-supplied labels that are not in the registry.
+const CustomLabelPrefix = "custom:"
+
+// knownLabels is the registry of classification labels the system understands.
+// It contains every label produced by the classifiers.
+var knownLabels = map[string]struct{}{
+	"Biotech":               {},
+	"Tech":                  {},
+	"Financial":             {},
+	"Energy":                {},
+	"Other Sector":          {},
+	"High-Risk Speculative": {},
+	"Potential Growth":      {},
+	"Bullish Signal":        {},
+	"Bearish Signal":        {},
+	"New Coverage":          {},
+	"Analyst Positive":      {},
+	"Analyst Negative":      {},
+	"Momentum Upgrades":     {},
+	"Deteriorating Outlook": {},
+	NeutralLabel:            {},
+}
+
+// IsKnownLabel reports whether label is in the label registry.
+func IsKnownLabel(label string) bool {
+	_, ok := knownLabels[label]
+	return ok
+}
+
+// UnknownLabelPolicy decides what happens to client-supplied labels missing from the registry.
+type UnknownLabelPolicy string
+
+const (
+	// LabelPolicyDeny rejects writes containing unknown labels.
+	LabelPolicyDeny UnknownLabelPolicy = "deny"
+	// LabelPolicyNamespace keeps unknown labels as custom tags, prefixed with CustomLabelPrefix.
+	LabelPolicyNamespace UnknownLabelPolicy = "namespace"
+	// LabelPolicyAllow keeps unknown labels as they are.
+	LabelPolicyAllow UnknownLabelPolicy = "allow"
+)
+
+// ParseUnknownLabelPolicy converts a configuration value into an UnknownLabelPolicy.
+func ParseUnknownLabelPolicy(s string) (UnknownLabelPolicy, error) {
+	switch policy := UnknownLabelPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case LabelPolicyDeny, LabelPolicyNamespace, LabelPolicyAllow:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid unknown label policy: %q (must be deny, namespace, or allow)", s)
+	}
+}
+
+// NormalizeLabels validates client-supplied labels: it trims them, drops empty values and
+// duplicates, enforces the size limits, and applies policy to labels missing from the registry.
+// Errors wrap ErrInvalidStock.
+func NormalizeLabels(labels []string, policy UnknownLabelPolicy) ([]string, error) {
+	if len(labels) > MaxLabelsPerStock {
+		return nil, fmt.Errorf("%w: too many classifications: %d (maximum %d)", ErrInvalidStock, len(labels), MaxLabelsPerStock)
+	}
+
+	normalized := make([]string, 0, len(labels))
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if len(label) > MaxLabelLength {
+			return nil, fmt.Errorf("%w: classification too long: %q (maximum %d characters)", ErrInvalidStock, label, MaxLabelLength)
+		}
+
+		// Custom tags are unknown labels too, so the deny policy rejects them
+		if !IsKnownLabel(label) {
+			switch policy {
+			case LabelPolicyAllow:
+			case LabelPolicyNamespace:
+				if !strings.HasPrefix(label, CustomLabelPrefix) {
+					label = CustomLabelPrefix + label
+				}
+			default:
+				return nil, fmt.Errorf("%w: unknown classification: %q", ErrInvalidStock, label)
+			}
+		}
+
+		if _, dup := seen[label]; dup {
+			continue
+		}
+		seen[label] = struct{}{}
+		normalized = append(normalized, label)
+	}
+
+	return normalized, nil
+}
@@ -14,3 +14,16 @@ type PaginationParams struct {
 	SortField string `form:"sortField"`
 	SortOrder int    `form:"sortOrder"` // 1 for asc, -1 for desc
 }
+
+// PaginationLimits bounds the page sizes clients can request.
+//
+// Fields:
+// - DefaultPageSize: The page size applied when a client omits it.
+// - MaxPageSize: The hard cap on the page size. Larger requests are clamped to it.
+type PaginationLimits struct {
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+// DefaultPaginationLimits are the limits applied unless configured otherwise.
+var DefaultPaginationLimits = PaginationLimits{DefaultPageSize: 20, MaxPageSize: 500}
@@ -0,0 +1,32 @@
+package domain
+
+// Roles granted to authenticated principals.
+const (
+	RoleAdmin = "admin"
+)
+
+// Rate-limit tiers. Principals in the unlimited tier have no request quota.
+const (
+	RateLimitTierUnlimited = "unlimited"
+)
+
+// Principal describes the identity behind an authenticated request.
+//
+// Fields:
+// - Name: The actor name recorded in the audit log.
+// - Roles: The roles granted to the principal.
+// - RateLimitTier: The rate-limit tier applied to the principal's requests.
+// - Quota: The principal's quota usage, nil when its tier has no quota.
+type Principal struct {
+	Name          string      `json:"principal"`
+	Roles         []string    `json:"roles"`
+	RateLimitTier string      `json:"rate_limit_tier"`
+	Quota         *QuotaUsage `json:"quota"`
+}
+
+// QuotaUsage reports how much of a request quota has been consumed.
+type QuotaUsage struct {
+	Limit     int `json:"limit"`
+	Used      int `json:"used"`
+	Remaining int `json:"remaining"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// QueryOptions holds optional query-string switches that change how a list query is
+// executed without affecting which rows match.
+//
+// Fields:
+// - Estimate: When true, the total may be estimated from planner statistics for broad
+// queries instead of running an exact COUNT(*). Exact totals are returned otherwise.
+// - Compact: When true, list endpoints return the abbreviated stock representation.
+// - CountOnly: When true, only the total is computed; no rows are fetched.
+type QueryOptions struct {
+	Estimate  bool `form:"estimate"`
+	Compact   bool `form:"compact"`
+	CountOnly bool `form:"countOnly"`
+}
+
+// StockPage is one page of stocks together with the metadata describing the result.
+//
+// Fields:
+// - Stocks: The stocks in the requested page.
+// - Total: The number of stocks matching the filters.
+// - Pagination: The pagination actually applied, after defaults and caps.
+// - Approximate: True when Total is an estimate rather than an exact count.
+// - CachedAt: When Total was computed, if it was served from a cache older than
+// StaleAfter. The zero time means Total is fresh.
+type StockPage struct {
+	Stocks      []Stock
+	Total       int
+	Pagination  PaginationParams
+	Approximate bool
+	CachedAt    time.Time
+}
+
+// StaleAfter is the age from which cached data is reported to clients as stale.
+const StaleAfter = time.Minute
+
+// Stale reports whether the page includes cached data older than StaleAfter.
+func (p StockPage) Stale() bool {
+	return !p.CachedAt.IsZero()
+}
@@ -0,0 +1,20 @@
+package domain
+
+// SchemaVersion is the migration state of the database schema.
+// Fields:
+// - Version: The version of the last applied migration, nil if none was applied.
+// - Dirty: True if a migration failed halfway and the schema needs manual repair.
+type SchemaVersion struct {
+	Version *uint `json:"schemaVersion"`
+	Dirty   bool  `json:"dirty"`
+}
+
+// Readiness is the payload of the readiness probe.
+// Fields:
+// - Status: "ready" or "not ready".
+// - Error: Why the service is not ready, if it is not.
+type Readiness struct {
+	Status string `json:"status"`
+	SchemaVersion
+	Error string `json:"error,omitempty"`
+}
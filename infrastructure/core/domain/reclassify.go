@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// JobStatus is the state of a background job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// ReclassifyJob tracks a background run of the classifier over the stored stocks matching
+// a set of filters.
+//
+// Fields:
+// - ID: The identifier used to poll the job.
+// - Status: Whether the job is running, completed, or failed.
+// - Filters: The filters selecting the stocks to reclassify.
+// - Actor: The admin who started the job.
+// - Total: The number of matching stocks when the job started. Stocks written afterwards may change the final count.
+// - Processed: The number of stocks run through the classifier so far.
+// - Updated: The number of stocks whose classifications changed and were written.
+// - Error: Why the job failed, if it did.
+// - StartedAt, FinishedAt: When the job started and, once it is over, finished.
+type ReclassifyJob struct {
+	ID         string     `json:"id"`
+	Status     JobStatus  `json:"status"`
+	Filters    Filters    `json:"filters"`
+	Actor      string     `json:"actor"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Updated    int        `json:"updated"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
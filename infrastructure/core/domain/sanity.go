@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Invariants checked by the data sanity sampler. Rows written before validation existed
+// may violate them.
+const (
+	InvariantParsableTargets = "parsable_targets"   // TargetFrom and TargetTo parse as amounts
+	InvariantTickerFormat    = "ticker_format"      // Ticker contains only uppercase letters and numbers
+	InvariantClassifications = "classifications"    // At least one classification is set
+	InvariantTimeNotInFuture = "time_not_in_future" // Time is not after the check
+)
+
+// maxSanityExamples bounds the number of example IDs kept per violated invariant.
+const maxSanityExamples = 5
+
+// CheckInvariants returns the invariants the stock violates, with now as the reference
+// for the time check.
+func (s *Stock) CheckInvariants(now time.Time) []string {
+	var violated []string
+	if _, err := parseCurrencyToFloat(s.TargetFrom); err != nil {
+		violated = append(violated, InvariantParsableTargets)
+	} else if _, err := parseCurrencyToFloat(s.TargetTo); err != nil {
+		violated = append(violated, InvariantParsableTargets)
+	}
+	if !tickerPattern.MatchString(s.Ticker) {
+		violated = append(violated, InvariantTickerFormat)
+	}
+	if len(s.Classifications) == 0 {
+		violated = append(violated, InvariantClassifications)
+	}
+	if s.Time.After(now) {
+		violated = append(violated, InvariantTimeNotInFuture)
+	}
+	return violated
+}
+
+// SanityReport summarizes the invariant violations found in a sample of stocks.
+// Fields:
+//   - Sampled: The number of stocks checked.
+//   - Violating: The number of stocks violating at least one invariant.
+//   - Violations: The number of violating stocks per invariant.
+//   - Examples: Up to maxSanityExamples IDs of violating stocks per invariant.
+//   - CheckedAt: The reference time of the check.
+type SanityReport struct {
+	Sampled    int               `json:"sampled"`
+	Violating  int               `json:"violating"`
+	Violations map[string]int    `json:"violations"`
+	Examples   map[string][]uint `json:"examples"`
+	CheckedAt  time.Time         `json:"checkedAt"`
+}
+
+// NewSanityReport checks the invariants of every stock and builds the report.
+func NewSanityReport(stocks []Stock, now time.Time) SanityReport {
+	report := SanityReport{
+		Sampled:    len(stocks),
+		Violations: make(map[string]int),
+		Examples:   make(map[string][]uint),
+		CheckedAt:  now,
+	}
+
+	for i := range stocks {
+		violated := stocks[i].CheckInvariants(now)
+		if len(violated) == 0 {
+			continue
+		}
+		report.Violating++
+		for _, invariant := range violated {
+			report.Violations[invariant]++
+			if len(report.Examples[invariant]) < maxSanityExamples {
+				report.Examples[invariant] = append(report.Examples[invariant], stocks[i].ID)
+			}
+		}
+	}
+
+	return report
+}
+
+// ViolationRate returns the fraction of sampled stocks violating at least one invariant.
+func (r SanityReport) ViolationRate() float64 {
+	if r.Sampled == 0 {
+		return 0
+	}
+	return float64(r.Violating) / float64(r.Sampled)
+}
+
+// String formats the report as a single log line, e.g.
+// "sampled=500 violating=3 rate=0.60% ticker_format=2 (ids 4,9) time_not_in_future=1 (ids 12)".
+func (r SanityReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sampled=%d violating=%d rate=%.2f%%", r.Sampled, r.Violating, r.ViolationRate()*100)
+
+	invariants := make([]string, 0, len(r.Violations))
+	for invariant := range r.Violations {
+		invariants = append(invariants, invariant)
+	}
+	sort.Strings(invariants)
+
+	for _, invariant := range invariants {
+		ids := make([]string, len(r.Examples[invariant]))
+		for i, id := range r.Examples[invariant] {
+			ids[i] = fmt.Sprint(id)
+		}
+		fmt.Fprintf(&b, " %s=%d (ids %s)", invariant, r.Violations[invariant], strings.Join(ids, ","))
+	}
+	return b.String()
+}
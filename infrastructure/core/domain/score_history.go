@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// ScoreHistoryEntry records the score of a ticker computed after an ingestion run, from
+// its latest analyst event at the time. The entries of a ticker chart how its
+// attractiveness evolved.
+type ScoreHistoryEntry struct {
+	ID                   uint      `gorm:"primarykey" json:"-"`
+	Ticker               string    `gorm:"size:10;not null" json:"ticker"`
+	StockID              uint      `gorm:"not null" json:"stock_id"` // ID of the scored event
+	Score                float64   `gorm:"not null" json:"score"`
+	UpsidePoints         float64   `gorm:"not null" json:"upside_points"`
+	ClassificationPoints float64   `gorm:"not null" json:"classification_points"`
+	RatingPoints         float64   `gorm:"not null" json:"rating_points"`
+	RiskScore            float64   `gorm:"not null" json:"risk_score"`
+	EventTime            time.Time `gorm:"not null" json:"event_time"`  // Time of the scored event
+	ComputedAt           time.Time `gorm:"not null" json:"computed_at"` // End of the ingestion run
+}
+
+// TableName overrides the default table name.
+func (ScoreHistoryEntry) TableName() string {
+	return "score_history"
+}
+
+// Score history page limits.
+const (
+	DefaultScoreHistoryLimit = 500
+	MaxScoreHistoryLimit     = 5000
+)
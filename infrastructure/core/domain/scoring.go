@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// ClassificationPoints holds the built-in points each classification adds to a stock's
+// score, in effect until scoring weights are stored (see ScoringWeights).
+// Classifications not listed here do not contribute to the score.
+var ClassificationPoints = map[string]float64{
+	"Potential Growth": 30,
+	"Bullish Signal":   25,
+	"New Coverage":     20,
+	"Analyst Positive": 15,
+	"Tech":             10,
+	"Biotech":          8,
+}
+
+// RatingPoints holds the points each final analyst rating adds to a stock's score.
+var RatingPoints = map[string]float64{
+	"Strong-Buy": 40,
+	"Outperform": 30,
+	"Buy":        20,
+}
+
+// MaxUpsidePoints caps the points a stock can earn from its upside potential.
+const MaxUpsidePoints = 100
+
+// RiskPoints holds the risk each classification adds to a stock's risk score.
+// Stocks with any of these classifications are excluded from recommendations.
+var RiskPoints = map[string]float64{
+	"High-Risk Speculative": 50,
+	"Bearish Signal":        30,
+	"Analyst Negative":      30,
+	"Deteriorating Outlook": 30,
+}
+
+// MaxRiskScore caps a stock's risk score.
+const MaxRiskScore = 100
+
+// RecommendationPoolSize is the number of most recent events scored for recommendations.
+const RecommendationPoolSize = 5000
+
+// FreshnessHorizon is the age after which an analyst event is considered fully stale.
+const FreshnessHorizon = 30 * 24 * time.Hour
+
+// ClassificationContribution is the number of points a classification adds to a score.
+type ClassificationContribution struct {
+	Label  string  `json:"label"`
+	Points float64 `json:"points"`
+}
+
+// ScoreBreakdown details how a stock's composite score is built, along with its risk
+// and the freshness of the underlying analyst event.
+//
+// Fields:
+// - Score: The composite score used to rank recommendations.
+// - Upside: The upside potential in percent, or nil when the targets cannot be parsed.
+// - UpsidePoints, RatingPoints, Classifications: The components of Score.
+// - RiskScore: The sum of the risk points of the stock's classifications, capped at MaxRiskScore.
+// - Recommended: Whether the stock is eligible for recommendations (no risky classifications).
+// - EventTime, AgeHours: When the analyst event happened and how long ago.
+// - Freshness: 1 for a brand-new event, decreasing linearly to 0 at FreshnessHorizon.
+type ScoreBreakdown struct {
+	Ticker          string                       `json:"ticker"`
+	Company         string                       `json:"company"`
+	Score           float64                      `json:"score"`
+	Upside          *float64                     `json:"upside"`
+	UpsidePoints    float64                      `json:"upside_points"`
+	RatingPoints    float64                      `json:"rating_points"`
+	Classifications []ClassificationContribution `json:"classifications"`
+	RiskScore       float64                      `json:"risk_score"`
+	Recommended     bool                         `json:"recommended"`
+	EventTime       time.Time                    `json:"event_time"`
+	AgeHours        float64                      `json:"age_hours"`
+	Freshness       float64                      `json:"freshness"`
+}
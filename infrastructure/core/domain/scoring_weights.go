@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"time"
+)
+
+// MaxScoringWeight bounds the points a single classification can add to a score, in
+// either direction.
+const MaxScoringWeight = 1000
+
+// ScoringWeight is a change to the points a classification adds to a stock's score.
+// Changes are never updated in place: each one is stored with the version of the weights
+// it produced, so the weights in effect at any past version can be rebuilt and past
+// recommendation snapshots reproduced.
+//
+// Fields:
+// - Version: The version of the weights this change produced. Changes made together share a version.
+// - Label: The classification the weight applies to.
+// - Points: The points the classification adds to the score. Ignored when Removed is set.
+// - Removed: Whether the change removed the label's weight, so it no longer contributes to scores.
+// - Actor: Who made the change, as recorded in the audit log.
+type ScoringWeight struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	Version   int       `gorm:"not null" json:"version"`
+	Label     string    `gorm:"size:50;not null" json:"label"`
+	Points    float64   `gorm:"not null" json:"points"`
+	Removed   bool      `gorm:"not null" json:"removed,omitempty"`
+	Actor     string    `gorm:"size:100;not null" json:"actor"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+// TableName overrides the default table name.
+func (ScoringWeight) TableName() string {
+	return "scoring_weights"
+}
+
+// Validate checks that the change applies to a known label and, unless it removes the
+// weight, that its points are finite and within MaxScoringWeight. Errors wrap
+// ErrInvalidScoringWeight.
+func (w *ScoringWeight) Validate() error {
+	if !IsKnownLabel(w.Label) {
+		return fmt.Errorf("%w: unknown classification %q", ErrInvalidScoringWeight, w.Label)
+	}
+	if w.Removed {
+		return nil
+	}
+	if math.IsNaN(w.Points) || math.Abs(w.Points) > MaxScoringWeight {
+		return fmt.Errorf("%w: points must be between -%d and %d", ErrInvalidScoringWeight, MaxScoringWeight, MaxScoringWeight)
+	}
+	return nil
+}
+
+// ScoringWeights is the set of classification points in effect at a version.
+// Version 0 stands for the built-in ClassificationPoints, used until a weight is stored.
+type ScoringWeights struct {
+	Version int                `json:"version"`
+	Points  map[string]float64 `json:"points"`
+}
+
+// CurrentScoringWeights returns w itself, so fixed weights can be supplied wherever the
+// weights in effect are expected, such as to reproduce recommendations of a past version.
+func (w ScoringWeights) CurrentScoringWeights() ScoringWeights {
+	return w
+}
+
+// DefaultScoringWeights returns the built-in weights.
+func DefaultScoringWeights() ScoringWeights {
+	return ScoringWeights{Points: maps.Clone(ClassificationPoints)}
+}
+
+// ReplayScoringWeights rebuilds the weights in effect at version from the stored changes,
+// in the order they were made. Changes of later versions are ignored, unless version is
+// zero, which replays every change. Without any change, the built-in weights are returned.
+func ReplayScoringWeights(changes []ScoringWeight, version int) ScoringWeights {
+	weights := ScoringWeights{Points: map[string]float64{}}
+	for _, change := range changes {
+		if version > 0 && change.Version > version {
+			continue
+		}
+		weights.Version = max(weights.Version, change.Version)
+		if change.Removed {
+			delete(weights.Points, change.Label)
+		} else {
+			weights.Points[change.Label] = change.Points
+		}
+	}
+	if weights.Version == 0 {
+		return DefaultScoringWeights()
+	}
+	return weights
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// ShadowClassification stores the labels a candidate (shadow) classifier assigned to a
+// stock during ingestion. Shadow labels never affect queries or scoring; they are only
+// compared against the labels of the active classifier.
+type ShadowClassification struct {
+	ID         uint        `gorm:"primarykey" json:"id"`
+	StockID    uint        `gorm:"not null;index" json:"stock_id"`           // ID of the classified stock
+	Ticker     string      `gorm:"size:10;not null" json:"ticker"`           // Stock ticker, for convenience
+	Classifier string      `gorm:"size:50;not null;index" json:"classifier"` // Name of the shadow classifier
+	Labels     StringArray `gorm:"type:text[]" json:"labels"`                // Labels assigned by the shadow classifier
+	CreatedAt  time.Time   `gorm:"not null" json:"created_at"`
+}
+
+// LabelComparison counts how often a label was assigned by the active and the shadow
+// classifiers over the compared stocks.
+type LabelComparison struct {
+	Label  string `json:"label"`
+	Active int    `json:"active"`
+	Shadow int    `json:"shadow"`
+}
+
+// ShadowReport summarizes how a shadow classifier's labels differ from the active ones.
+//
+// Fields:
+// - Classifier: The name of the shadow classifier.
+// - Compared: The number of stocks classified by both.
+// - Agreements: The number of stocks with exactly the same set of labels.
+// - AgreementRate: Agreements / Compared, between 0 and 1.
+// - Labels: Per-label counts, sorted by label.
+type ShadowReport struct {
+	Classifier    string            `json:"classifier"`
+	Compared      int               `json:"compared"`
+	Agreements    int               `json:"agreements"`
+	AgreementRate float64           `json:"agreement_rate"`
+	Labels        []LabelComparison `json:"labels"`
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,20 +15,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// tickerPattern matches valid tickers: uppercase letters and numbers only.
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
 // Stock represents the stock entity in the system.
 // It contains information about the stock's ticker, company, classifications, and other attributes.
 type Stock struct {
 	gorm.Model
-	Ticker          string      `gorm:"size:10;not null;index" json:"ticker"` // Stock ticker (e.g., "AAPL")
-	TargetFrom      string      `gorm:"size:20" json:"target_from"`           // Initial target price
-	TargetTo        string      `gorm:"size:20" json:"target_to"`             // Final target price
-	Company         string      `gorm:"size:255;not null" json:"company"`     // Company name
-	Action          string      `gorm:"size:100" json:"action"`               // Analyst action (e.g., "upgraded by")
-	Brokerage       string      `gorm:"size:255;not null" json:"brokerage"`   // Brokerage firm
-	RatingFrom      string      `gorm:"size:50" json:"rating_from"`           // Initial rating
-	RatingTo        string      `gorm:"size:50" json:"rating_to"`             // Final rating
-	Time            time.Time   `gorm:"not null;index" json:"time"`           // Timestamp of the stock event
-	Classifications StringArray `gorm:"type:text[]" json:"classifications"`   // Classifications for the stock
+	Ticker            string      `gorm:"size:10;not null;index" json:"ticker"`  // Stock ticker (e.g., "AAPL")
+	TargetFrom        string      `gorm:"size:20" json:"target_from"`            // Initial target price
+	TargetTo          string      `gorm:"size:20" json:"target_to"`              // Final target price
+	Company           string      `gorm:"size:255;not null" json:"company"`      // Company name
+	CompanyNormalized string      `gorm:"size:255;index" json:"-"`               // Searchable company name, see NormalizeCompany
+	Action            string      `gorm:"size:100" json:"action"`                // Analyst action (e.g., "upgraded by")
+	Brokerage         string      `gorm:"size:255;not null" json:"brokerage"`    // Brokerage firm
+	RatingFrom        string      `gorm:"size:50" json:"rating_from"`            // Initial rating
+	RatingTo          string      `gorm:"size:50" json:"rating_to"`              // Final rating
+	Time              time.Time   `gorm:"not null;index" json:"time"`            // Timestamp of the stock event
+	Classifications   StringArray `gorm:"type:text[]" json:"classifications"`    // Classifications for the stock
+	Warnings          StringArray `gorm:"type:text[]" json:"warnings,omitempty"` // Soft validation warnings, see CheckWarnings
 }
 
 func parseCurrencyToFloat(currencyStr string) (float64, error) {
@@ -75,11 +81,10 @@ func (sa *StringArray) Scan(value interface{}) error {
 }
 
 // Value implements the driver Valuer interface for database serialization.
-// It converts the StringArray into a database-compatible format.
+// It converts the StringArray into a database-compatible format. Labels default to
+// ["Neutral"] in the hooks of the models holding them, not here, so other arrays such as
+// warnings can be stored empty.
 func (sa StringArray) Value() (driver.Value, error) {
-	if len(sa) == 0 {
-		return pq.StringArray{"Neutral"}.Value() // Default value if empty
-	}
 	return pq.StringArray(sa).Value()
 }
 
@@ -112,12 +117,31 @@ func (s *Stock) BeforeCreate(_ *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave is a GORM hook that keeps the normalized company name in sync with the
+// company name on every create and update, and stores empty classifications as
+// ["Neutral"].
+func (s *Stock) BeforeSave(_ *gorm.DB) error {
+	s.CompanyNormalized = NormalizeCompany(s.Company)
+	if len(s.Classifications) == 0 {
+		s.Classifications = StringArray{NeutralLabel}
+	}
+	return nil
+}
+
+// Clone returns a copy of the stock sharing no classifications or warnings with it, so
+// either can be mutated without affecting the other.
+func (s *Stock) Clone() Stock {
+	c := *s
+	c.Classifications = slices.Clone(s.Classifications)
+	c.Warnings = slices.Clone(s.Warnings)
+	return c
+}
+
 // Validate performs custom validations for the Stock model.
 // It ensures the ticker format is valid and the time is not in the future.
 func (s *Stock) Validate() error {
 	// Validate ticker format (only uppercase letters and numbers)
-	matched, _ := regexp.MatchString(`^[A-Z0-9]+$`, s.Ticker)
-	if !matched {
+	if !tickerPattern.MatchString(s.Ticker) {
 		return fmt.Errorf("ticker must contain only uppercase letters and numbers")
 	}
 
@@ -136,3 +160,17 @@ type Recommendation struct {
 	Score     float64 `json:"score"`
 	Rationale string  `json:"rationale"`
 }
+
+// RecommendationSnapshotSize is the number of top recommendations kept in snapshots,
+// whatever the limit of the request they were computed for. Stale answers are cut down to
+// the limit requested.
+const RecommendationSnapshotSize = 50
+
+// RecommendationSnapshot is the last recommendation list computed, kept to answer
+// requests while the database is unavailable. WeightsVersion is the version of the
+// scoring weights the list was computed with, zero for the built-in weights.
+type RecommendationSnapshot struct {
+	GeneratedAt     time.Time        `json:"generated_at"`
+	WeightsVersion  int              `json:"weights_version,omitempty"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
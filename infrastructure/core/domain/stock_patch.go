@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents (RFC 7386).
+const MergePatchContentType = "application/merge-patch+json"
+
+// FieldChange describes the change of a single field by an update.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// patchableField describes how a JSON Merge Patch member maps onto a Stock field.
+// Required fields cannot be cleared with an explicit null.
+type patchableField struct {
+	required bool
+	get      func(s *Stock) interface{}
+	set      func(s *Stock, raw json.RawMessage) error
+}
+
+// stringField builds a patchableField for a string field.
+func stringField(required bool, field func(s *Stock) *string) patchableField {
+	return patchableField{
+		required: required,
+		get:      func(s *Stock) interface{} { return *field(s) },
+		set: func(s *Stock, raw json.RawMessage) error {
+			var value string
+			if raw != nil {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return err
+				}
+			}
+			if required && value == "" {
+				return errors.New("cannot be empty")
+			}
+			*field(s) = value
+			return nil
+		},
+	}
+}
+
+// patchableFields lists the Stock fields clients can change, keyed by their JSON name,
+// which is also their column name.
+var patchableFields = map[string]patchableField{
+	"ticker":      stringField(true, func(s *Stock) *string { return &s.Ticker }),
+	"target_from": stringField(false, func(s *Stock) *string { return &s.TargetFrom }),
+	"target_to":   stringField(false, func(s *Stock) *string { return &s.TargetTo }),
+	"company":     stringField(true, func(s *Stock) *string { return &s.Company }),
+	"action":      stringField(false, func(s *Stock) *string { return &s.Action }),
+	"brokerage":   stringField(true, func(s *Stock) *string { return &s.Brokerage }),
+	"rating_from": stringField(false, func(s *Stock) *string { return &s.RatingFrom }),
+	"rating_to":   stringField(false, func(s *Stock) *string { return &s.RatingTo }),
+	"time": {
+		required: true,
+		get:      func(s *Stock) interface{} { return s.Time.UTC() },
+		set: func(s *Stock, raw json.RawMessage) error {
+			var t time.Time
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return err
+			}
+			s.Time = t.UTC()
+			return nil
+		},
+	},
+	"classifications": {
+		get: func(s *Stock) interface{} { return []string(s.Classifications) },
+		set: func(s *Stock, raw json.RawMessage) error {
+			if raw == nil {
+				s.Classifications = nil
+				return nil
+			}
+			var labels []string
+			if err := json.Unmarshal(raw, &labels); err != nil {
+				return err
+			}
+			s.Classifications = labels
+			return nil
+		},
+	},
+}
+
+// ParseMergePatch decodes a JSON Merge Patch document into its members. An explicit
+// null is kept as a nil RawMessage, which is distinct from an omitted member.
+func ParseMergePatch(body []byte) (map[string]json.RawMessage, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidStock)
+	}
+	for field, raw := range patch {
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			patch[field] = nil
+		}
+	}
+	return patch, nil
+}
+
+// ApplyMergePatch applies a parsed JSON Merge Patch to stock, following RFC 7386:
+// omitted members are left untouched and null members clear the field.
+// Unknown members, nulls on required fields, and values of the wrong type are
+// rejected with ErrInvalidStock, leaving stock unchanged.
+func ApplyMergePatch(stock *Stock, patch map[string]json.RawMessage) error {
+	patched := *stock
+	for field, raw := range patch {
+		spec, ok := patchableFields[field]
+		if !ok {
+			return fmt.Errorf("%w: field %q cannot be patched", ErrInvalidStock, field)
+		}
+		if raw == nil && spec.required {
+			return fmt.Errorf("%w: field %q cannot be null", ErrInvalidStock, field)
+		}
+		if err := spec.set(&patched, raw); err != nil {
+			return fmt.Errorf("%w: invalid value for %q: %v", ErrInvalidStock, field, err)
+		}
+	}
+
+	*stock = patched
+	return nil
+}
+
+// DiffStocks lists the patchable fields whose value differs between before and after,
+// sorted by field name.
+func DiffStocks(before, after *Stock) []FieldChange {
+	fields := make([]string, 0, len(patchableFields))
+	for field := range patchableFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		get := patchableFields[field].get
+		from, to := get(before), get(after)
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	return changes
+}
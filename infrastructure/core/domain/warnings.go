@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"math"
+	"strings"
+)
+
+// Soft validation warnings. They flag stocks that are suspicious but not invalid: flagged
+// stocks are stored as usual, with the codes in Stock.Warnings, and counted in the report
+// of the ingestion run that wrote them.
+const (
+	WarningUnknownRating     = "unknown_rating"      // RatingFrom or RatingTo is not in the rating vocabulary
+	WarningLargeTargetChange = "large_target_change" // The target moves by more than MaxTargetChangePercent
+)
+
+// MaxTargetChangePercent is the largest target price change, in percent either way, not
+// flagged as suspicious.
+const MaxTargetChangePercent = 300
+
+// knownRatings is the rating vocabulary used by the brokerages, in lower case.
+var knownRatings = map[string]struct{}{
+	"strong-buy": {}, "strong buy": {}, "buy": {}, "moderate buy": {}, "speculative buy": {},
+	"accumulate": {}, "top pick": {}, "positive": {},
+	"outperform": {}, "market outperform": {}, "sector outperform": {}, "outperformer": {},
+	"overweight": {}, "add": {},
+	"neutral": {}, "hold": {}, "equal weight": {}, "equal-weight": {}, "in-line": {},
+	"market perform": {}, "sector perform": {}, "peer perform": {}, "sector weight": {},
+	"underperform": {}, "market underperform": {}, "sector underperform": {},
+	"underweight": {}, "reduce": {}, "negative": {}, "cautious": {},
+	"sell": {}, "strong sell": {}, "strong-sell": {},
+}
+
+// IsKnownRating reports whether rating belongs to the rating vocabulary, ignoring case.
+// An empty rating, as brokerages send when initiating coverage, is known.
+func IsKnownRating(rating string) bool {
+	rating = strings.TrimSpace(rating)
+	if rating == "" {
+		return true
+	}
+	_, ok := knownRatings[strings.ToLower(rating)]
+	return ok
+}
+
+// CheckWarnings returns the soft validation warnings the stock raises. Targets that
+// cannot be parsed are not flagged here; the sanity report covers them.
+func (s *Stock) CheckWarnings() StringArray {
+	var warnings StringArray
+	if !IsKnownRating(s.RatingFrom) || !IsKnownRating(s.RatingTo) {
+		warnings = append(warnings, WarningUnknownRating)
+	}
+	if change, err := s.GetUpside(); err == nil && math.Abs(change) > MaxTargetChangePercent {
+		warnings = append(warnings, WarningLargeTargetChange)
+	}
+	return warnings
+}
+
+// WarningTally counts the stocks raising soft validation warnings, for run reports.
+//
+// Fields:
+// - Flagged: The number of stocks raising at least one warning.
+// - Counts: The number of stocks raising each warning.
+type WarningTally struct {
+	Flagged int            `json:"flagged"`
+	Counts  map[string]int `json:"counts,omitempty"`
+}
+
+// Add counts the warnings of stocks.
+func (t *WarningTally) Add(stocks ...*Stock) {
+	for _, stock := range stocks {
+		if len(stock.Warnings) == 0 {
+			continue
+		}
+		if t.Counts == nil {
+			t.Counts = make(map[string]int)
+		}
+		t.Flagged++
+		for _, warning := range stock.Warnings {
+			t.Counts[warning]++
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MaxWebhookTopics caps the number of tickers plus classifications of a subscription.
+const MaxWebhookTopics = 100
+
+// WebhookSubscription asks for stock events matching any of its tickers or
+// classifications to be POSTed to URL. Deliveries are signed with Secret, which is
+// generated by the server and only disclosed when the subscription is created.
+type WebhookSubscription struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	URL             string         `gorm:"size:2048;not null" json:"url"`       // Endpoint receiving the events
+	Secret          string         `gorm:"size:128;not null" json:"-"`          // HMAC key used to sign deliveries
+	Tickers         pq.StringArray `gorm:"type:text[]" json:"tickers"`          // Tickers to notify about
+	Classifications pq.StringArray `gorm:"type:text[]" json:"classifications"`  // Classifications to notify about
+	Active          bool           `gorm:"not null;default:true" json:"active"` // Inactive subscriptions receive nothing
+	CreatedAt       time.Time      `gorm:"not null" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"not null" json:"updated_at"`
+}
+
+// Validate checks the URL and topics of the subscription and normalizes tickers to
+// upper case. Errors wrap ErrInvalidWebhook.
+func (w *WebhookSubscription) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+
+	topics := len(w.Tickers) + len(w.Classifications)
+	if topics == 0 {
+		return fmt.Errorf("%w: at least one ticker or classification is required", ErrInvalidWebhook)
+	}
+	if topics > MaxWebhookTopics {
+		return fmt.Errorf("%w: at most %d tickers and classifications are allowed", ErrInvalidWebhook, MaxWebhookTopics)
+	}
+
+	for i, ticker := range w.Tickers {
+		w.Tickers[i] = strings.ToUpper(strings.TrimSpace(ticker))
+	}
+	return nil
+}
+
+// Matches reports whether stock has one of the subscribed tickers or classifications.
+func (w *WebhookSubscription) Matches(stock *Stock) bool {
+	for _, ticker := range w.Tickers {
+		if ticker == stock.Ticker {
+			return true
+		}
+	}
+	for _, label := range w.Classifications {
+		for _, classification := range stock.Classifications {
+			if label == classification {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WebhookDelivery logs the outcome of delivering one event to one subscription.
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	SubscriptionID uint           `gorm:"not null;index" json:"subscription_id"`
+	Event          string         `gorm:"size:50;not null" json:"event"`
+	Tickers        pq.StringArray `gorm:"type:text[]" json:"tickers"`       // Tickers included in the payload
+	Attempts       int            `gorm:"not null" json:"attempts"`         // Number of delivery attempts
+	StatusCode     int            `json:"status_code,omitempty"`            // Last HTTP status returned by the subscriber
+	Error          string         `gorm:"type:text" json:"error,omitempty"` // Last error, if the delivery failed
+	Delivered      bool           `gorm:"not null" json:"delivered"`
+	CreatedAt      time.Time      `gorm:"not null;index" json:"created_at"`
+}
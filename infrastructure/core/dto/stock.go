@@ -0,0 +1,86 @@
+// Package dto defines the representations of stocks exchanged with clients: the stocks
+// accepted on writes, the stocks returned by every endpoint and the webhook deliveries.
+// They decouple payloads from the gorm.Model embedded in domain.Stock. The response
+// package wraps them in its envelopes; the core builds them without depending on it.
+package dto
+
+import (
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// StockItem is the representation of a stock event returned to clients.
+// ID is omitted for stocks that have not been stored.
+type StockItem struct {
+	ID              uint     `json:"id,omitempty"`
+	Ticker          string   `json:"ticker"`
+	TargetFrom      string   `json:"target_from"`
+	TargetTo        string   `json:"target_to"`
+	Company         string   `json:"company"`
+	Action          string   `json:"action"`
+	Brokerage       string   `json:"brokerage"`
+	RatingFrom      string   `json:"rating_from"`
+	RatingTo        string   `json:"rating_to"`
+	Time            string   `json:"time"`
+	Classifications []string `json:"classifications"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// FromStock maps a domain stock to its client representation.
+func FromStock(stock *domain.Stock) StockItem {
+	return StockItem{
+		ID:              stock.ID,
+		Ticker:          stock.Ticker,
+		TargetFrom:      stock.TargetFrom,
+		TargetTo:        stock.TargetTo,
+		Company:         stock.Company,
+		Action:          stock.Action,
+		Brokerage:       stock.Brokerage,
+		RatingFrom:      stock.RatingFrom,
+		RatingTo:        stock.RatingTo,
+		Time:            stock.Time.Format(time.RFC3339),
+		Classifications: stock.Classifications,
+		Warnings:        stock.Warnings,
+	}
+}
+
+// FromStocks maps a slice of domain stocks to their client representation.
+func FromStocks(stocks []domain.Stock) []StockItem {
+	items := make([]StockItem, len(stocks))
+	for i := range stocks {
+		items[i] = FromStock(&stocks[i])
+	}
+	return items
+}
+
+// StockInput is the payload accepted when creating stocks. Only business fields are
+// accepted; persistence fields (ID, timestamps) are always set by the server.
+type StockInput struct {
+	Ticker          string    `json:"ticker" binding:"required"`
+	TargetFrom      string    `json:"target_from"`
+	TargetTo        string    `json:"target_to"`
+	Company         string    `json:"company" binding:"required"`
+	Action          string    `json:"action"`
+	Brokerage       string    `json:"brokerage" binding:"required"`
+	RatingFrom      string    `json:"rating_from"`
+	RatingTo        string    `json:"rating_to"`
+	Time            time.Time `json:"time" binding:"required"`
+	Classifications []string  `json:"classifications"`
+}
+
+// ToDomain converts the payload into a new, unsaved domain stock.
+func (in *StockInput) ToDomain() *domain.Stock {
+	return &domain.Stock{
+		Ticker:          in.Ticker,
+		TargetFrom:      in.TargetFrom,
+		TargetTo:        in.TargetTo,
+		Company:         in.Company,
+		Action:          in.Action,
+		Brokerage:       in.Brokerage,
+		RatingFrom:      in.RatingFrom,
+		RatingTo:        in.RatingTo,
+		Time:            in.Time,
+		Classifications: in.Classifications,
+	}
+}
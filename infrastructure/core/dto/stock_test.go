@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestStockInput(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should map the business fields to a new stock", func(t *testing.T) {
+		in := StockInput{
+			Ticker:          "AAPL",
+			TargetFrom:      "$100.00",
+			TargetTo:        "$120.00",
+			Company:         "Apple Inc.",
+			Action:          "upgraded by",
+			Brokerage:       "Acme",
+			RatingFrom:      "Hold",
+			RatingTo:        "Buy",
+			Time:            now,
+			Classifications: []string{"Tech"},
+		}
+
+		assert.Equal(t, &domain.Stock{
+			Ticker:          "AAPL",
+			TargetFrom:      "$100.00",
+			TargetTo:        "$120.00",
+			Company:         "Apple Inc.",
+			Action:          "upgraded by",
+			Brokerage:       "Acme",
+			RatingFrom:      "Hold",
+			RatingTo:        "Buy",
+			Time:            now,
+			Classifications: domain.StringArray{"Tech"},
+		}, in.ToDomain())
+	})
+
+	t.Run("should ignore persistence fields sent by clients", func(t *testing.T) {
+		var in StockInput
+		body := `{"ID": 7, "CreatedAt": "2020-01-01T00:00:00Z", "ticker": "AAPL", "time": "2025-03-01T12:00:00Z"}`
+		assert.NoError(t, json.Unmarshal([]byte(body), &in))
+
+		stock := in.ToDomain()
+
+		assert.Zero(t, stock.ID)
+		assert.Zero(t, stock.CreatedAt)
+		assert.Equal(t, "AAPL", stock.Ticker)
+		assert.Equal(t, now, stock.Time)
+	})
+}
+
+func TestFromStock(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	stock := domain.Stock{
+		Ticker:          "AAPL",
+		Company:         "Apple Inc.",
+		RatingTo:        "Buy",
+		Time:            now,
+		Classifications: domain.StringArray{"Tech"},
+	}
+
+	t.Run("should map a stored stock with its ID", func(t *testing.T) {
+		stored := stock
+		stored.ID = 7
+
+		item := FromStock(&stored)
+
+		assert.Equal(t, uint(7), item.ID)
+		assert.Equal(t, "AAPL", item.Ticker)
+		assert.Equal(t, "2025-03-01T12:00:00Z", item.Time)
+		assert.Equal(t, []string{"Tech"}, item.Classifications)
+	})
+
+	t.Run("should omit the ID of unsaved stocks", func(t *testing.T) {
+		b, err := json.Marshal(FromStocks([]domain.Stock{stock}))
+
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), `"id"`)
+	})
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// WebhookPayload is the JSON body POSTed to webhook subscribers.
+type WebhookPayload struct {
+	Event          string      `json:"event"` // e.g., "stock.batch"
+	SubscriptionID uint        `json:"subscription_id"`
+	Stocks         []StockItem `json:"stocks"` // The written stocks matching the subscription
+	OccurredAt     time.Time   `json:"occurred_at"`
+}
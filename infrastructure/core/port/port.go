@@ -2,6 +2,8 @@ package port
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"stock-api/infrastructure/core/domain"
 )
@@ -12,21 +14,46 @@ type StockRepository interface {
 	Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error)
 	FindAll(ctx context.Context, order string, page, limit int) ([]domain.Stock, error)
 	FindByTicker(ctx context.Context, ticker string) (*domain.Stock, error)
+	FindLatestByTicker(ctx context.Context, ticker string) (*domain.Stock, error)
+	FindByID(ctx context.Context, id uint) (*domain.Stock, error)
+	Update(ctx context.Context, stock *domain.Stock, columns []string) error
+	// Deprecated: Use FindByClassificationPaginated, which bounds the result size.
 	FindByClassification(ctx context.Context, classification string) ([]domain.Stock, error)
+	FindByClassificationPaginated(ctx context.Context, classification string, pagination domain.PaginationParams) ([]domain.Stock, error)
+	CountByClassification(ctx context.Context, classification string) (int, error)
 	SaveBatch(ctx context.Context, data []*domain.Stock) error
 	Count(ctx context.Context, filters domain.Filters) (int, error)
+	EstimateCount(ctx context.Context, filters domain.Filters) (count int, approximate bool, err error)
+}
+
+// StockStore is a complete stock storage backend: the repository together with the
+// optional capabilities the application relies on. Both the SQL store and the in-memory
+// store of sandbox mode implement it.
+type StockStore interface {
+	StockRepository
+	StockHistoryReader
+	StockSampler
+	ClassificationUpdater
+	CachePurger
+	AsOfReader
 }
 
 type FieldValidator interface {
 	IsValidField(field string) bool
+	IsVirtualField(field string) bool
 	GetAllValidFields() []string
 }
 
 type StockService interface {
 	RegisterStock(ctx context.Context, stock *domain.Stock) error
+	RegisterStocks(ctx context.Context, stocks []*domain.Stock) error
+	UpdateStock(ctx context.Context, id uint, patch map[string]json.RawMessage) (*domain.Stock, []domain.FieldChange, error)
 	FindStockByTicker(ctx context.Context, ticker string) (*domain.Stock, error)
+	FindLatestStockByTicker(ctx context.Context, ticker string) (*domain.Stock, error)
 	DeleteStock(ctx context.Context, stock *domain.Stock, id uint) error
 	Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, int, error)
+	FindPage(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters, opts domain.QueryOptions) (domain.StockPage, error)
+	FindByClassification(ctx context.Context, classification string, pagination domain.PaginationParams) (domain.StockPage, error)
 	FindAllStocks(ctx context.Context, order string, page int, limit int) ([]domain.Stock, error)
 }
 
@@ -37,8 +64,149 @@ type ClassificationService interface {
 
 type BestInvestmentsService interface {
 	GetStockRecommendations(batch []domain.Stock, limit int) []domain.Recommendation
+	GetScoreBreakdown(stock domain.Stock) domain.ScoreBreakdown
 }
 
 type APIClient interface {
 	FetchStocks(ctx context.Context, jwtToken string, lastTicker string) ([]*domain.Stock, string, error)
 }
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, page, pageSize int) ([]domain.AuditEntry, error)
+}
+
+// CachedCounter is implemented by repositories that cache counts and can report when a
+// cached count was computed. A zero cachedAt means the count is fresh.
+type CachedCounter interface {
+	CountCached(ctx context.Context, filters domain.Filters) (count int, cachedAt time.Time, err error)
+}
+
+// StockHistoryReader is implemented by repositories that can read the recent events of
+// several tickers in a single query, so history-based classifiers avoid N+1 lookups.
+type StockHistoryReader interface {
+	FindRecentByTickers(ctx context.Context, tickers []string, since time.Time) ([]domain.Stock, error)
+}
+
+// SchemaInspector reports whether the database is reachable and its migration state.
+type SchemaInspector interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (domain.SchemaVersion, error)
+}
+
+// StockSampler is implemented by repositories that can return a random sample of stocks.
+type StockSampler interface {
+	Sample(ctx context.Context, n int) ([]domain.Stock, error)
+}
+
+// ClassificationUpdater is implemented by repositories that can rewrite the
+// classifications of several stocks at once, as reclassification jobs do.
+type ClassificationUpdater interface {
+	UpdateClassifications(ctx context.Context, stocks []*domain.Stock) error
+}
+
+// AsOfReader is implemented by repositories that can read the stocks as they stood at a
+// past time: those stored by then and not deleted yet, including those deleted since,
+// with the values they had then, kept as revisions when they are updated in place.
+// The methods mirror those of StockRepository; past views are neither cached nor coalesced.
+type AsOfReader interface {
+	FindAsOf(ctx context.Context, at time.Time, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error)
+	CountAsOf(ctx context.Context, at time.Time, filters domain.Filters) (int, error)
+	FindAllAsOf(ctx context.Context, at time.Time, order string, page, limit int) ([]domain.Stock, error)
+	FindLatestByTickerAsOf(ctx context.Context, at time.Time, ticker string) (*domain.Stock, error)
+}
+
+// CachePurger is implemented by components holding caches that admins can flush.
+type CachePurger interface {
+	PurgeCache()
+}
+
+// RecommendationSnapshotStore keeps the latest recommendation snapshot across restarts.
+// Saving the list already stored keeps the current snapshot, with its generation time.
+// Load reports false when no snapshot was saved yet.
+type RecommendationSnapshotStore interface {
+	Save(ctx context.Context, snapshot domain.RecommendationSnapshot) error
+	Load(ctx context.Context) (domain.RecommendationSnapshot, bool, error)
+}
+
+// ScoreHistoryRepository stores the scores computed after each ingestion run, so clients
+// can chart how a ticker's score evolved. ListByTicker returns the latest limit entries
+// computed within [from, to], oldest first; a zero from or to leaves that end open.
+type ScoreHistoryRepository interface {
+	SaveBatch(ctx context.Context, entries []*domain.ScoreHistoryEntry) error
+	ListByTicker(ctx context.Context, ticker string, from, to time.Time, limit int) ([]domain.ScoreHistoryEntry, error)
+}
+
+// ScoreRecorder records the scores of the stocks written by an ingestion run.
+type ScoreRecorder interface {
+	RecordScores(ctx context.Context, stocks []domain.Stock, computedAt time.Time) error
+}
+
+// ScoringWeightRepository stores the changes to the scoring weights, each tagged with the
+// version of the weights it produced. ListChanges returns the changes up to version, in the
+// order they were made; a zero version returns every change. Append stores changes as a
+// single new version and returns it.
+type ScoringWeightRepository interface {
+	ListChanges(ctx context.Context, version int) ([]domain.ScoringWeight, error)
+	Append(ctx context.Context, changes []*domain.ScoringWeight) (int, error)
+}
+
+// ScoringWeightsManager reads and changes the versioned scoring weights. A zero version
+// stands for the weights in effect.
+type ScoringWeightsManager interface {
+	GetWeights(ctx context.Context, version int) (domain.ScoringWeights, error)
+	ListChanges(ctx context.Context) ([]domain.ScoringWeight, error)
+	Apply(ctx context.Context, actor string, changes ...*domain.ScoringWeight) (domain.ScoringWeights, error)
+}
+
+// ScoringWeightsProvider supplies the scoring weights in effect.
+type ScoringWeightsProvider interface {
+	CurrentScoringWeights() domain.ScoringWeights
+}
+
+// ScoringWeightsHistory is implemented by scoring weight providers that can rebuild the
+// weights in effect at a past time.
+type ScoringWeightsHistory interface {
+	ScoringWeightsAsOf(ctx context.Context, at time.Time) (domain.ScoringWeights, error)
+}
+
+// PointInTimeScorer is implemented by recommendation services that can score stocks as
+// they would have been scored at a past time, with the weights in effect then and the
+// freshness measured then, so recommendations can be backtested.
+type PointInTimeScorer interface {
+	GetRecommendationsAsOf(ctx context.Context, stocks []domain.Stock, limit int, at time.Time) ([]domain.Recommendation, int, error)
+	GetScoreBreakdownAsOf(ctx context.Context, stock domain.Stock, at time.Time) (domain.ScoreBreakdown, error)
+}
+
+// VersionedRecommender is implemented by recommendation services scoring with versioned
+// weights. It returns the recommendations together with the version of the weights used,
+// so snapshots can be reproduced.
+type VersionedRecommender interface {
+	GetVersionedRecommendations(stocks []domain.Stock, limit int) ([]domain.Recommendation, int)
+}
+
+// ReclassifyJobManager starts reclassification jobs and reports their progress.
+type ReclassifyJobManager interface {
+	Start(ctx context.Context, filters domain.Filters, actor string) (domain.ReclassifyJob, error)
+	Job(id string) (domain.ReclassifyJob, error)
+	Jobs() []domain.ReclassifyJob
+}
+
+type IngestionRunner interface {
+	ProcessStocks(ctx context.Context) error
+}
+
+type ShadowClassificationRepository interface {
+	SaveBatch(ctx context.Context, data []*domain.ShadowClassification) error
+	Report(ctx context.Context, classifier string) (domain.ShadowReport, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	FindSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, activeOnly bool) ([]domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uint) error
+	RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID uint, page, pageSize int) ([]domain.WebhookDelivery, error)
+}
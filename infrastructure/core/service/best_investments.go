@@ -1,18 +1,59 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
 )
 
-type BestInvestmentsServiceImpl struct{}
+type BestInvestmentsServiceImpl struct {
+	scores  *ScoreCache
+	weights port.ScoringWeightsProvider
+}
 
+// NewBestInvestmentsService creates a new instance of BestInvestmentsServiceImpl
+// with its own score cache.
 func NewBestInvestmentsService() *BestInvestmentsServiceImpl {
-	return &BestInvestmentsServiceImpl{}
+	return &BestInvestmentsServiceImpl{scores: NewScoreCache(defaultScoreCacheSize)}
+}
+
+// WithScoringWeights scores stocks with the weights supplied by provider instead of the
+// built-in ClassificationPoints, so they can be tuned at runtime.
+func (s *BestInvestmentsServiceImpl) WithScoringWeights(provider port.ScoringWeightsProvider) *BestInvestmentsServiceImpl {
+	s.weights = provider
+	return s
+}
+
+// scoringWeights returns the weights in effect. The points must not be modified.
+func (s *BestInvestmentsServiceImpl) scoringWeights() domain.ScoringWeights {
+	if s.weights == nil {
+		return domain.ScoringWeights{Points: domain.ClassificationPoints}
+	}
+	return s.weights.CurrentScoringWeights()
+}
+
+// scoredStock pairs a stock with its score while ranking recommendations.
+type scoredStock struct {
+	stock *domain.Stock
+	score float64
+}
+
+// InvalidateScores drops the cached scores of the stocks with the given IDs.
+// It is meant to be called when those stock rows change.
+func (s *BestInvestmentsServiceImpl) InvalidateScores(ids ...uint) {
+	s.scores.Invalidate(ids...)
+}
+
+// PurgeCache drops every cached score.
+func (s *BestInvestmentsServiceImpl) PurgeCache() {
+	s.scores.Purge()
 }
 
 // GetStockRecommendations generates a list of stock recommendations based on their scores.
@@ -28,32 +69,99 @@ func NewBestInvestmentsService() *BestInvestmentsServiceImpl {
 //
 // The function performs the following steps:
 //  1. Filters the input stocks using the filterStocks function.
-//  2. Sorts the filtered stocks in descending order based on their calculated scores.
+//  2. Sorts the filtered stocks in descending order based on their scores, computed once per
+//     stock and served from the score cache when the stock's content has been scored before.
 //  3. Limits the number of recommendations to the specified limit or the total number of filtered stocks.
 //  4. Constructs and returns a slice of Recommendation objects, including the position, ticker, company name,
 //     score, and rationale for each recommended stock.
 func (s *BestInvestmentsServiceImpl) GetStockRecommendations(stocks []domain.Stock, limit int) []domain.Recommendation {
-	// Filter and sort
+	recommendations, _ := s.GetVersionedRecommendations(stocks, limit)
+	return recommendations
+}
+
+// GetVersionedRecommendations generates recommendations like GetStockRecommendations, and
+// returns the version of the scoring weights they were computed with.
+func (s *BestInvestmentsServiceImpl) GetVersionedRecommendations(stocks []domain.Stock, limit int) ([]domain.Recommendation, int) {
+	weights := s.scoringWeights()
+	score := strategies[StrategyScore]
+
+	// Scores are cached across requests
+	return rankRecommendations(stocks, limit, func(stock *domain.Stock) float64 {
+		return s.scores.Score(stock, weights.Version, func(stock domain.Stock) float64 {
+			return score(stock, weights.Points)
+		})
+	}), weights.Version
+}
+
+// GetRecommendationsAsOf generates recommendations like GetVersionedRecommendations, as
+// they would have been generated at at: with the weights in effect then.
+func (s *BestInvestmentsServiceImpl) GetRecommendationsAsOf(ctx context.Context, stocks []domain.Stock, limit int, at time.Time) ([]domain.Recommendation, int, error) {
+	weights, err := s.scoringWeightsAsOf(ctx, at)
+	if err != nil {
+		return nil, 0, err
+	}
+	score := strategies[StrategyScore]
+
+	return rankRecommendations(stocks, limit, func(stock *domain.Stock) float64 {
+		return s.scores.Score(stock, weights.Version, func(stock domain.Stock) float64 {
+			return score(stock, weights.Points)
+		})
+	}), weights.Version, nil
+}
+
+// scoringWeightsAsOf returns the weights in effect at at. Providers that keep no history
+// of the weights are assumed to have kept them unchanged.
+func (s *BestInvestmentsServiceImpl) scoringWeightsAsOf(ctx context.Context, at time.Time) (domain.ScoringWeights, error) {
+	history, ok := s.weights.(port.ScoringWeightsHistory)
+	if !ok {
+		return s.scoringWeights(), nil
+	}
+	return history.ScoringWeightsAsOf(ctx, at)
+}
+
+// GetStockRecommendationsWithStrategy generates recommendations like GetStockRecommendations,
+// ranking the stocks with the named strategy (see RecommendationStrategies).
+// It returns an error if the strategy is unknown.
+func (s *BestInvestmentsServiceImpl) GetStockRecommendationsWithStrategy(stocks []domain.Stock, limit int, strategy string) ([]domain.Recommendation, error) {
+	if strategy == StrategyScore {
+		return s.GetStockRecommendations(stocks, limit), nil
+	}
+	score, ok := strategies[strategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown recommendation strategy: %s (available: %v)", strategy, RecommendationStrategies())
+	}
+	points := s.scoringWeights().Points
+	return rankRecommendations(stocks, limit, func(stock *domain.Stock) float64 { return score(*stock, points) }), nil
+}
+
+// rankRecommendations filters the stocks, scores each one once, and returns the top limit.
+func rankRecommendations(stocks []domain.Stock, limit int, score func(*domain.Stock) float64) []domain.Recommendation {
 	filtered := filterStocks(stocks)
-	sort.Slice(filtered, func(i, j int) bool {
-		return calculateScore(filtered[i]) > calculateScore(filtered[j])
+	ranked := make([]scoredStock, len(filtered))
+	for i := range filtered {
+		ranked[i] = scoredStock{stock: &filtered[i], score: score(&filtered[i])}
+	}
+
+	// Sort by score
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
 	})
 
 	// Limit results
-	if limit > len(filtered) {
-		limit = len(filtered)
+	if limit > len(ranked) {
+		limit = len(ranked)
 	}
 
 	// Prepare response
 	recommendations := make([]domain.Recommendation, limit)
 	for i := 0; i < limit; i++ {
-		stock := filtered[i]
+		stock := ranked[i].stock
 		recommendations[i] = domain.Recommendation{
 			Position:  i + 1,
 			Ticker:    stock.Ticker,
 			Company:   stock.Company,
-			Score:     calculateScore(stock),
-			Rationale: getRationale(stock),
+			Score:     ranked[i].score,
+			Rationale: getRationale(*stock),
 		}
 	}
 
@@ -71,74 +179,86 @@ func filterStocks(stocks []domain.Stock) []domain.Stock {
 }
 
 // isRecommended determines if a stock is recommended based on its classifications.
-// It excludes stocks with problematic classifications.
+// It excludes stocks with problematic classifications (those carrying risk points).
 func isRecommended(stock domain.Stock) bool {
 	// Exclude problematic stocks
 	for _, classification := range stock.Classifications {
-		switch classification {
-		case "High-Risk Speculative", "Bearish Signal", "Analyst Negative":
+		if domain.RiskPoints[classification] > 0 {
 			return false
 		}
 	}
 	return true
 }
 
-// calculateScore calculates the score of a stock based on various factors.
-// The score is determined by growth potential, positive classifications, and analyst ratings.
-func calculateScore(stock domain.Stock) float64 {
-	score := 0.0
+// GetScoreBreakdown returns the composite score of a single stock together with its
+// components, risk score, and freshness, without ranking any other stock.
+func (s *BestInvestmentsServiceImpl) GetScoreBreakdown(stock domain.Stock) domain.ScoreBreakdown {
+	return scoreBreakdown(stock, s.scoringWeights().Points, time.Now())
+}
 
-	// 1. Growth potential (50% weight)
-	upside, err := stock.GetUpside()
+// GetScoreBreakdownAsOf returns the score breakdown of a stock as it would have been
+// computed at at: with the weights in effect then, and the freshness measured then.
+func (s *BestInvestmentsServiceImpl) GetScoreBreakdownAsOf(ctx context.Context, stock domain.Stock, at time.Time) (domain.ScoreBreakdown, error) {
+	weights, err := s.scoringWeightsAsOf(ctx, at)
 	if err != nil {
-		fmt.Println("Error:", err)
-		panic("Error")
+		return domain.ScoreBreakdown{}, err
+	}
+	return scoreBreakdown(stock, weights.Points, at), nil
+}
+
+// scoreBreakdown computes the score components of a stock as of now, from its growth
+// potential, the given points of its classifications, and its analyst rating.
+// Stocks whose targets cannot be parsed earn no upside points.
+func scoreBreakdown(stock domain.Stock, points map[string]float64, now time.Time) domain.ScoreBreakdown {
+	b := domain.ScoreBreakdown{
+		Ticker:          stock.Ticker,
+		Company:         stock.Company,
+		Classifications: []domain.ClassificationContribution{},
+		Recommended:     isRecommended(stock),
+		EventTime:       stock.Time,
 	}
 
-	score += minFloat(upside*2, 100) // Maximum 100 points
+	// 1. Growth potential (50% weight)
+	if upside, err := stock.GetUpside(); err == nil {
+		b.Upside = &upside
+		b.UpsidePoints = minFloat(upside*2, domain.MaxUpsidePoints) // Maximum 100 points
+	}
 
 	// 2. Positive classifications (30%)
+	classificationPoints := 0.0
 	for _, classification := range stock.Classifications {
-		switch classification {
-		case "Potential Growth":
-			score += 30
-		case "Bullish Signal":
-			score += 25
-		case "New Coverage":
-			score += 20
-		case "Analyst Positive":
-			score += 15
-		case "Tech":
-			score += 10
-		case "Biotech":
-			score += 8
+		if weight, ok := points[classification]; ok {
+			b.Classifications = append(b.Classifications, domain.ClassificationContribution{Label: classification, Points: weight})
+			classificationPoints += weight
 		}
+		b.RiskScore += domain.RiskPoints[classification]
 	}
+	b.RiskScore = minFloat(b.RiskScore, domain.MaxRiskScore)
 
 	// 3. Analyst ratings (20%)
-	switch stock.RatingTo {
-	case "Strong-Buy":
-		score += 40
-	case "Outperform":
-		score += 30
-	case "Buy":
-		score += 20
+	b.RatingPoints = domain.RatingPoints[stock.RatingTo]
+
+	b.Score = b.UpsidePoints + classificationPoints + b.RatingPoints
+
+	// Freshness of the analyst event
+	if !stock.Time.IsZero() {
+		age := now.Sub(stock.Time)
+		if age < 0 {
+			age = 0
+		}
+		b.AgeHours = age.Hours()
+		b.Freshness = math.Max(0, 1-float64(age)/float64(domain.FreshnessHorizon))
 	}
 
-	return score
+	return b
 }
 
 // getRationale generates a rationale for recommending a stock based on its attributes.
+// The upside is left out when the targets cannot be parsed.
 func getRationale(stock domain.Stock) string {
 	reasons := []string{}
 
-	upside, err := stock.GetUpside()
-	if err != nil {
-		fmt.Println("Error:", err)
-		panic("Error")
-	}
-
-	if upside > 10 {
+	if upside, err := stock.GetUpside(); err == nil && upside > 10 {
 		reasons = append(reasons,
 			"Potential of "+strconv.FormatFloat(upside, 'f', 1, 64)+"%")
 	}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"stock-api/infrastructure/core/domain"
 )
@@ -70,4 +73,142 @@ func TestGetStockRecommendations(t *testing.T) {
 		assert.Contains(t, recommendations[0].Rationale, "Potential of 15.0%")
 		assert.Contains(t, recommendations[0].Rationale, "Recent upgrade")
 	})
+
+	t.Run("should recommend stocks with unparsable targets without their upside", func(t *testing.T) {
+		stocks := []domain.Stock{{
+			Ticker:          "NVDA",
+			Classifications: []string{"Bullish Signal"},
+			RatingTo:        "Buy",
+			TargetFrom:      "N/A",
+			TargetTo:        "$500.00",
+		}}
+
+		recommendations := service.GetStockRecommendations(stocks, 1)
+
+		require.Len(t, recommendations, 1)
+		assert.Equal(t, "Recent upgrade", recommendations[0].Rationale)
+	})
+
+	t.Run("should exclude stocks with a deteriorating outlook", func(t *testing.T) {
+		stocks := []domain.Stock{{
+			Ticker:          "INTC",
+			Classifications: []string{"Bullish Signal", "Deteriorating Outlook"},
+			RatingTo:        "Buy",
+			TargetFrom:      "$30.00",
+			TargetTo:        "$36.00",
+		}}
+
+		recommendations := service.GetStockRecommendations(stocks, 1)
+		b := scoreBreakdown(stocks[0], domain.ClassificationPoints, time.Now())
+
+		assert.Empty(t, recommendations)
+		assert.Equal(t, domain.RiskPoints["Deteriorating Outlook"], b.RiskScore)
+		assert.False(t, b.Recommended)
+	})
+}
+
+func TestGetScoreBreakdown(t *testing.T) {
+	now := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should detail the components of the score", func(t *testing.T) {
+		stock := domain.Stock{
+			Ticker:          "AAPL",
+			Classifications: []string{"Potential Growth", "Bullish Signal", "Other Sector"},
+			RatingTo:        "Strong-Buy",
+			TargetFrom:      "$100.00",
+			TargetTo:        "$115.00",
+			Time:            now.Add(-15 * 24 * time.Hour),
+		}
+
+		b := scoreBreakdown(stock, domain.ClassificationPoints, now)
+
+		assert.InDelta(t, 15.0, *b.Upside, 0.001)
+		assert.InDelta(t, 30.0, b.UpsidePoints, 0.001)
+		assert.Equal(t, 40.0, b.RatingPoints)
+		assert.Equal(t, []domain.ClassificationContribution{
+			{Label: "Potential Growth", Points: 30},
+			{Label: "Bullish Signal", Points: 25},
+		}, b.Classifications)
+		assert.InDelta(t, 125.0, b.Score, 0.001)
+		assert.InDelta(t, scoreBreakdown(stock, domain.ClassificationPoints, now).Score, b.Score, 0.001)
+		assert.Equal(t, 0.0, b.RiskScore)
+		assert.True(t, b.Recommended)
+		assert.InDelta(t, 0.5, b.Freshness, 0.001)
+	})
+
+	t.Run("should report risk and tolerate unparsable targets", func(t *testing.T) {
+		stock := domain.Stock{
+			Ticker:          "TSLA",
+			Classifications: []string{"High-Risk Speculative", "Bearish Signal", "Analyst Negative"},
+			TargetFrom:      "n/a",
+			TargetTo:        "$240.00",
+			Time:            now.Add(-60 * 24 * time.Hour),
+		}
+
+		b := scoreBreakdown(stock, domain.ClassificationPoints, now)
+
+		assert.Nil(t, b.Upside)
+		assert.Equal(t, 0.0, b.Score)
+		assert.Equal(t, float64(domain.MaxRiskScore), b.RiskScore)
+		assert.False(t, b.Recommended)
+		assert.Equal(t, 0.0, b.Freshness)
+	})
+}
+
+func TestGetScoreBreakdownAsOf(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	weights := NewScoringWeightsService(&fakeScoringWeightRepository{}, time.Minute)
+	weights.now = func() time.Time { return start }
+	_, err := weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Tech", Points: 12})
+	require.NoError(t, err)
+	weights.now = func() time.Time { return start.Add(24 * time.Hour) }
+	_, err = weights.Apply(ctx, "bob", &domain.ScoringWeight{Label: "Tech", Points: 20})
+	require.NoError(t, err)
+
+	s := NewBestInvestmentsService().WithScoringWeights(weights)
+	stock := domain.Stock{Ticker: "AAPL", Classifications: []string{"Tech"}, TargetFrom: "$100.00", TargetTo: "$100.00", Time: start}
+
+	b, err := s.GetScoreBreakdownAsOf(ctx, stock, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, b.Score)
+	assert.InDelta(t, 1.0, b.AgeHours, 0.001)
+
+	recommendations, version, err := s.GetRecommendationsAsOf(ctx, []domain.Stock{stock}, 1, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, 12.0, recommendations[0].Score)
+
+	current, _ := s.GetVersionedRecommendations([]domain.Stock{stock}, 1)
+	assert.Equal(t, 20.0, current[0].Score)
+}
+
+func TestGetStockRecommendationsWithStrategy(t *testing.T) {
+	service := NewBestInvestmentsService()
+	now := time.Now()
+
+	stocks := []domain.Stock{
+		{Ticker: "OLD", Classifications: []string{"Potential Growth"}, RatingTo: "Buy", TargetFrom: "$100.00", TargetTo: "$140.00", Time: now.Add(-25 * 24 * time.Hour)},
+		{Ticker: "NEW", Classifications: []string{"Potential Growth"}, RatingTo: "Buy", TargetFrom: "$100.00", TargetTo: "$120.00", Time: now.Add(-time.Hour)},
+	}
+
+	t.Run("should rank by composite score", func(t *testing.T) {
+		recommendations, err := service.GetStockRecommendationsWithStrategy(stocks, 2, StrategyScore)
+		assert.NoError(t, err)
+		assert.Equal(t, "OLD", recommendations[0].Ticker)
+	})
+
+	t.Run("should rank fresh events first", func(t *testing.T) {
+		recommendations, err := service.GetStockRecommendationsWithStrategy(stocks, 2, StrategyFresh)
+		assert.NoError(t, err)
+		assert.Equal(t, "NEW", recommendations[0].Ticker)
+		assert.Equal(t, 2, recommendations[1].Position)
+	})
+
+	t.Run("should reject unknown strategies", func(t *testing.T) {
+		_, err := service.GetStockRecommendationsWithStrategy(stocks, 2, "random")
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"stock-api/infrastructure/core/port"
+)
+
+// DefaultClassifier is the name of the classifier used when none is configured.
+const DefaultClassifier = "default"
+
+// ClassifierDeps holds the dependencies classifiers may need beyond the stock itself.
+// Fields:
+//   - History: Reads recent events per ticker, for classifiers looking at event history.
+type ClassifierDeps struct {
+	History port.StockHistoryReader
+}
+
+// classifiers lists the classification strategies available by name. New classifiers are
+// registered here so they can be evaluated in shadow mode before being promoted.
+var classifiers = map[string]func(deps ClassifierDeps) (port.ClassificationService, error){
+	DefaultClassifier: func(ClassifierDeps) (port.ClassificationService, error) {
+		return NewClassificationService(), nil
+	},
+	MomentumClassifierName: func(deps ClassifierDeps) (port.ClassificationService, error) {
+		if deps.History == nil {
+			return nil, fmt.Errorf("classifier %s requires an event history reader", MomentumClassifierName)
+		}
+		return NewMomentumClassifier(NewClassificationService(), deps.History), nil
+	},
+}
+
+// NewClassifier creates the classifier registered under name.
+func NewClassifier(name string, deps ClassifierDeps) (port.ClassificationService, error) {
+	factory, ok := classifiers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown classifier: %s (available: %v)", name, ClassifierNames())
+	}
+	return factory(deps)
+}
+
+// ClassifierNames returns the names of the registered classifiers, sorted.
+func ClassifierNames() []string {
+	names := make([]string, 0, len(classifiers))
+	for name := range classifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -24,18 +24,20 @@ func (s *ClassificationService) Classify(stock *domain.Stock) {
 	classifications := make(map[string]struct{}) // Use a map to avoid duplicate classifications
 
 	// 1. Classify by Sector (based on company name)
-	// The sector classification is inferred from keywords in the company name.
+	// The sector classification is inferred from keywords in the normalized company name,
+	// so case, accents, and legal-form suffixes do not affect it.
+	company := domain.NormalizeCompany(stock.Company)
 	switch {
-	case strings.Contains(stock.Company, "Medical"), strings.Contains(stock.Company, "Therapeutics"), strings.Contains(stock.Company, "Biopharma"), strings.Contains(stock.Company, "Pharma"):
+	case companyHasAnyKeyword(company, "medical", "therapeutics", "biopharma", "pharma"):
 		// Biotech sector includes companies in pharmaceuticals, biotechnology, and medical research.
 		classifications["Biotech"] = struct{}{}
-	case strings.Contains(stock.Company, "Tech"), strings.Contains(stock.Company, "Software"), strings.Contains(stock.Company, "Group"), strings.Contains(stock.Company, "Systems"), strings.Contains(stock.Company, "Solutions"):
+	case companyHasAnyKeyword(company, "tech", "software", "group", "systems", "solutions"):
 		// Tech sector includes companies in software, hardware, and technology services.
 		classifications["Tech"] = struct{}{}
-	case strings.Contains(stock.Company, "Financial"), strings.Contains(stock.Company, "Bank"), strings.Contains(stock.Company, "Banc"), strings.Contains(stock.Company, "Capital"), strings.Contains(stock.Company, "Insurance"), strings.Contains(stock.Company, "Investments"), strings.Contains(stock.Company, "Advisors"):
+	case companyHasAnyKeyword(company, "financial", "bank", "banc", "capital", "insurance", "investments", "advisors"):
 		// Financial sector includes banks, insurance companies, and investment firms.
 		classifications["Financial"] = struct{}{}
-	case strings.Contains(stock.Company, "Energy"), strings.Contains(stock.Company, "Resources"), strings.Contains(stock.Company, "Petroleum"), strings.Contains(stock.Company, "Gas"):
+	case companyHasAnyKeyword(company, "energy", "resources", "petroleum", "gas"):
 		// Energy sector includes companies in oil, gas, and renewable energy.
 		classifications["Energy"] = struct{}{}
 	default:
@@ -105,6 +107,16 @@ func (s *ClassificationService) ClassifyBatch(batch []*domain.Stock) {
 	}
 }
 
+// companyHasAnyKeyword reports whether the normalized company name has any of the keywords.
+func companyHasAnyKeyword(company string, keywords ...string) bool {
+	for _, keyword := range keywords {
+		if domain.CompanyHasKeyword(company, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // parsePrice converts a price string (e.g., "$13.00") to a float64.
 // It removes the "$" symbol and parses the remaining string as a float.
 func parsePrice(priceStr string) (float64, error) {
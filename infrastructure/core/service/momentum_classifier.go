@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// MomentumClassifierName is the registry name of the MomentumClassifier.
+const MomentumClassifierName = "momentum"
+
+// Rate-of-change rules applied over the events of a ticker within momentumWindow up to
+// (and including) the classified event.
+const (
+	momentumWindow      = 30 * 24 * time.Hour
+	momentumUpgrades    = 3 // Upgrades labeled "Momentum Upgrades"
+	deterioratingCuts   = 2 // Target cuts labeled "Deteriorating Outlook"
+	historyQueryTimeout = 10 * time.Second
+)
+
+// MomentumClassifier extends a base classifier with labels derived from the recent event
+// history of each ticker: repeated upgrades mark "Momentum Upgrades", repeated target cuts
+// mark "Deteriorating Outlook".
+//
+// The history of a whole batch is read with a single query, and the events of the batch
+// itself count as history, so events ingested together are classified consistently.
+type MomentumClassifier struct {
+	base    port.ClassificationService
+	history port.StockHistoryReader
+}
+
+// NewMomentumClassifier creates a new instance of MomentumClassifier.
+func NewMomentumClassifier(base port.ClassificationService, history port.StockHistoryReader) *MomentumClassifier {
+	return &MomentumClassifier{base: base, history: history}
+}
+
+// Classify classifies a single stock. Prefer ClassifyBatch, which shares one history
+// query across the batch.
+func (c *MomentumClassifier) Classify(stock *domain.Stock) {
+	c.ClassifyBatch([]*domain.Stock{stock})
+}
+
+// ClassifyBatch applies the base classification to each stock, then the history labels.
+// If the history cannot be read, the stocks keep the base labels only.
+func (c *MomentumClassifier) ClassifyBatch(batch []*domain.Stock) {
+	c.base.ClassifyBatch(batch)
+	if len(batch) == 0 {
+		return
+	}
+
+	events, err := c.loadHistory(batch)
+	if err != nil {
+		log.Printf("Error loading event history, skipping momentum labels: %v", err)
+		return
+	}
+
+	for _, stock := range batch {
+		upgrades, cuts := countRecentSignals(events[stock.Ticker], stock.Time)
+		if upgrades >= momentumUpgrades {
+			addLabel(stock, "Momentum Upgrades")
+		}
+		if cuts >= deterioratingCuts {
+			addLabel(stock, "Deteriorating Outlook")
+		}
+	}
+}
+
+// loadHistory returns the events of every ticker in the batch, stored or in the batch,
+// grouped by ticker. Stored rows that are also part of the batch are counted once.
+func (c *MomentumClassifier) loadHistory(batch []*domain.Stock) (map[string][]domain.Stock, error) {
+	var tickers []string
+	seenTickers := make(map[string]struct{})
+	since := batch[0].Time
+	for _, stock := range batch {
+		if _, ok := seenTickers[stock.Ticker]; !ok {
+			seenTickers[stock.Ticker] = struct{}{}
+			tickers = append(tickers, stock.Ticker)
+		}
+		if stock.Time.Before(since) {
+			since = stock.Time
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+	defer cancel()
+	stored, err := c.history.FindRecentByTickers(ctx, tickers, since.Add(-momentumWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(map[string][]domain.Stock, len(tickers))
+	batchIDs := make(map[uint]struct{})
+	for _, stock := range batch {
+		events[stock.Ticker] = append(events[stock.Ticker], *stock)
+		if stock.ID != 0 {
+			batchIDs[stock.ID] = struct{}{}
+		}
+	}
+	for _, event := range stored {
+		if _, ok := batchIDs[event.ID]; !ok {
+			events[event.Ticker] = append(events[event.Ticker], event)
+		}
+	}
+	return events, nil
+}
+
+// countRecentSignals counts the upgrades and target cuts among the events that happened
+// within momentumWindow up to at.
+func countRecentSignals(events []domain.Stock, at time.Time) (upgrades, cuts int) {
+	from := at.Add(-momentumWindow)
+	for i := range events {
+		event := &events[i]
+		if event.Time.After(at) || !event.Time.After(from) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(event.Action), "upgraded") {
+			upgrades++
+		}
+		priceFrom, errFrom := parsePrice(event.TargetFrom)
+		priceTo, errTo := parsePrice(event.TargetTo)
+		if errFrom == nil && errTo == nil && priceTo < priceFrom {
+			cuts++
+		}
+	}
+	return upgrades, cuts
+}
+
+// addLabel adds label to the stock's classifications, replacing the "Neutral" default.
+func addLabel(stock *domain.Stock, label string) {
+	if len(stock.Classifications) == 1 && stock.Classifications[0] == domain.NeutralLabel {
+		stock.Classifications = domain.StringArray{}
+	}
+	for _, existing := range stock.Classifications {
+		if existing == label {
+			return
+		}
+	}
+	stock.Classifications = append(stock.Classifications, label)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// fakeHistoryReader serves fixed events and counts the queries made.
+type fakeHistoryReader struct {
+	events  []domain.Stock
+	err     error
+	queries int
+}
+
+func (f *fakeHistoryReader) FindRecentByTickers(_ context.Context, tickers []string, since time.Time) ([]domain.Stock, error) {
+	f.queries++
+	var events []domain.Stock
+	for _, event := range f.events {
+		for _, ticker := range tickers {
+			if event.Ticker == ticker && !event.Time.Before(since) {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, f.err
+}
+
+func TestMomentumClassifier(t *testing.T) {
+	now := time.Now().UTC()
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+
+	history := &fakeHistoryReader{events: []domain.Stock{
+		{Ticker: "AAPL", Action: "upgraded by Acme", Time: daysAgo(20)},
+		{Ticker: "AAPL", Action: "upgraded by Beta", Time: daysAgo(10)},
+		{Ticker: "AAPL", Action: "upgraded by Gamma", Time: daysAgo(45)}, // Outside the window
+		{Ticker: "XOM", TargetFrom: "$120.00", TargetTo: "$110.00", Time: daysAgo(5)},
+		{Ticker: "TSLA", Action: "upgraded by Acme", Time: daysAgo(3)},
+	}}
+	classifier := NewMomentumClassifier(NewClassificationService(), history)
+
+	t.Run("should label history signals with one query per batch", func(t *testing.T) {
+		history.queries = 0
+		batch := []*domain.Stock{
+			{Ticker: "AAPL", Company: "Apple", Action: "upgraded by Delta", Time: now},
+			{Ticker: "XOM", Company: "Exxon", TargetFrom: "$110.00", TargetTo: "$100.00", Time: now},
+			{Ticker: "TSLA", Company: "Tesla", Action: "upgraded by Beta", Time: now},
+		}
+
+		classifier.ClassifyBatch(batch)
+
+		assert.Equal(t, 1, history.queries)
+		assert.Contains(t, batch[0].Classifications, "Momentum Upgrades")
+		assert.Contains(t, batch[0].Classifications, "Bullish Signal")
+		assert.Contains(t, batch[1].Classifications, "Deteriorating Outlook")
+		assert.NotContains(t, batch[2].Classifications, "Momentum Upgrades")
+	})
+
+	t.Run("should count events of the same batch as history", func(t *testing.T) {
+		batch := []*domain.Stock{
+			{Ticker: "TSLA", Company: "Tesla", Action: "upgraded by Beta", Time: daysAgo(2)},
+			{Ticker: "TSLA", Company: "Tesla", Action: "upgraded by Gamma", Time: daysAgo(1)},
+		}
+
+		classifier.ClassifyBatch(batch)
+
+		assert.NotContains(t, batch[0].Classifications, "Momentum Upgrades")
+		assert.Contains(t, batch[1].Classifications, "Momentum Upgrades")
+	})
+
+	t.Run("should keep the base labels when the history cannot be read", func(t *testing.T) {
+		failing := NewMomentumClassifier(NewClassificationService(), &fakeHistoryReader{err: errors.New("db down")})
+		stock := &domain.Stock{Ticker: "AAPL", Company: "Apple", Action: "upgraded by Delta", Time: now}
+
+		failing.Classify(stock)
+
+		assert.Contains(t, stock.Classifications, "Bullish Signal")
+		assert.NotContains(t, stock.Classifications, "Momentum Upgrades")
+	})
+
+	t.Run("should be registered", func(t *testing.T) {
+		_, err := NewClassifier(MomentumClassifierName, ClassifierDeps{History: history})
+		assert.NoError(t, err)
+
+		_, err = NewClassifier(MomentumClassifierName, ClassifierDeps{})
+		assert.Error(t, err)
+	})
+}
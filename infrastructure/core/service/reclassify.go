@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// DefaultReclassifyBatchSize is the number of stocks read, classified, and written at a
+// time by reclassification jobs.
+const DefaultReclassifyBatchSize = 500
+
+// maxFinishedReclassifyJobs bounds the number of finished jobs kept for polling.
+const maxFinishedReclassifyJobs = 50
+
+// ReclassifyService runs the classifier again over the stored stocks matching a set of
+// filters, in the background, and writes the classifications that changed in batches.
+// Only one job runs at a time.
+//
+// Jobs are tracked in process memory: they are only known to the instance that started
+// them, and a restart abandons the running job and forgets the finished ones.
+type ReclassifyService struct {
+	repo           port.StockRepository
+	updater        port.ClassificationUpdater
+	classifier     port.ClassificationService
+	fieldValidator port.FieldValidator
+	batchSize      int
+
+	mu    sync.Mutex
+	jobs  map[string]*domain.ReclassifyJob
+	order []string // Job IDs, oldest first
+	done  chan struct{}
+}
+
+// NewReclassifyService creates a new instance of ReclassifyService writing batches of up
+// to batchSize stocks. Filter fields are checked with fieldValidator.
+func NewReclassifyService(
+	repo port.StockRepository,
+	updater port.ClassificationUpdater,
+	classifier port.ClassificationService,
+	fieldValidator port.FieldValidator,
+	batchSize int,
+) *ReclassifyService {
+	if batchSize <= 0 {
+		batchSize = DefaultReclassifyBatchSize
+	}
+	return &ReclassifyService{
+		repo:           repo,
+		updater:        updater,
+		classifier:     classifier,
+		fieldValidator: fieldValidator,
+		batchSize:      batchSize,
+		jobs:           make(map[string]*domain.ReclassifyJob),
+	}
+}
+
+// Start validates the filters, counts the matching stocks, and starts a job reclassifying
+// them. Stocks are read in ID order, so id filters are not supported.
+//
+// Returns:
+// - The job, as started.
+// - An error wrapping domain.ErrInvalidFilter if the filters are rejected,
+// domain.ErrJobRunning if another job is running, or the error of the count.
+func (s *ReclassifyService) Start(ctx context.Context, filters domain.Filters, actor string) (domain.ReclassifyJob, error) {
+	for field := range filters {
+		switch {
+		case field == "id":
+			return domain.ReclassifyJob{}, fmt.Errorf("%w: id filters are not supported, stocks are read in id order", domain.ErrInvalidFilter)
+		case !s.fieldValidator.IsValidField(field):
+			return domain.ReclassifyJob{}, fmt.Errorf("%w: unknown field %s", domain.ErrInvalidFilter, field)
+		case s.fieldValidator.IsVirtualField(field):
+			return domain.ReclassifyJob{}, fmt.Errorf("%w: field %s can only be used for sorting", domain.ErrInvalidFilter, field)
+		}
+	}
+
+	// Claim the single job slot before counting, so concurrent starts are rejected
+	s.mu.Lock()
+	if s.done != nil {
+		s.mu.Unlock()
+		return domain.ReclassifyJob{}, domain.ErrJobRunning
+	}
+	done := make(chan struct{})
+	s.done = done
+	s.mu.Unlock()
+
+	job, err := s.newJob(ctx, filters, actor)
+	if err != nil {
+		s.mu.Lock()
+		s.done = nil
+		s.mu.Unlock()
+		close(done)
+		return domain.ReclassifyJob{}, err
+	}
+
+	s.mu.Lock()
+	s.track(job)
+	started := *job
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		s.run(job)
+	}()
+
+	return started, nil
+}
+
+// newJob counts the stocks matching the filters and describes the job reclassifying them.
+func (s *ReclassifyService) newJob(ctx context.Context, filters domain.Filters, actor string) (*domain.ReclassifyJob, error) {
+	total, err := s.repo.Count(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("error counting stocks: %w", err)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ReclassifyJob{
+		ID:        id,
+		Status:    domain.JobRunning,
+		Filters:   maps.Clone(filters),
+		Actor:     actor,
+		Total:     total,
+		StartedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Job returns the current state of a job. It returns domain.ErrJobNotFound if the job is
+// unknown to this instance.
+func (s *ReclassifyService) Job(id string) (domain.ReclassifyJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return domain.ReclassifyJob{}, domain.ErrJobNotFound
+	}
+	return *job, nil
+}
+
+// Jobs returns the running job and the most recent finished ones, newest first.
+func (s *ReclassifyService) Jobs() []domain.ReclassifyJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]domain.ReclassifyJob, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, *s.jobs[s.order[i]])
+	}
+	return jobs
+}
+
+// Wait blocks until the running job, if any, finishes or ctx is done.
+func (s *ReclassifyService) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track registers a new job, forgetting the oldest finished jobs beyond the limit.
+// The caller must hold the lock.
+func (s *ReclassifyService) track(job *domain.ReclassifyJob) {
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	for len(s.order) > maxFinishedReclassifyJobs+1 {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// run processes the stocks of a job batch by batch, and records its outcome.
+func (s *ReclassifyService) run(job *domain.ReclassifyJob) {
+	err := s.process(context.Background(), job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := time.Now().UTC()
+	job.FinishedAt = &finishedAt
+	job.Status = domain.JobCompleted
+	if err != nil {
+		job.Status = domain.JobFailed
+		job.Error = err.Error()
+		log.Printf("Reclassification job %s failed after %d stocks: %v", job.ID, job.Processed, err)
+	} else {
+		log.Printf("Reclassification job %s completed: %d stocks processed, %d updated", job.ID, job.Processed, job.Updated)
+	}
+	s.done = nil
+}
+
+// process reads the matching stocks in ID order, after the last ID of the previous batch,
+// so writes made by the job never shift the following batches.
+func (s *ReclassifyService) process(ctx context.Context, job *domain.ReclassifyJob) error {
+	pagination := domain.PaginationParams{Page: 1, PageSize: s.batchSize, SortField: "id", SortOrder: 1}
+	filters := maps.Clone(job.Filters)
+	if filters == nil {
+		filters = domain.Filters{}
+	}
+
+	var lastID uint
+	for {
+		filters["id"] = domain.Filter{Value: int(lastID), MatchMode: "greaterThan"}
+		stocks, err := s.repo.Find(ctx, pagination, filters)
+		if err != nil {
+			return fmt.Errorf("error reading stocks: %w", err)
+		}
+		if len(stocks) == 0 {
+			return nil
+		}
+		lastID = stocks[len(stocks)-1].ID
+
+		changed := s.reclassify(stocks)
+		if len(changed) > 0 {
+			if err := s.updater.UpdateClassifications(ctx, changed); err != nil {
+				return fmt.Errorf("error writing classifications: %w", err)
+			}
+		}
+
+		s.mu.Lock()
+		job.Processed += len(stocks)
+		job.Updated += len(changed)
+		s.mu.Unlock()
+
+		if len(stocks) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+// reclassify runs the classifier over stocks from scratch and returns those whose
+// classifications changed. Labels the classifier never produces, such as custom tags set by
+// admins, are kept.
+func (s *ReclassifyService) reclassify(stocks []domain.Stock) []*domain.Stock {
+	batch := make([]*domain.Stock, len(stocks))
+	previous := make([]domain.StringArray, len(stocks))
+	for i := range stocks {
+		previous[i] = stocks[i].Classifications
+		stocks[i].Classifications = nil
+		batch[i] = &stocks[i]
+	}
+
+	s.classifier.ClassifyBatch(batch)
+
+	var changed []*domain.Stock
+	for i, stock := range batch {
+		for _, label := range previous[i] {
+			if isAdminLabel(label) && !slices.Contains(stock.Classifications, label) {
+				stock.Classifications = append(stock.Classifications, label)
+			}
+		}
+		if !sameLabels(previous[i], stock.Classifications) {
+			changed = append(changed, stock)
+		}
+	}
+	return changed
+}
+
+// isAdminLabel reports whether label can only have been set by an admin: a custom tag, or
+// any other label missing from the registry, which holds every label the classifiers produce.
+func isAdminLabel(label string) bool {
+	return strings.HasPrefix(label, domain.CustomLabelPrefix) || !domain.IsKnownLabel(label)
+}
+
+// sameLabels reports whether a and b hold the same labels, in any order.
+func sameLabels(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// newJobID generates a random job identifier.
+func newJobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("error generating job ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// fakeReclassifyRepository serves stocks in ID order, honouring the id filter of keyset
+// paging and an exact rating_to filter, and records the classifications written.
+type fakeReclassifyRepository struct {
+	port.StockRepository
+	stocks  []domain.Stock
+	updates [][]uint
+	err     error
+	block   chan struct{}
+}
+
+func (f *fakeReclassifyRepository) matches(stock domain.Stock, filters domain.Filters) bool {
+	if filter, ok := filters["id"]; ok && stock.ID <= uint(filter.Value.(int)) {
+		return false
+	}
+	if filter, ok := filters["rating_to"]; ok && stock.RatingTo != filter.Value {
+		return false
+	}
+	return true
+}
+
+func (f *fakeReclassifyRepository) Count(_ context.Context, filters domain.Filters) (int, error) {
+	count := 0
+	for _, stock := range f.stocks {
+		if f.matches(stock, filters) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeReclassifyRepository) Find(_ context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	var stocks []domain.Stock
+	for _, stock := range f.stocks {
+		if f.matches(stock, filters) && len(stocks) < pagination.PageSize {
+			stock.Classifications = slices.Clone(stock.Classifications)
+			stocks = append(stocks, stock)
+		}
+	}
+	return stocks, nil
+}
+
+func (f *fakeReclassifyRepository) UpdateClassifications(_ context.Context, stocks []*domain.Stock) error {
+	var ids []uint
+	for _, stock := range stocks {
+		ids = append(ids, stock.ID)
+		for i := range f.stocks {
+			if f.stocks[i].ID == stock.ID {
+				f.stocks[i].Classifications = stock.Classifications
+			}
+		}
+	}
+	f.updates = append(f.updates, ids)
+	return nil
+}
+
+// ratingLabels are the registry labels ratingClassifier gives to each target rating.
+var ratingLabels = map[string]string{
+	"Buy":  "Bullish Signal",
+	"Sell": "Bearish Signal",
+	"Hold": domain.NeutralLabel,
+}
+
+// ratingClassifier labels each stock after its target rating.
+type ratingClassifier struct{}
+
+func (ratingClassifier) Classify(stock *domain.Stock) {
+	stock.Classifications = append(stock.Classifications, ratingLabels[stock.RatingTo])
+}
+
+func (c ratingClassifier) ClassifyBatch(batch []*domain.Stock) {
+	for _, stock := range batch {
+		c.Classify(stock)
+	}
+}
+
+// fakeFieldValidator accepts a fixed set of fields.
+type fakeFieldValidator struct {
+	fields  []string
+	virtual []string
+}
+
+func (f fakeFieldValidator) IsValidField(field string) bool {
+	return slices.Contains(f.fields, field) || slices.Contains(f.virtual, field)
+}
+
+func (f fakeFieldValidator) IsVirtualField(field string) bool {
+	return slices.Contains(f.virtual, field)
+}
+
+func (f fakeFieldValidator) GetAllValidFields() []string {
+	return append(slices.Clone(f.fields), f.virtual...)
+}
+
+func newTestReclassifyService(repo *fakeReclassifyRepository, batchSize int) *ReclassifyService {
+	validator := fakeFieldValidator{fields: []string{"id", "rating_to", "ticker"}, virtual: []string{"score"}}
+	return NewReclassifyService(repo, repo, ratingClassifier{}, validator, batchSize)
+}
+
+func reclassifyStocks(ratings ...string) []domain.Stock {
+	stocks := make([]domain.Stock, len(ratings))
+	for i, rating := range ratings {
+		stocks[i] = domain.Stock{RatingTo: rating, Classifications: domain.StringArray{"Bullish Signal"}}
+		stocks[i].ID = uint(i + 1)
+	}
+	return stocks
+}
+
+func waitReclassify(t *testing.T, s *ReclassifyService, id string) domain.ReclassifyJob {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Wait(ctx))
+
+	job, err := s.Job(id)
+	require.NoError(t, err)
+	return job
+}
+
+func TestReclassifyService(t *testing.T) {
+	t.Run("pages through every stock and writes only changes", func(t *testing.T) {
+		repo := &fakeReclassifyRepository{stocks: reclassifyStocks("Buy", "Sell", "Buy", "Hold", "Buy")}
+		s := newTestReclassifyService(repo, 2)
+
+		started, err := s.Start(context.Background(), nil, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, domain.JobRunning, started.Status)
+		assert.Equal(t, 5, started.Total)
+		assert.Equal(t, "admin", started.Actor)
+
+		job := waitReclassify(t, s, started.ID)
+		assert.Equal(t, domain.JobCompleted, job.Status)
+		assert.Equal(t, 5, job.Processed)
+		assert.Equal(t, 2, job.Updated)
+		require.NotNil(t, job.FinishedAt)
+		assert.Equal(t, [][]uint{{2}, {4}}, repo.updates)
+		assert.Equal(t, domain.StringArray{"Bearish Signal"}, repo.stocks[1].Classifications)
+		assert.Equal(t, domain.StringArray{"Bullish Signal"}, repo.stocks[2].Classifications)
+	})
+
+	t.Run("restricts the job to the filters", func(t *testing.T) {
+		repo := &fakeReclassifyRepository{stocks: reclassifyStocks("Sell", "Hold", "Sell")}
+		s := newTestReclassifyService(repo, 10)
+
+		started, err := s.Start(context.Background(), domain.Filters{"rating_to": {Value: "Sell", MatchMode: "equals"}}, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, 2, started.Total)
+
+		job := waitReclassify(t, s, started.ID)
+		assert.Equal(t, 2, job.Updated)
+		assert.Equal(t, [][]uint{{1, 3}}, repo.updates)
+		assert.Equal(t, domain.StringArray{"Bullish Signal"}, repo.stocks[1].Classifications)
+	})
+
+	t.Run("keeps the labels set by admins", func(t *testing.T) {
+		repo := &fakeReclassifyRepository{stocks: reclassifyStocks("Sell", "Buy")}
+		repo.stocks[0].Classifications = domain.StringArray{"Bullish Signal", "custom:watchlist", "Legacy"}
+		repo.stocks[1].Classifications = domain.StringArray{"custom:watchlist", "Bullish Signal"}
+		s := newTestReclassifyService(repo, 10)
+
+		started, err := s.Start(context.Background(), nil, "admin")
+		require.NoError(t, err)
+
+		job := waitReclassify(t, s, started.ID)
+		assert.Equal(t, 1, job.Updated)
+		assert.Equal(t, [][]uint{{1}}, repo.updates)
+		assert.Equal(t, domain.StringArray{"Bearish Signal", "custom:watchlist", "Legacy"}, repo.stocks[0].Classifications)
+		assert.Equal(t, domain.StringArray{"custom:watchlist", "Bullish Signal"}, repo.stocks[1].Classifications)
+	})
+
+	t.Run("rejects id, unknown and virtual filters", func(t *testing.T) {
+		s := newTestReclassifyService(&fakeReclassifyRepository{}, 10)
+
+		for _, field := range []string{"id", "unknown", "score"} {
+			_, err := s.Start(context.Background(), domain.Filters{field: {Value: 1, MatchMode: "equals"}}, "admin")
+			assert.ErrorIs(t, err, domain.ErrInvalidFilter, field)
+		}
+		assert.Empty(t, s.Jobs())
+	})
+
+	t.Run("runs a single job at a time", func(t *testing.T) {
+		repo := &fakeReclassifyRepository{stocks: reclassifyStocks("Sell"), block: make(chan struct{})}
+		s := newTestReclassifyService(repo, 10)
+
+		first, err := s.Start(context.Background(), nil, "admin")
+		require.NoError(t, err)
+		_, err = s.Start(context.Background(), nil, "admin")
+		assert.ErrorIs(t, err, domain.ErrJobRunning)
+
+		close(repo.block)
+		waitReclassify(t, s, first.ID)
+
+		second, err := s.Start(context.Background(), nil, "admin")
+		require.NoError(t, err)
+		waitReclassify(t, s, second.ID)
+
+		jobs := s.Jobs()
+		require.Len(t, jobs, 2)
+		assert.Equal(t, second.ID, jobs[0].ID)
+		assert.Equal(t, first.ID, jobs[1].ID)
+	})
+
+	t.Run("records failures", func(t *testing.T) {
+		repo := &fakeReclassifyRepository{stocks: reclassifyStocks("Sell"), err: errors.New("connection refused")}
+		s := newTestReclassifyService(repo, 10)
+
+		started, err := s.Start(context.Background(), nil, "admin")
+		require.NoError(t, err)
+
+		job := waitReclassify(t, s, started.ID)
+		assert.Equal(t, domain.JobFailed, job.Status)
+		assert.Contains(t, job.Error, "connection refused")
+		assert.Zero(t, job.Processed)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		s := newTestReclassifyService(&fakeReclassifyRepository{}, 10)
+
+		_, err := s.Job("missing")
+		assert.ErrorIs(t, err, domain.ErrJobNotFound)
+	})
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// Recommendation strategies, selecting how candidate stocks are ranked.
+const (
+	// StrategyScore ranks by the composite score. It is the strategy of the API.
+	StrategyScore = "score"
+	// StrategyFresh ranks by the composite score weighted by the freshness of the event,
+	// so stale analyst events sink.
+	StrategyFresh = "fresh"
+)
+
+// strategies maps each strategy to the score it ranks by, given the points of each
+// classification.
+var strategies = map[string]func(stock domain.Stock, points map[string]float64) float64{
+	StrategyScore: func(stock domain.Stock, points map[string]float64) float64 {
+		return scoreBreakdown(stock, points, time.Now()).Score
+	},
+	StrategyFresh: func(stock domain.Stock, points map[string]float64) float64 {
+		b := scoreBreakdown(stock, points, time.Now())
+		return b.Score * b.Freshness
+	},
+}
+
+// RecommendationStrategies returns the names of the available strategies, sorted.
+func RecommendationStrategies() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
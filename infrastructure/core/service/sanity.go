@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// Metrics of the last sanity report, published through expvar.
+var (
+	sanitySampled       = expvar.NewInt("sanity_sampled_rows")
+	sanityViolating     = expvar.NewInt("sanity_violating_rows")
+	sanityViolationRate = expvar.NewFloat("sanity_violation_rate")
+)
+
+// SanitySampler checks a random sample of stored stocks against the domain invariants,
+// to catch bad historical data written before validation existed.
+type SanitySampler struct {
+	repo port.StockSampler
+}
+
+// NewSanitySampler creates a new instance of SanitySampler.
+func NewSanitySampler(repo port.StockSampler) *SanitySampler {
+	return &SanitySampler{repo: repo}
+}
+
+// Run samples up to n stocks, checks their invariants, and publishes the violation rate.
+//
+// Returns:
+//   - A SanityReport with the violations found.
+//   - An error if the sample cannot be read.
+func (s *SanitySampler) Run(ctx context.Context, n int) (domain.SanityReport, error) {
+	if n <= 0 {
+		return domain.SanityReport{}, fmt.Errorf("sample size must be positive, got %d", n)
+	}
+
+	stocks, err := s.repo.Sample(ctx, n)
+	if err != nil {
+		return domain.SanityReport{}, fmt.Errorf("error sampling stocks: %w", err)
+	}
+
+	report := domain.NewSanityReport(stocks, time.Now())
+	sanitySampled.Set(int64(report.Sampled))
+	sanityViolating.Set(int64(report.Violating))
+	sanityViolationRate.Set(report.ViolationRate())
+
+	return report, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// fakeStockSampler returns a fixed sample.
+type fakeStockSampler struct {
+	stocks []domain.Stock
+	err    error
+}
+
+func (f *fakeStockSampler) Sample(_ context.Context, n int) ([]domain.Stock, error) {
+	if len(f.stocks) > n {
+		return f.stocks[:n], f.err
+	}
+	return f.stocks, f.err
+}
+
+func TestSanitySampler(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	valid := domain.Stock{Ticker: "AAPL", TargetFrom: "$100.00", TargetTo: "$1,200.50", Time: past, Classifications: domain.StringArray{"Tech"}}
+
+	badTicker := valid
+	badTicker.ID = 2
+	badTicker.Ticker = "aapl"
+
+	badTargets := valid
+	badTargets.ID = 3
+	badTargets.TargetTo = "n/a"
+
+	futureUnclassified := valid
+	futureUnclassified.ID = 4
+	futureUnclassified.Time = time.Now().Add(time.Hour)
+	futureUnclassified.Classifications = nil
+
+	t.Run("should count violations per invariant", func(t *testing.T) {
+		sampler := NewSanitySampler(&fakeStockSampler{stocks: []domain.Stock{valid, badTicker, badTargets, futureUnclassified}})
+
+		report, err := sampler.Run(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, report.Sampled)
+		assert.Equal(t, 3, report.Violating)
+		assert.Equal(t, 0.75, report.ViolationRate())
+		assert.Equal(t, map[string]int{
+			domain.InvariantTickerFormat:    1,
+			domain.InvariantParsableTargets: 1,
+			domain.InvariantClassifications: 1,
+			domain.InvariantTimeNotInFuture: 1,
+		}, report.Violations)
+		assert.Equal(t, []uint{4}, report.Examples[domain.InvariantTimeNotInFuture])
+		assert.Equal(t, 0.75, sanityViolationRate.Value())
+		assert.Contains(t, report.String(), "rate=75.00% classifications=1 (ids 4)")
+	})
+
+	t.Run("should report a clean sample", func(t *testing.T) {
+		report, err := NewSanitySampler(&fakeStockSampler{stocks: []domain.Stock{valid}}).Run(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, report.Violating)
+		assert.Equal(t, "sampled=1 violating=0 rate=0.00%", report.String())
+	})
+
+	t.Run("should reject invalid sample sizes and repository errors", func(t *testing.T) {
+		_, err := NewSanitySampler(&fakeStockSampler{}).Run(context.Background(), 0)
+		assert.Error(t, err)
+
+		_, err = NewSanitySampler(&fakeStockSampler{err: errors.New("db down")}).Run(context.Background(), 10)
+		assert.ErrorContains(t, err, "db down")
+	})
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// scoringVersion identifies the scoring strategy. Bump it whenever scoreBreakdown changes,
+// so scores computed by the previous strategy are never served from the cache.
+const scoringVersion = "v1"
+
+// defaultScoreCacheSize bounds the number of cached scores.
+const defaultScoreCacheSize = 50000
+
+// ScoreCache memoizes stock scores keyed by a hash of the scoring-relevant fields, the
+// scoring version, and the version of the scoring weights. Since the key depends on the
+// content, a changed row or a change of weights never hits a stale entry; Invalidate
+// additionally drops the entries of rows known to have changed.
+type ScoreCache struct {
+	mu         sync.RWMutex
+	scores     map[string]float64
+	keysByID   map[uint]string
+	maxEntries int
+}
+
+// NewScoreCache creates a cache holding up to maxEntries scores.
+func NewScoreCache(maxEntries int) *ScoreCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultScoreCacheSize
+	}
+	return &ScoreCache{
+		scores:     make(map[string]float64),
+		keysByID:   make(map[uint]string),
+		maxEntries: maxEntries,
+	}
+}
+
+// Score returns the cached score of stock under the given version of the scoring weights,
+// calling compute on a cache miss.
+func (c *ScoreCache) Score(stock *domain.Stock, weightsVersion int, compute func(domain.Stock) float64) float64 {
+	key := scoreKey(stock, weightsVersion)
+
+	c.mu.RLock()
+	score, ok := c.scores[key]
+	c.mu.RUnlock()
+	if ok {
+		return score
+	}
+
+	score = compute(*stock)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Start over when full; entries are cheap to recompute
+	if len(c.scores) >= c.maxEntries {
+		c.scores = make(map[string]float64)
+		c.keysByID = make(map[uint]string)
+	}
+	c.scores[key] = score
+	if stock.ID != 0 {
+		c.keysByID[stock.ID] = key
+	}
+
+	return score
+}
+
+// Invalidate drops the cached scores of the stocks with the given IDs.
+func (c *ScoreCache) Invalidate(ids ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		if key, ok := c.keysByID[id]; ok {
+			delete(c.scores, key)
+			delete(c.keysByID, id)
+		}
+	}
+}
+
+// Purge drops every cached score.
+func (c *ScoreCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scores = make(map[string]float64)
+	c.keysByID = make(map[uint]string)
+}
+
+// Len returns the number of cached scores.
+func (c *ScoreCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.scores)
+}
+
+// scoreKey hashes the fields the score depends on, together with the scoring version and
+// the version of the weights. Classifications are sorted because their order does not
+// affect the score.
+func scoreKey(stock *domain.Stock, weightsVersion int) string {
+	classifications := append([]string(nil), stock.Classifications...)
+	sort.Strings(classifications)
+
+	content := strings.Join([]string{
+		scoringVersion,
+		strconv.Itoa(weightsVersion),
+		stock.TargetFrom,
+		stock.TargetTo,
+		stock.RatingTo,
+		strings.Join(classifications, "\x1f"),
+	}, "\x1e")
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestScoreCache(t *testing.T) {
+	stock := domain.Stock{
+		Ticker:          "AAPL",
+		Classifications: []string{"Potential Growth", "Bullish Signal"},
+		RatingTo:        "Strong-Buy",
+		TargetFrom:      "$100.00",
+		TargetTo:        "$115.00",
+	}
+	stock.ID = 1
+
+	countingScore := func(calls *int) func(domain.Stock) float64 {
+		return func(s domain.Stock) float64 {
+			*calls++
+			return scoreBreakdown(s, domain.ClassificationPoints, time.Now()).Score
+		}
+	}
+
+	t.Run("should compute each distinct content once", func(t *testing.T) {
+		cache := NewScoreCache(10)
+		calls := 0
+
+		first := cache.Score(&stock, 0, countingScore(&calls))
+		second := cache.Score(&stock, 0, countingScore(&calls))
+
+		// Same scoring content in a different order hits the same entry
+		reordered := stock
+		reordered.Classifications = []string{"Bullish Signal", "Potential Growth"}
+		third := cache.Score(&reordered, 0, countingScore(&calls))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first, second)
+		assert.Equal(t, first, third)
+	})
+
+	t.Run("should miss when scoring fields change", func(t *testing.T) {
+		cache := NewScoreCache(10)
+		calls := 0
+
+		cache.Score(&stock, 0, countingScore(&calls))
+		changed := stock
+		changed.RatingTo = "Buy"
+		cache.Score(&changed, 0, countingScore(&calls))
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should recompute after invalidation", func(t *testing.T) {
+		cache := NewScoreCache(10)
+		calls := 0
+
+		cache.Score(&stock, 0, countingScore(&calls))
+		cache.Invalidate(stock.ID)
+		cache.Score(&stock, 0, countingScore(&calls))
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should not reuse scores computed with other weights", func(t *testing.T) {
+		cache := NewScoreCache(10)
+		calls := 0
+
+		cache.Score(&stock, 1, countingScore(&calls))
+		cache.Score(&stock, 2, countingScore(&calls))
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should stay within its size bound", func(t *testing.T) {
+		cache := NewScoreCache(2)
+		for _, target := range []string{"$110.00", "$120.00", "$130.00"} {
+			s := stock
+			s.TargetTo = target
+			cache.Score(&s, 0, countingScore(new(int)))
+		}
+
+		assert.LessOrEqual(t, cache.Len(), 2)
+	})
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// ScoreHistoryRecorder persists, after each ingestion run, the score of every ticker the
+// run wrote, computed from the ticker's latest event.
+type ScoreHistoryRecorder struct {
+	repo   port.ScoreHistoryRepository
+	scorer port.BestInvestmentsService
+}
+
+// NewScoreHistoryRecorder creates a new instance of ScoreHistoryRecorder.
+func NewScoreHistoryRecorder(repo port.ScoreHistoryRepository, scorer port.BestInvestmentsService) *ScoreHistoryRecorder {
+	return &ScoreHistoryRecorder{repo: repo, scorer: scorer}
+}
+
+// RecordScores stores one entry per ticker, scoring the most recent of its stocks.
+func (r *ScoreHistoryRecorder) RecordScores(ctx context.Context, stocks []domain.Stock, computedAt time.Time) error {
+	latest := make(map[string]*domain.Stock)
+	for i := range stocks {
+		stock := &stocks[i]
+		if current, ok := latest[stock.Ticker]; !ok || stock.Time.After(current.Time) {
+			latest[stock.Ticker] = stock
+		}
+	}
+
+	entries := make([]*domain.ScoreHistoryEntry, 0, len(latest))
+	for _, stock := range latest {
+		b := r.scorer.GetScoreBreakdown(*stock)
+		entry := &domain.ScoreHistoryEntry{
+			Ticker:       stock.Ticker,
+			StockID:      stock.ID,
+			Score:        b.Score,
+			UpsidePoints: b.UpsidePoints,
+			RatingPoints: b.RatingPoints,
+			RiskScore:    b.RiskScore,
+			EventTime:    stock.Time,
+			ComputedAt:   computedAt,
+		}
+		for _, contribution := range b.Classifications {
+			entry.ClassificationPoints += contribution.Points
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Ticker < entries[j].Ticker })
+
+	if err := r.repo.SaveBatch(ctx, entries); err != nil {
+		return fmt.Errorf("error saving score history: %w", err)
+	}
+	return nil
+}
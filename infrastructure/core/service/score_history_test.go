@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// fakeScoreHistoryRepository records the saved entries.
+type fakeScoreHistoryRepository struct {
+	port.ScoreHistoryRepository
+	saved []*domain.ScoreHistoryEntry
+}
+
+func (f *fakeScoreHistoryRepository) SaveBatch(_ context.Context, entries []*domain.ScoreHistoryEntry) error {
+	f.saved = append(f.saved, entries...)
+	return nil
+}
+
+func TestScoreHistoryRecorder(t *testing.T) {
+	repo := &fakeScoreHistoryRepository{}
+	recorder := NewScoreHistoryRecorder(repo, NewBestInvestmentsService())
+	now := time.Now().UTC()
+
+	older := domain.Stock{Ticker: "AAPL", TargetFrom: "$100.00", TargetTo: "$100.00", Time: now.Add(-time.Hour)}
+	older.ID = 1
+	latest := domain.Stock{Ticker: "AAPL", TargetFrom: "$100.00", TargetTo: "$120.00", RatingTo: "Buy", Classifications: domain.StringArray{"Tech"}, Time: now}
+	latest.ID = 2
+	other := domain.Stock{Ticker: "MSFT", TargetFrom: "$100.00", TargetTo: "$110.00", Time: now}
+	other.ID = 3
+
+	err := recorder.RecordScores(context.Background(), []domain.Stock{latest, other, older}, now)
+
+	assert.NoError(t, err)
+	assert.Len(t, repo.saved, 2)
+
+	aapl := repo.saved[0]
+	assert.Equal(t, "AAPL", aapl.Ticker)
+	assert.Equal(t, uint(2), aapl.StockID)
+	assert.Equal(t, 40.0, aapl.UpsidePoints)
+	assert.Equal(t, domain.ClassificationPoints["Tech"], aapl.ClassificationPoints)
+	assert.Equal(t, domain.RatingPoints["Buy"], aapl.RatingPoints)
+	assert.Equal(t, aapl.UpsidePoints+aapl.ClassificationPoints+aapl.RatingPoints, aapl.Score)
+	assert.Equal(t, now, aapl.ComputedAt)
+	assert.Equal(t, "MSFT", repo.saved[1].Ticker)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// DefaultScoringWeightsTTL is how long the weights in effect are cached before they are
+// read again, which bounds how long other replicas keep scoring with outdated weights.
+const DefaultScoringWeightsTTL = 30 * time.Second
+
+// scoringWeightsLoadTimeout bounds each read of the weights in effect.
+const scoringWeightsLoadTimeout = 5 * time.Second
+
+// ScoringWeightsService manages the versioned classification points used to score stocks.
+// The weights in effect are cached and read again once older than the TTL, or right after
+// a change made through this service.
+type ScoringWeightsService struct {
+	repo port.ScoringWeightRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu       sync.RWMutex
+	current  domain.ScoringWeights
+	loadedAt time.Time // Zero when the cache must be read again
+
+	loads singleflight.Group
+}
+
+// NewScoringWeightsService creates a new instance of ScoringWeightsService caching the
+// weights in effect for ttl. Until they are first read, the built-in weights apply.
+func NewScoringWeightsService(repo port.ScoringWeightRepository, ttl time.Duration) *ScoringWeightsService {
+	if ttl <= 0 {
+		ttl = DefaultScoringWeightsTTL
+	}
+	return &ScoringWeightsService{
+		repo:    repo,
+		ttl:     ttl,
+		now:     time.Now,
+		current: domain.DefaultScoringWeights(),
+	}
+}
+
+// CurrentScoringWeights returns the weights in effect, reading them again when the cache
+// expired. If they cannot be read, the last known weights are kept for another TTL.
+// The returned points must not be modified.
+func (s *ScoringWeightsService) CurrentScoringWeights() domain.ScoringWeights {
+	s.mu.RLock()
+	current, fresh := s.current, !s.loadedAt.IsZero() && s.now().Sub(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return current
+	}
+
+	// Concurrent callers share a single read
+	weights, _, _ := s.loads.Do("current", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), scoringWeightsLoadTimeout)
+		defer cancel()
+		if err := s.reload(ctx); err != nil {
+			log.Printf("Error reading scoring weights, keeping version %d: %v", current.Version, err)
+		}
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.current, nil
+	})
+	return weights.(domain.ScoringWeights)
+}
+
+// reload reads the weights in effect into the cache. On failure, the cached weights are
+// kept for another TTL, so an unavailable database does not slow down every request.
+func (s *ScoringWeightsService) reload(ctx context.Context) error {
+	changes, err := s.repo.ListChanges(ctx, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = s.now()
+	if err != nil {
+		return fmt.Errorf("error reading scoring weights: %w", err)
+	}
+	s.current = domain.ReplayScoringWeights(changes, 0)
+	return nil
+}
+
+// PurgeCache makes the next call read the weights in effect again.
+func (s *ScoringWeightsService) PurgeCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
+
+// GetWeights returns the weights in effect at version, or the current ones when version
+// is zero. It returns domain.ErrScoringWeightsVersionNotFound if no weights were stored
+// with that version.
+func (s *ScoringWeightsService) GetWeights(ctx context.Context, version int) (domain.ScoringWeights, error) {
+	if version < 0 {
+		return domain.ScoringWeights{}, domain.ErrScoringWeightsVersionNotFound
+	}
+
+	changes, err := s.repo.ListChanges(ctx, version)
+	if err != nil {
+		return domain.ScoringWeights{}, fmt.Errorf("error reading scoring weights: %w", err)
+	}
+
+	weights := domain.ReplayScoringWeights(changes, version)
+	if version != 0 && weights.Version != version {
+		return domain.ScoringWeights{}, domain.ErrScoringWeightsVersionNotFound
+	}
+	return weights, nil
+}
+
+// ScoringWeightsAsOf returns the weights in effect at at: those of the latest version
+// made by then, or the built-in weights if no change was made by then.
+func (s *ScoringWeightsService) ScoringWeightsAsOf(ctx context.Context, at time.Time) (domain.ScoringWeights, error) {
+	changes, err := s.repo.ListChanges(ctx, 0)
+	if err != nil {
+		return domain.ScoringWeights{}, fmt.Errorf("error reading scoring weights: %w", err)
+	}
+
+	changes = slices.DeleteFunc(changes, func(change domain.ScoringWeight) bool {
+		return change.CreatedAt.After(at)
+	})
+	return domain.ReplayScoringWeights(changes, 0), nil
+}
+
+// ListChanges returns every change made to the weights, oldest first.
+func (s *ScoringWeightsService) ListChanges(ctx context.Context) ([]domain.ScoringWeight, error) {
+	changes, err := s.repo.ListChanges(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scoring weight changes: %w", err)
+	}
+	return changes, nil
+}
+
+// Apply validates and stores changes as a new version of the weights, made by actor, and
+// returns the resulting weights. The cache is refreshed, so the new weights apply at once
+// on this instance and within the TTL on the others.
+// Validation errors wrap domain.ErrInvalidScoringWeight, and removing the weight of a
+// classification that has none domain.ErrScoringWeightNotFound.
+func (s *ScoringWeightsService) Apply(ctx context.Context, actor string, changes ...*domain.ScoringWeight) (domain.ScoringWeights, error) {
+	if len(changes) == 0 {
+		return domain.ScoringWeights{}, fmt.Errorf("%w: no changes", domain.ErrInvalidScoringWeight)
+	}
+	current, err := s.GetWeights(ctx, 0)
+	if err != nil {
+		return domain.ScoringWeights{}, err
+	}
+
+	now := s.now().UTC()
+	for _, change := range changes {
+		if err := change.Validate(); err != nil {
+			return domain.ScoringWeights{}, err
+		}
+		if _, ok := current.Points[change.Label]; change.Removed && !ok {
+			return domain.ScoringWeights{}, fmt.Errorf("%w: %s", domain.ErrScoringWeightNotFound, change.Label)
+		}
+		change.Actor = actor
+		change.CreatedAt = now
+	}
+
+	version, err := s.repo.Append(ctx, changes)
+	if err != nil {
+		return domain.ScoringWeights{}, fmt.Errorf("error storing scoring weights: %w", err)
+	}
+
+	weights, err := s.GetWeights(ctx, version)
+	if err != nil {
+		return domain.ScoringWeights{}, err
+	}
+
+	// Only move forward, in case a concurrent reload already saw a later version
+	s.mu.Lock()
+	if weights.Version >= s.current.Version {
+		s.current = weights
+		s.loadedAt = s.now()
+	}
+	s.mu.Unlock()
+
+	return weights, nil
+}
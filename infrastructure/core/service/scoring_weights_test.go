@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// fakeScoringWeightRepository stores changes in memory and can be made to fail.
+type fakeScoringWeightRepository struct {
+	changes []domain.ScoringWeight
+	reads   int
+	err     error
+}
+
+func (f *fakeScoringWeightRepository) ListChanges(_ context.Context, version int) ([]domain.ScoringWeight, error) {
+	f.reads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var changes []domain.ScoringWeight
+	for _, change := range f.changes {
+		if version == 0 || change.Version <= version {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (f *fakeScoringWeightRepository) Append(_ context.Context, changes []*domain.ScoringWeight) (int, error) {
+	version := 1
+	if len(f.changes) > 0 {
+		version = f.changes[len(f.changes)-1].Version + 1
+	}
+	for _, change := range changes {
+		change.Version = version
+		f.changes = append(f.changes, *change)
+	}
+	return version, nil
+}
+
+func TestScoringWeightsService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should version every change and rebuild past versions", func(t *testing.T) {
+		repo := &fakeScoringWeightRepository{}
+		weights := NewScoringWeightsService(repo, time.Minute)
+
+		assert.Equal(t, domain.DefaultScoringWeights(), weights.CurrentScoringWeights())
+
+		v1, err := weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Tech", Points: 12}, &domain.ScoringWeight{Label: "Energy", Points: 5})
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScoringWeights{Version: 1, Points: map[string]float64{"Tech": 12, "Energy": 5}}, v1)
+
+		v2, err := weights.Apply(ctx, "bob", &domain.ScoringWeight{Label: "Tech", Removed: true})
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScoringWeights{Version: 2, Points: map[string]float64{"Energy": 5}}, v2)
+		assert.Equal(t, v2, weights.CurrentScoringWeights())
+		assert.Equal(t, "bob", repo.changes[2].Actor)
+
+		past, err := weights.GetWeights(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, v1, past)
+
+		_, err = weights.GetWeights(ctx, 3)
+		assert.ErrorIs(t, err, domain.ErrScoringWeightsVersionNotFound)
+	})
+
+	t.Run("should rebuild the weights in effect at a past time", func(t *testing.T) {
+		repo := &fakeScoringWeightRepository{}
+		weights := NewScoringWeightsService(repo, time.Minute)
+		start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		weights.now = func() time.Time { return start }
+		v1, err := weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Tech", Points: 12})
+		require.NoError(t, err)
+		weights.now = func() time.Time { return start.Add(time.Hour) }
+		v2, err := weights.Apply(ctx, "bob", &domain.ScoringWeight{Label: "Tech", Points: 20})
+		require.NoError(t, err)
+
+		for at, expected := range map[time.Time]domain.ScoringWeights{
+			start.Add(-time.Minute):     domain.DefaultScoringWeights(),
+			start:                       v1,
+			start.Add(30 * time.Minute): v1,
+			start.Add(2 * time.Hour):    v2,
+		} {
+			past, err := weights.ScoringWeightsAsOf(ctx, at)
+			require.NoError(t, err)
+			assert.Equal(t, expected, past, at)
+		}
+	})
+
+	t.Run("should reject invalid changes", func(t *testing.T) {
+		repo := &fakeScoringWeightRepository{}
+		weights := NewScoringWeightsService(repo, time.Minute)
+
+		_, err := weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Unknown", Points: 1})
+		assert.ErrorIs(t, err, domain.ErrInvalidScoringWeight)
+
+		_, err = weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Tech", Points: domain.MaxScoringWeight + 1})
+		assert.ErrorIs(t, err, domain.ErrInvalidScoringWeight)
+
+		_, err = weights.Apply(ctx, "alice", &domain.ScoringWeight{Label: "Energy", Removed: true})
+		assert.ErrorIs(t, err, domain.ErrScoringWeightNotFound)
+
+		assert.Empty(t, repo.changes)
+	})
+
+	t.Run("should cache the weights in effect and keep them when they cannot be read", func(t *testing.T) {
+		repo := &fakeScoringWeightRepository{changes: []domain.ScoringWeight{{Version: 1, Label: "Tech", Points: 50}}}
+		weights := NewScoringWeightsService(repo, time.Minute)
+		now := time.Now()
+		weights.now = func() time.Time { return now }
+
+		assert.Equal(t, 1, weights.CurrentScoringWeights().Version)
+		assert.Equal(t, 1, weights.CurrentScoringWeights().Version)
+		assert.Equal(t, 1, repo.reads)
+
+		// Changes made by other replicas show up once the cache expires
+		repo.changes = append(repo.changes, domain.ScoringWeight{Version: 2, Label: "Tech", Points: 60})
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, 60.0, weights.CurrentScoringWeights().Points["Tech"])
+
+		repo.err = errors.New("connection refused")
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, 2, weights.CurrentScoringWeights().Version)
+		assert.Equal(t, 2, weights.CurrentScoringWeights().Version)
+		assert.Equal(t, 3, repo.reads)
+	})
+
+	t.Run("should score recommendations with the weights in effect", func(t *testing.T) {
+		repo := &fakeScoringWeightRepository{changes: []domain.ScoringWeight{{Version: 4, Label: "Energy", Points: 100}}}
+		srv := NewBestInvestmentsService().WithScoringWeights(NewScoringWeightsService(repo, time.Minute))
+
+		stocks := []domain.Stock{
+			{Ticker: "AAPL", TargetFrom: "$100.00", TargetTo: "$110.00", Classifications: []string{"Tech"}},
+			{Ticker: "XOM", TargetFrom: "$100.00", TargetTo: "$110.00", Classifications: []string{"Energy"}},
+		}
+		recommendations, version := srv.GetVersionedRecommendations(stocks, 2)
+
+		assert.Equal(t, 4, version)
+		assert.Equal(t, "XOM", recommendations[0].Ticker)
+		assert.InDelta(t, 120.0, recommendations[0].Score, 0.001)
+		assert.InDelta(t, 20.0, recommendations[1].Score, 0.001)
+	})
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"stock-api/infrastructure/core/domain"
 	"stock-api/infrastructure/core/port"
@@ -12,77 +15,365 @@ import (
 type StockService struct {
 	repo           port.StockRepository
 	fieldValidator port.FieldValidator
+	labelPolicy    domain.UnknownLabelPolicy
+	limits         domain.PaginationLimits
 }
 
 func NewStockService(userRepo port.StockRepository, fieldValidator port.FieldValidator) *StockService {
-	return &StockService{repo: userRepo, fieldValidator: fieldValidator}
+	return &StockService{
+		repo:           userRepo,
+		fieldValidator: fieldValidator,
+		labelPolicy:    domain.LabelPolicyDeny,
+		limits:         domain.DefaultPaginationLimits,
+	}
+}
+
+// WithPaginationLimits sets the default page size and the page size cap applied to
+// client-supplied pagination. Non-positive values keep the current limits.
+func (s *StockService) WithPaginationLimits(limits domain.PaginationLimits) *StockService {
+	if limits.DefaultPageSize > 0 {
+		s.limits.DefaultPageSize = limits.DefaultPageSize
+	}
+	if limits.MaxPageSize > 0 {
+		s.limits.MaxPageSize = limits.MaxPageSize
+	}
+	if s.limits.DefaultPageSize > s.limits.MaxPageSize {
+		s.limits.DefaultPageSize = s.limits.MaxPageSize
+	}
+	return s
+}
+
+// WithLabelPolicy sets how client-supplied classifications missing from the label
+// registry are handled on writes. Unknown labels are rejected by default.
+func (s *StockService) WithLabelPolicy(policy domain.UnknownLabelPolicy) *StockService {
+	s.labelPolicy = policy
+	return s
 }
 
 func (s *StockService) RegisterStock(ctx context.Context, stock *domain.Stock) error {
 	if stock == nil {
 		return errors.New("stock cannot be nil")
 	}
+	if err := s.validateStock(stock); err != nil {
+		return err
+	}
 	if err := s.repo.Create(ctx, stock); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *StockService) Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, int, error) {
-	// Validate page
-	if pagination.Page <= 0 {
-		return nil, 0, fmt.Errorf("invalid page: %d (must be greater than 0)", pagination.Page)
+// RegisterStocks validates and stores several stocks in a single batch.
+// Nothing is stored if any stock is invalid.
+func (s *StockService) RegisterStocks(ctx context.Context, stocks []*domain.Stock) error {
+	if len(stocks) == 0 {
+		return fmt.Errorf("%w: batch cannot be empty", domain.ErrInvalidStock)
+	}
+	for i, stock := range stocks {
+		if stock == nil {
+			return fmt.Errorf("%w: item %d cannot be null", domain.ErrInvalidStock, i)
+		}
+		if err := s.validateStock(stock); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
 	}
+	return s.repo.SaveBatch(ctx, stocks)
+}
 
-	// Validate pageSize
-	if pagination.PageSize <= 0 {
-		return nil, 0, fmt.Errorf("invalid page size: %d (must be greater than 0)", pagination.PageSize)
+// UpdateStock applies a JSON Merge Patch to the stock with the given ID and stores the
+// fields that changed. The patched stock goes through the same validation as new stocks.
+//
+// Returns:
+//   - *domain.Stock: The stock after the patch.
+//   - []domain.FieldChange: The fields whose value changed, empty if the patch was a no-op.
+//   - error: domain.ErrStockNotFound if the stock does not exist, an error wrapping
+//     domain.ErrInvalidStock if the patch or the patched stock is invalid.
+func (s *StockService) UpdateStock(ctx context.Context, id uint, patch map[string]json.RawMessage) (*domain.Stock, []domain.FieldChange, error) {
+	stock, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Values by default for optional Pagination Fields
-	if pagination.SortField == "" {
-		pagination.SortField = "time"
+	before := stock.Clone()
+
+	if err := domain.ApplyMergePatch(stock, patch); err != nil {
+		return nil, nil, err
+	}
+	if err := s.validateStock(stock); err != nil {
+		return nil, nil, err
 	}
 
-	if pagination.SortOrder == 0 {
-		pagination.SortOrder = -1
+	// Diff after validation, which may normalize the classifications
+	changes := domain.DiffStocks(&before, stock)
+	if len(changes) == 0 {
+		return stock, changes, nil
 	}
 
-	// Validate sorting field
-	if pagination.SortField != "" && !s.fieldValidator.IsValidField(pagination.SortField) {
-		return nil, 0, fmt.Errorf("invalid sort field: %s", pagination.SortField)
+	columns := make([]string, len(changes))
+	for i, change := range changes {
+		columns[i] = change.Field
+	}
+	if !slices.Equal(before.Warnings, stock.Warnings) {
+		columns = append(columns, "warnings")
+	}
+	if err := s.repo.Update(ctx, stock, columns); err != nil {
+		return nil, nil, err
+	}
+	return stock, changes, nil
+}
+
+// count returns the number of stocks matching the filters. When the repository serves
+// counts from a cache, it also returns when a count older than domain.StaleAfter was
+// computed, so clients can be told the total may be delayed.
+func (s *StockService) count(ctx context.Context, filters domain.Filters) (int, time.Time, error) {
+	counter, ok := s.repo.(port.CachedCounter)
+	if !ok {
+		total, err := s.repo.Count(ctx, filters)
+		return total, time.Time{}, err
 	}
 
-	// Validate sort order
-	if pagination.SortOrder != 1 && pagination.SortOrder != -1 {
-		return nil, 0, fmt.Errorf("invalid sort order: %d (must be 'asc' or 'desc')", pagination.SortOrder)
+	total, cachedAt, err := counter.CountCached(ctx, filters)
+	if err != nil || cachedAt.IsZero() || time.Since(cachedAt) <= domain.StaleAfter {
+		return total, time.Time{}, err
 	}
+	return total, cachedAt, nil
+}
+
+// validateStock checks a client-supplied stock, normalizes its classifications
+// according to the label policy, and flags its soft validation warnings, which never
+// reject it. Errors wrap domain.ErrInvalidStock.
+func (s *StockService) validateStock(stock *domain.Stock) error {
+	if err := stock.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidStock, err)
+	}
+	stock.Warnings = stock.CheckWarnings()
+
+	labels, err := domain.NormalizeLabels(stock.Classifications, s.labelPolicy)
+	if err != nil {
+		return err
+	}
+	stock.Classifications = labels
+	return nil
+}
 
-	// Validate filter fields
+func (s *StockService) Find(ctx context.Context, pagination domain.PaginationParams, filters domain.Filters) ([]domain.Stock, int, error) {
+	page, err := s.FindPage(ctx, pagination, filters, domain.QueryOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return page.Stocks, page.Total, nil
+}
+
+// FindPage returns one page of the stocks matching the filters along with its metadata.
+// When opts.Estimate is set, the total may be an estimate (see StockRepository.EstimateCount).
+// When opts.CountOnly is set, only the total is computed and no rows are fetched.
+// Pages may be cached for domain.StockCacheMaxAge and totals alone for
+// domain.CountCacheMaxAge; pages with a stale total must be revalidated.
+// When ctx reads the data at a past time (see domain.WithAsOf), the stocks are read as
+// they stood then and the total is exact.
+func (s *StockService) FindPage(
+	ctx context.Context,
+	pagination domain.PaginationParams,
+	filters domain.Filters,
+	opts domain.QueryOptions,
+) (domain.StockPage, error) {
+	pagination, err := s.validatePagination(pagination)
+	if err != nil {
+		return domain.StockPage{}, err
+	}
+
+	// Validate filter fields. Virtual fields are computed and only support sorting.
 	for field := range filters {
 		if !s.fieldValidator.IsValidField(field) {
-			return nil, 0, fmt.Errorf("invalid filter field: %s", field)
+			return domain.StockPage{}, fmt.Errorf("invalid filter field: %s", field)
+		}
+		if s.fieldValidator.IsVirtualField(field) {
+			return domain.StockPage{}, fmt.Errorf("field %s can only be used for sorting", field)
+		}
+	}
+
+	if at, ok := domain.AsOfFrom(ctx); ok {
+		return s.findPageAsOf(ctx, at, pagination, filters, opts)
+	}
+
+	page := domain.StockPage{Pagination: pagination}
+	if !opts.CountOnly {
+		page.Stocks, err = s.repo.Find(ctx, pagination, filters)
+		if err != nil {
+			return domain.StockPage{}, err
 		}
 	}
 
-	stocks, err := s.repo.Find(ctx, pagination, filters)
+	if opts.Estimate {
+		page.Total, page.Approximate, err = s.repo.EstimateCount(ctx, filters)
+	} else {
+		page.Total, page.CachedAt, err = s.count(ctx, filters)
+	}
 	if err != nil {
-		return nil, 0, err
+		return domain.StockPage{}, err
+	}
+
+	hint := domain.CacheHint{MaxAge: domain.StockCacheMaxAge}
+	switch {
+	case page.Stale():
+		hint = domain.CacheHint{Revalidate: true}
+	case opts.CountOnly:
+		hint.MaxAge = domain.CountCacheMaxAge
 	}
+	domain.HintCache(ctx, hint)
+
+	return page, nil
+}
 
-	total, err := s.repo.Count(ctx, filters)
+// findPageAsOf returns one page of the stocks matching the filters as they stood at at,
+// with an exact total. Views of recent times, such as asOf today, still change with the
+// writes that follow, so pages must be revalidated.
+func (s *StockService) findPageAsOf(
+	ctx context.Context,
+	at time.Time,
+	pagination domain.PaginationParams,
+	filters domain.Filters,
+	opts domain.QueryOptions,
+) (domain.StockPage, error) {
+	reader, err := s.asOfReader()
 	if err != nil {
-		return nil, 0, err
+		return domain.StockPage{}, err
 	}
 
-	return stocks, total, nil
+	page := domain.StockPage{Pagination: pagination}
+	if !opts.CountOnly {
+		page.Stocks, err = reader.FindAsOf(ctx, at, pagination, filters)
+		if err != nil {
+			return domain.StockPage{}, err
+		}
+	}
+	page.Total, err = reader.CountAsOf(ctx, at, filters)
+	if err != nil {
+		return domain.StockPage{}, err
+	}
+
+	domain.HintCache(ctx, domain.CacheHint{Revalidate: true})
+	return page, nil
 }
 
+// asOfReader returns the repository reading past views, or domain.ErrAsOfUnsupported if
+// it cannot.
+func (s *StockService) asOfReader() (port.AsOfReader, error) {
+	reader, ok := s.repo.(port.AsOfReader)
+	if !ok {
+		return nil, domain.ErrAsOfUnsupported
+	}
+	return reader, nil
+}
+
+// FindByClassification returns one page of the stocks tagged with the given classification,
+// along with the total number of matching stocks and the pagination applied. Pages may be
+// cached for domain.StockCacheMaxAge. Past views are read as in FindPage.
+func (s *StockService) FindByClassification(
+	ctx context.Context,
+	classification string,
+	pagination domain.PaginationParams,
+) (domain.StockPage, error) {
+	if classification == "" {
+		return domain.StockPage{}, errors.New("classification cannot be empty")
+	}
+
+	pagination, err := s.validatePagination(pagination)
+	if err != nil {
+		return domain.StockPage{}, err
+	}
+
+	if at, ok := domain.AsOfFrom(ctx); ok {
+		filters := domain.Filters{"classifications": {Value: classification, MatchMode: "contains"}}
+		return s.findPageAsOf(ctx, at, pagination, filters, domain.QueryOptions{})
+	}
+
+	stocks, err := s.repo.FindByClassificationPaginated(ctx, classification, pagination)
+	if err != nil {
+		return domain.StockPage{}, err
+	}
+
+	total, err := s.repo.CountByClassification(ctx, classification)
+	if err != nil {
+		return domain.StockPage{}, err
+	}
+
+	domain.HintCache(ctx, domain.CacheHint{MaxAge: domain.StockCacheMaxAge})
+	return domain.StockPage{Stocks: stocks, Total: total, Pagination: pagination}, nil
+}
+
+// validatePagination checks the pagination parameters and fills in the defaults: the
+// first page, the configured default page size, and newest first. Page sizes above the
+// configured cap are clamped to it.
+func (s *StockService) validatePagination(pagination domain.PaginationParams) (domain.PaginationParams, error) {
+	// Omitted page and page size fall back to the first page and the default size
+	if pagination.Page == 0 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize == 0 {
+		pagination.PageSize = s.limits.DefaultPageSize
+	}
+
+	// Validate page
+	if pagination.Page < 0 {
+		return pagination, fmt.Errorf("invalid page: %d (must be greater than 0)", pagination.Page)
+	}
+
+	// Validate pageSize
+	if pagination.PageSize < 0 {
+		return pagination, fmt.Errorf("invalid page size: %d (must be greater than 0)", pagination.PageSize)
+	}
+
+	// Clamp to the hard cap
+	if pagination.PageSize > s.limits.MaxPageSize {
+		pagination.PageSize = s.limits.MaxPageSize
+	}
+
+	// Values by default for optional Pagination Fields
+	if pagination.SortField == "" {
+		pagination.SortField = "time"
+	}
+
+	if pagination.SortOrder == 0 {
+		pagination.SortOrder = -1
+	}
+
+	// Validate sorting field
+	if !s.fieldValidator.IsValidField(pagination.SortField) {
+		return pagination, fmt.Errorf("invalid sort field: %s", pagination.SortField)
+	}
+
+	// Validate sort order
+	if pagination.SortOrder != 1 && pagination.SortOrder != -1 {
+		return pagination, fmt.Errorf("invalid sort order: %d (must be 'asc' or 'desc')", pagination.SortOrder)
+	}
+
+	return pagination, nil
+}
+
+// FindAllStocks returns one page of every stock in the given order. It backs the
+// recommendations, which stay valid until the next ingestion or admin write, so responses
+// built from these stocks must be revalidated rather than expire after a fixed time.
+// When ctx reads the data at a past time, the stocks are read as they stood then.
 func (s *StockService) FindAllStocks(ctx context.Context, order string, page, limit int) ([]domain.Stock, error) {
+	if at, ok := domain.AsOfFrom(ctx); ok {
+		reader, err := s.asOfReader()
+		if err != nil {
+			return nil, err
+		}
+		stocks, err := reader.FindAllAsOf(ctx, at, order, page, limit)
+		if err != nil {
+			return nil, err
+		}
+		domain.HintCache(ctx, domain.CacheHint{Revalidate: true})
+		return stocks, nil
+	}
+
 	stocks, err := s.repo.FindAll(ctx, order, page, limit)
 	if err != nil {
 		return nil, err
 	}
+	domain.HintCache(ctx, domain.CacheHint{Revalidate: true})
 	return stocks, nil
 }
 
@@ -92,8 +383,36 @@ func (s *StockService) FindStockByTicker(ctx context.Context, ticker string) (*d
 		return nil, err
 	}
 	if stock == nil {
-		return nil, errors.New("stock not found")
+		return nil, domain.ErrStockNotFound
+	}
+	return stock, nil
+}
+
+// FindLatestStockByTicker returns the most recent event of a ticker, which reflects
+// its current state. It may be cached for domain.StockCacheMaxAge. When ctx reads the
+// data at a past time, it returns the most recent event stored by then.
+func (s *StockService) FindLatestStockByTicker(ctx context.Context, ticker string) (*domain.Stock, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	if at, ok := domain.AsOfFrom(ctx); ok {
+		reader, err := s.asOfReader()
+		if err != nil {
+			return nil, err
+		}
+		stock, err := reader.FindLatestByTickerAsOf(ctx, at, ticker)
+		if err != nil {
+			return nil, err
+		}
+		domain.HintCache(ctx, domain.CacheHint{Revalidate: true})
+		return stock, nil
+	}
+
+	stock, err := s.repo.FindLatestByTicker(ctx, ticker)
+	if err != nil {
+		return nil, err
 	}
+	domain.HintCache(ctx, domain.CacheHint{MaxAge: domain.StockCacheMaxAge})
 	return stock, nil
 }
 
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// syntheticPageSize is the number of events in each page served by SyntheticAPIClient.
+const syntheticPageSize = 100
+
+// syntheticCompanies are the companies synthetic events are about, with the base target
+// price their targets are drawn around. The names cover every sector the classifier knows.
+var syntheticCompanies = []struct {
+	ticker, company string
+	price           float64
+}{
+	{"ACME", "Acme Software Inc.", 120},
+	{"NOVA", "Nova Systems Corp.", 45},
+	{"HLX", "Helix Therapeutics", 30},
+	{"MEDI", "Medina Medical Group", 85},
+	{"BNKR", "Bunker Bank Corp.", 60},
+	{"CAPX", "Capstone Capital Advisors", 22},
+	{"PETR", "Petra Petroleum Ltd.", 75},
+	{"SOLR", "Solaris Energy Inc.", 18},
+	{"GRNT", "Granite Building Products", 40},
+	{"BRWR", "Brewer Beverage Co.", 55},
+	{"ORBT", "Orbit Solutions Holdings", 95},
+	{"FARM", "Farmstead Foods Inc.", 28},
+}
+
+var (
+	syntheticBrokerages = []string{"Morgan Stanley", "Goldman Sachs", "Barclays", "JPMorgan Chase & Co.", "Wells Fargo", "Piper Sandler"}
+	syntheticActions    = []string{"upgraded by", "downgraded by", "target raised by", "target lowered by", "initiated by", "reiterated by"}
+	syntheticRatings    = []string{"Strong-Buy", "Buy", "Outperform", "Neutral", "Hold", "Underperform", "Sell"}
+)
+
+// SyntheticAPIClient is an APIClient serving generated analyst events instead of calling
+// the external API. It backs sandbox mode, where the application runs with no network
+// access or infrastructure.
+//
+// Events are drawn from a seeded generator, so a given seed always produces the same data
+// set, dated within the last 30 days.
+type SyntheticAPIClient struct {
+	total int
+	now   func() time.Time
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSyntheticAPIClient creates a client serving total events generated from seed.
+func NewSyntheticAPIClient(total int, seed int64) *SyntheticAPIClient {
+	return &SyntheticAPIClient{
+		total: total,
+		now:   time.Now,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// FetchStocks returns the page of events starting at the offset encoded in lastTicker, and
+// the cursor of the next page, which is empty after the last one.
+func (c *SyntheticAPIClient) FetchStocks(ctx context.Context, _ string, lastTicker string) ([]*domain.Stock, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	offset := 0
+	if lastTicker != "" {
+		var err error
+		if offset, err = strconv.Atoi(lastTicker); err != nil {
+			return nil, "", fmt.Errorf("invalid synthetic page cursor %q", lastTicker)
+		}
+	}
+
+	end := min(offset+syntheticPageSize, c.total)
+	if offset >= end {
+		return nil, "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now().UTC()
+	stocks := make([]*domain.Stock, 0, end-offset)
+	for range end - offset {
+		stocks = append(stocks, c.generate(now))
+	}
+
+	nextPage := ""
+	if end < c.total {
+		nextPage = strconv.Itoa(end)
+	}
+	return stocks, nextPage, nil
+}
+
+// generate draws a single analyst event. The caller must hold the lock.
+func (c *SyntheticAPIClient) generate(now time.Time) *domain.Stock {
+	company := syntheticCompanies[c.rng.Intn(len(syntheticCompanies))]
+
+	// Targets move between -30% and +60% around a price near the base price
+	from := company.price * (0.8 + 0.4*c.rng.Float64())
+	to := from * (0.7 + 0.9*c.rng.Float64())
+
+	return &domain.Stock{
+		Ticker:     company.ticker,
+		Company:    company.company,
+		TargetFrom: fmt.Sprintf("$%.2f", from),
+		TargetTo:   fmt.Sprintf("$%.2f", to),
+		Action:     syntheticActions[c.rng.Intn(len(syntheticActions))],
+		Brokerage:  syntheticBrokerages[c.rng.Intn(len(syntheticBrokerages))],
+		RatingFrom: syntheticRatings[c.rng.Intn(len(syntheticRatings))],
+		RatingTo:   syntheticRatings[c.rng.Intn(len(syntheticRatings))],
+		Time:       now.Add(-time.Duration(c.rng.Int63n(int64(domain.FreshnessHorizon)))).Truncate(time.Second),
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestSyntheticAPIClient(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	fetchAll := func(t *testing.T, client *SyntheticAPIClient) []*domain.Stock {
+		var (
+			stocks []*domain.Stock
+			cursor string
+		)
+		for {
+			page, next, err := client.FetchStocks(ctx, "", cursor)
+			require.NoError(t, err)
+			stocks = append(stocks, page...)
+			if next == "" {
+				return stocks
+			}
+			cursor = next
+		}
+	}
+
+	newClient := func() *SyntheticAPIClient {
+		client := NewSyntheticAPIClient(250, 7)
+		client.now = func() time.Time { return now }
+		return client
+	}
+
+	t.Run("should page through the configured number of valid events", func(t *testing.T) {
+		stocks := fetchAll(t, newClient())
+		require.Len(t, stocks, 250)
+
+		for _, stock := range stocks {
+			assert.NotEmpty(t, stock.Ticker)
+			assert.NotEmpty(t, stock.Company)
+			_, err := stock.GetUpside()
+			assert.NoError(t, err)
+			assert.False(t, stock.Time.After(now))
+			assert.True(t, stock.Time.After(now.Add(-domain.FreshnessHorizon)))
+		}
+	})
+
+	t.Run("should generate the same events from the same seed", func(t *testing.T) {
+		assert.Equal(t, fetchAll(t, newClient()), fetchAll(t, newClient()))
+	})
+
+	t.Run("should reject invalid cursors", func(t *testing.T) {
+		_, _, err := newClient().FetchStocks(ctx, "", "AAPL")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/dto"
+	"stock-api/infrastructure/core/port"
+)
+
+// Headers sent with every webhook delivery. Subscribers verify a delivery by computing
+// HMAC-SHA256 over "<timestamp>.<body>" with their secret and comparing it with the
+// signature header, which has the form "sha256=<hex>".
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+)
+
+// Delivery defaults.
+const (
+	defaultWebhookAttempts    = 3
+	defaultWebhookBackoff     = time.Second
+	defaultWebhookConcurrency = 8
+	defaultWebhookQueueSize   = 256
+)
+
+// WebhookDispatcher POSTs stock write events to the webhook subscriptions they match.
+// Each delivery is signed, retried with exponential backoff on network errors, 429, and
+// 5xx responses, and logged in the delivery log once it succeeds or gives up.
+//
+// Deliveries are queued and run in the background by a fixed pool of workers, so a slow
+// subscriber never blocks the event bus. When the queue is full, new deliveries are
+// dropped, counted, and logged in the delivery log as failed without any attempt.
+type WebhookDispatcher struct {
+	repo        port.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	workers     int
+	queue       chan webhookJob
+	start       sync.Once
+	inFlight    sync.WaitGroup
+	dropped     atomic.Uint64
+}
+
+// webhookJob is a delivery waiting in the queue for a worker.
+type webhookJob struct {
+	subscription domain.WebhookSubscription
+	payload      dto.WebhookPayload
+}
+
+// NewWebhookDispatcher creates a new instance of WebhookDispatcher.
+func NewWebhookDispatcher(repo port.WebhookRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: defaultWebhookAttempts,
+		backoff:     defaultWebhookBackoff,
+		workers:     defaultWebhookConcurrency,
+		queue:       make(chan webhookJob, defaultWebhookQueueSize),
+	}
+}
+
+// WithRetry overrides the number of delivery attempts and the initial backoff between them.
+func (d *WebhookDispatcher) WithRetry(maxAttempts int, backoff time.Duration) *WebhookDispatcher {
+	d.maxAttempts = maxAttempts
+	d.backoff = backoff
+	return d
+}
+
+// WithWorkers overrides the number of concurrent deliveries and the number of deliveries
+// that may wait for a worker. It must be called before the first event is handled.
+func (d *WebhookDispatcher) WithWorkers(workers, queueSize int) *WebhookDispatcher {
+	d.workers = workers
+	d.queue = make(chan webhookJob, queueSize)
+	return d
+}
+
+// HandleStockWrite queues the delivery of a stock write event to every active
+// subscription matching at least one of the written stocks. Deletions are not delivered.
+// It is meant to be subscribed to eventbus.StockWrites.
+func (d *WebhookDispatcher) HandleStockWrite(event domain.StockWriteEvent) {
+	if event.Operation == domain.WriteDelete || len(event.Stocks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	subscriptions, err := d.repo.ListSubscriptions(ctx, true)
+	cancel()
+	if err != nil {
+		log.Printf("Error loading webhook subscriptions: %v", err)
+		return
+	}
+
+	d.start.Do(func() {
+		for range d.workers {
+			go d.work()
+		}
+	})
+
+	for i := range subscriptions {
+		subscription := subscriptions[i]
+
+		var matched []domain.Stock
+		for j := range event.Stocks {
+			if subscription.Matches(&event.Stocks[j]) {
+				matched = append(matched, event.Stocks[j])
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		job := webhookJob{
+			subscription: subscription,
+			payload: dto.WebhookPayload{
+				Event:          "stock." + string(event.Operation),
+				SubscriptionID: subscription.ID,
+				Stocks:         dto.FromStocks(matched),
+				OccurredAt:     event.OccurredAt,
+			},
+		}
+
+		d.inFlight.Add(1)
+		select {
+		case d.queue <- job:
+		default:
+			d.inFlight.Done()
+			d.dropped.Add(1)
+			log.Printf("Webhook delivery queue is full, dropping %s for subscription %d", job.payload.Event, subscription.ID)
+			delivery := newWebhookDelivery(&job.payload)
+			delivery.Error = "delivery dropped: queue is full"
+			d.record(&delivery)
+		}
+	}
+}
+
+// Dropped returns the number of deliveries discarded because the queue was full.
+func (d *WebhookDispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Wait blocks until every queued delivery has finished or ctx is done.
+func (d *WebhookDispatcher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs the queued deliveries, one at a time, for the life of the process.
+func (d *WebhookDispatcher) work() {
+	for job := range d.queue {
+		d.deliver(&job.subscription, job.payload)
+		d.inFlight.Done()
+	}
+}
+
+// deliver sends the payload, retrying transient failures, and logs the outcome.
+func (d *WebhookDispatcher) deliver(subscription *domain.WebhookSubscription, payload dto.WebhookPayload) {
+	delivery := newWebhookDelivery(&payload)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		delivery.Error = fmt.Sprintf("error encoding payload: %v", err)
+	} else {
+		backoff := d.backoff
+		for delivery.Attempts < d.maxAttempts {
+			if delivery.Attempts > 0 {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+			delivery.Attempts++
+
+			var retry bool
+			delivery.StatusCode, retry, err = d.post(subscription, payload.Event, body)
+			if err == nil {
+				delivery.Delivered, delivery.Error = true, ""
+				break
+			}
+			delivery.Error = err.Error()
+			if !retry {
+				break
+			}
+		}
+	}
+
+	d.record(&delivery)
+}
+
+// newWebhookDelivery starts the delivery log entry of a payload.
+func newWebhookDelivery(payload *dto.WebhookPayload) domain.WebhookDelivery {
+	delivery := domain.WebhookDelivery{SubscriptionID: payload.SubscriptionID, Event: payload.Event}
+	for _, stock := range payload.Stocks {
+		delivery.Tickers = append(delivery.Tickers, stock.Ticker)
+	}
+	return delivery
+}
+
+// record logs the outcome of a delivery in the delivery log.
+func (d *WebhookDispatcher) record(delivery *domain.WebhookDelivery) {
+	delivery.CreatedAt = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.repo.RecordDelivery(ctx, delivery); err != nil {
+		log.Printf("Error recording webhook delivery for subscription %d: %v", delivery.SubscriptionID, err)
+	}
+}
+
+// post makes a single signed delivery attempt.
+//
+// Returns:
+//   - statusCode: The HTTP status returned by the subscriber, 0 if there was no response.
+//   - retry: True if the failure is transient and the attempt should be retried.
+//   - err: An error unless the subscriber answered with a 2xx status.
+func (d *WebhookDispatcher) post(subscription *domain.WebhookSubscription, event string, body []byte) (statusCode int, retry bool, err error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+}
+
+// GenerateWebhookSecret returns a random secret for signing the deliveries of a new
+// subscription.
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SignWebhook computes the signature header value of a delivery body.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/dto"
+	"stock-api/infrastructure/core/port"
+)
+
+// fakeWebhookRepository serves fixed subscriptions and records deliveries.
+type fakeWebhookRepository struct {
+	port.WebhookRepository
+	subscriptions []domain.WebhookSubscription
+
+	mu         sync.Mutex
+	deliveries []domain.WebhookDelivery
+}
+
+func (f *fakeWebhookRepository) ListSubscriptions(_ context.Context, _ bool) ([]domain.WebhookSubscription, error) {
+	return f.subscriptions, nil
+}
+
+func (f *fakeWebhookRepository) RecordDelivery(_ context.Context, delivery *domain.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries = append(f.deliveries, *delivery)
+	return nil
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	event := domain.StockWriteEvent{
+		Operation: domain.WriteBatch,
+		Stocks: []domain.Stock{
+			{Ticker: "AAPL", Classifications: domain.StringArray{"Tech"}},
+			{Ticker: "MSFT", Classifications: domain.StringArray{"Bullish Signal"}},
+			{Ticker: "XOM", Classifications: domain.StringArray{"Energy"}},
+		},
+		OccurredAt: time.Now().UTC(),
+	}
+
+	dispatch := func(t *testing.T, repo *fakeWebhookRepository, event domain.StockWriteEvent) {
+		dispatcher := NewWebhookDispatcher(repo).WithRetry(3, time.Millisecond)
+		dispatcher.HandleStockWrite(event)
+		assert.NoError(t, dispatcher.Wait(context.Background()))
+	}
+
+	t.Run("should deliver signed payloads with the matching stocks", func(t *testing.T) {
+		var payload dto.WebhookPayload
+		var validSignature bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			expected := SignWebhook("s3cret", r.Header.Get(WebhookTimestampHeader), body)
+			validSignature = r.Header.Get(WebhookSignatureHeader) == expected
+			_ = json.Unmarshal(body, &payload)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		repo := &fakeWebhookRepository{subscriptions: []domain.WebhookSubscription{{
+			ID:              1,
+			URL:             server.URL,
+			Secret:          "s3cret",
+			Tickers:         pq.StringArray{"AAPL"},
+			Classifications: pq.StringArray{"Bullish Signal"},
+		}}}
+		dispatch(t, repo, event)
+
+		assert.True(t, validSignature)
+		assert.Equal(t, "stock.batch", payload.Event)
+		assert.Len(t, payload.Stocks, 2)
+		assert.Len(t, repo.deliveries, 1)
+		assert.True(t, repo.deliveries[0].Delivered)
+		assert.Equal(t, pq.StringArray{"AAPL", "MSFT"}, repo.deliveries[0].Tickers)
+	})
+
+	t.Run("should retry transient failures", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		repo := &fakeWebhookRepository{subscriptions: []domain.WebhookSubscription{{ID: 1, URL: server.URL, Tickers: pq.StringArray{"AAPL"}}}}
+		dispatch(t, repo, event)
+
+		assert.Equal(t, int32(3), calls.Load())
+		assert.True(t, repo.deliveries[0].Delivered)
+		assert.Equal(t, 3, repo.deliveries[0].Attempts)
+	})
+
+	t.Run("should log permanent failures without retrying", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		repo := &fakeWebhookRepository{subscriptions: []domain.WebhookSubscription{{ID: 1, URL: server.URL, Tickers: pq.StringArray{"AAPL"}}}}
+		dispatch(t, repo, event)
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.False(t, repo.deliveries[0].Delivered)
+		assert.Equal(t, http.StatusGone, repo.deliveries[0].StatusCode)
+		assert.NotEmpty(t, repo.deliveries[0].Error)
+	})
+
+	t.Run("should drop and log deliveries when the queue is full", func(t *testing.T) {
+		received := make(chan struct{}, 3)
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		repo := &fakeWebhookRepository{subscriptions: []domain.WebhookSubscription{{ID: 1, URL: server.URL, Tickers: pq.StringArray{"AAPL"}}}}
+		dispatcher := NewWebhookDispatcher(repo).WithWorkers(1, 1)
+		dispatcher.HandleStockWrite(event)
+		<-received // The only worker is busy
+		dispatcher.HandleStockWrite(event)
+		dispatcher.HandleStockWrite(event)
+		close(release)
+		assert.NoError(t, dispatcher.Wait(context.Background()))
+
+		assert.Equal(t, uint64(1), dispatcher.Dropped())
+		assert.Len(t, repo.deliveries, 3)
+		dropped := repo.deliveries[0]
+		assert.False(t, dropped.Delivered)
+		assert.Zero(t, dropped.Attempts)
+		assert.Contains(t, dropped.Error, "queue is full")
+	})
+
+	t.Run("should skip deletions and unmatched subscriptions", func(t *testing.T) {
+		repo := &fakeWebhookRepository{subscriptions: []domain.WebhookSubscription{
+			{ID: 1, URL: "http://127.0.0.1:1", Tickers: pq.StringArray{"TSLA"}},
+		}}
+		dispatch(t, repo, event)
+
+		deletion := event
+		deletion.Operation = domain.WriteDelete
+		repo.subscriptions[0].Tickers = pq.StringArray{"AAPL"}
+		dispatch(t, repo, deletion)
+
+		assert.Empty(t, repo.deliveries)
+	})
+}
@@ -0,0 +1,213 @@
+// Package eventbus provides a small in-process publish/subscribe bus with
+// typed topics. Each subscriber owns a buffered queue drained by its own
+// goroutine, so a slow subscriber never blocks publishers or other subscribers:
+// when its queue is full, new events for it are dropped and counted.
+package eventbus
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// DefaultBufferSize is the per-subscriber queue size used when New receives a
+// non-positive value.
+const DefaultBufferSize = 256
+
+// Topic identifies a stream of events whose payloads are of type T.
+// Using a typed topic guarantees at compile time that publishers and
+// subscribers agree on the payload type.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic creates a topic with the given name.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the topic name.
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Topics published by the application.
+var (
+	// StockWrites receives an event after every successful write to the stocks table.
+	StockWrites = NewTopic[domain.StockWriteEvent]("stocks.write")
+	// IngestionCompleted receives an event when the ingestion pipeline finishes a run.
+	IngestionCompleted = NewTopic[domain.IngestionRunEvent]("ingestion.completed")
+)
+
+// Bus fans out published events to the subscribers of each topic.
+// A nil *Bus is valid and silently discards every publish and subscribe call,
+// which lets components treat the bus as optional.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[uint64]*subscriber
+	nextID      uint64
+	bufferSize  int
+	closed      bool
+	wg          sync.WaitGroup
+	dropped     atomic.Uint64
+}
+
+type subscriber struct {
+	id      uint64
+	topic   string
+	events  chan any
+	handler func(any)
+}
+
+// New creates a Bus whose subscribers buffer up to bufferSize pending events.
+func New(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Bus{
+		subscribers: make(map[string]map[uint64]*subscriber),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish delivers payload to every subscriber of topic without blocking.
+// Subscribers whose queue is full miss the event; see Bus.Dropped.
+func Publish[T any](b *Bus, topic Topic[T], payload T) {
+	if b == nil {
+		return
+	}
+	b.publish(topic.name, payload)
+}
+
+// Subscribe registers handler for the events published on topic.
+// Handlers run sequentially on a goroutine dedicated to the subscription.
+// It returns a function that cancels the subscription; events already queued
+// are still delivered.
+func Subscribe[T any](b *Bus, topic Topic[T], handler func(T)) (unsubscribe func()) {
+	if b == nil {
+		return func() {}
+	}
+	return b.subscribe(topic.name, func(payload any) {
+		if event, ok := payload.(T); ok {
+			handler(event)
+		}
+	})
+}
+
+// Dropped returns the number of events discarded because a subscriber was too slow.
+func (b *Bus) Dropped() uint64 {
+	if b == nil {
+		return 0
+	}
+	return b.dropped.Load()
+}
+
+// Close stops accepting new events and waits until every subscriber has
+// drained its queue, or until ctx is done, whichever comes first.
+func (b *Bus) Close(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for topic, subs := range b.subscribers {
+			for _, sub := range subs {
+				close(sub.events)
+			}
+			delete(b.subscribers, topic)
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) publish(topic string, payload any) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, sub := range b.subscribers[topic] {
+		select {
+		case sub.events <- payload:
+		default:
+			b.dropped.Add(1)
+			log.Printf("eventbus: subscriber %d on %q is too slow, dropping event", sub.id, topic)
+		}
+	}
+}
+
+func (b *Bus) subscribe(topic string, handler func(any)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return func() {}
+	}
+
+	b.nextID++
+	sub := &subscriber{
+		id:      b.nextID,
+		topic:   topic,
+		events:  make(chan any, b.bufferSize),
+		handler: handler,
+	}
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[uint64]*subscriber)
+	}
+	b.subscribers[topic][sub.id] = sub
+
+	b.wg.Add(1)
+	go sub.run(&b.wg)
+
+	return func() { b.unsubscribe(sub) }
+}
+
+func (b *Bus) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub.topic][sub.id]; !ok {
+		return // Already removed by Close or a previous call
+	}
+	delete(b.subscribers[sub.topic], sub.id)
+	close(sub.events)
+}
+
+// run drains the subscriber queue until it is closed.
+func (s *subscriber) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for payload := range s.events {
+		s.invoke(payload)
+	}
+}
+
+// invoke calls the handler, recovering from panics so a faulty subscriber
+// cannot take the process down.
+func (s *subscriber) invoke(payload any) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("eventbus: subscriber %d on %q panicked: %v", s.id, s.topic, r)
+		}
+	}()
+	s.handler(payload)
+}
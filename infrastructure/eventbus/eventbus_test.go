@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	topic := NewTopic[int]("numbers")
+
+	t.Run("should fan out events to every subscriber", func(t *testing.T) {
+		bus := New(10)
+
+		var mu sync.Mutex
+		received := map[string][]int{}
+		Subscribe(bus, topic, func(n int) {
+			mu.Lock()
+			defer mu.Unlock()
+			received["a"] = append(received["a"], n)
+		})
+		Subscribe(bus, topic, func(n int) {
+			mu.Lock()
+			defer mu.Unlock()
+			received["b"] = append(received["b"], n)
+		})
+
+		Publish(bus, topic, 1)
+		Publish(bus, topic, 2)
+
+		assert.NoError(t, bus.Close(context.Background()))
+		assert.Equal(t, []int{1, 2}, received["a"])
+		assert.Equal(t, []int{1, 2}, received["b"])
+	})
+
+	t.Run("should drop events for slow subscribers", func(t *testing.T) {
+		bus := New(1)
+
+		release := make(chan struct{})
+		Subscribe(bus, topic, func(int) { <-release })
+
+		for i := 0; i < 5; i++ {
+			Publish(bus, topic, i)
+		}
+		close(release)
+
+		assert.NoError(t, bus.Close(context.Background()))
+		assert.NotZero(t, bus.Dropped())
+	})
+
+	t.Run("should drain queued events on close", func(t *testing.T) {
+		bus := New(10)
+
+		var count int
+		Subscribe(bus, topic, func(int) {
+			time.Sleep(5 * time.Millisecond)
+			count++
+		})
+		for i := 0; i < 5; i++ {
+			Publish(bus, topic, i)
+		}
+
+		assert.NoError(t, bus.Close(context.Background()))
+		assert.Equal(t, 5, count)
+	})
+
+	t.Run("should ignore events published after close", func(t *testing.T) {
+		bus := New(10)
+
+		var count int
+		Subscribe(bus, topic, func(int) { count++ })
+		assert.NoError(t, bus.Close(context.Background()))
+
+		Publish(bus, topic, 1)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("should stop delivering after unsubscribe", func(t *testing.T) {
+		bus := New(10)
+
+		var count int
+		unsubscribe := Subscribe(bus, topic, func(int) { count++ })
+		unsubscribe()
+		unsubscribe() // Idempotent
+
+		Publish(bus, topic, 1)
+		assert.NoError(t, bus.Close(context.Background()))
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("should treat a nil bus as a no-op", func(t *testing.T) {
+		var bus *Bus
+		Subscribe(bus, topic, func(int) {})
+		Publish(bus, topic, 1)
+		assert.NoError(t, bus.Close(context.Background()))
+	})
+}
@@ -0,0 +1,84 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+// cacheable reports whether a response may carry the caching policy hinted by the
+// services: successful reads only, so writes and errors are never cached.
+func cacheable(ctx *gin.Context, status int) bool {
+	if ctx.Request == nil || status != http.StatusOK {
+		return false
+	}
+	method := ctx.Request.Method
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// writeCacheable writes a successful read with the Cache-Control and ETag headers
+// derived from the cache hints given while it was built. It answers 304 Not Modified when
+// the client already holds the same body, per If-None-Match.
+// It reports false, writing nothing, if no hint was given.
+func writeCacheable(ctx *gin.Context, status int, body JsonResponse) bool {
+	hint, ok := domain.CacheHintFrom(ctx.Request.Context())
+	if !ok {
+		return false
+	}
+
+	header := ctx.Writer.Header()
+	header.Set("Cache-Control", cacheControl(hint))
+	if hint.NoStore {
+		ctx.IndentedJSON(status, body)
+		return true
+	}
+
+	// Same encoding as IndentedJSON, so the tag matches the bytes sent
+	data, err := json.MarshalIndent(body, "", "    ")
+	if err != nil {
+		ctx.IndentedJSON(status, body)
+		return true
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	header.Set("ETag", etag)
+
+	if matchesETag(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return true
+	}
+	ctx.Data(status, "application/json; charset=utf-8", data)
+	return true
+}
+
+// cacheControl formats a cache hint as a Cache-Control header value.
+func cacheControl(hint domain.CacheHint) string {
+	switch {
+	case hint.NoStore:
+		return "no-store"
+	case hint.Revalidate || hint.MaxAge <= 0:
+		return "no-cache"
+	default:
+		return fmt.Sprintf("max-age=%d", int(hint.MaxAge.Seconds()))
+	}
+}
+
+// matchesETag reports whether an If-None-Match header value lists etag. Weak tags are
+// compared by their opaque part, as the weak comparison of RFC 9110 requires.
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,29 +1,92 @@
 package response
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type JsonResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success  bool        `json:"success"`
+	Message  string      `json:"message,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Warnings []string    `json:"warnings,omitempty"`
+	Sandbox  bool        `json:"sandbox,omitempty"`
+	AsOf     string      `json:"asOf,omitempty"`
 }
 
+// Warning codes sent in the Warning header (RFC 7234, section 5.5).
+const (
+	WarnStale         = 110 // Response is Stale
+	WarnMiscellaneous = 199 // Miscellaneous Warning
+)
+
+// Context keys holding the response flags of the current request.
+const (
+	warningsKey = "response.warnings"
+	sandboxKey  = "response.sandbox"
+	asOfKey     = "response.asOf"
+)
+
+// Warn flags a degraded response, such as one built from a fallback path. The warning is
+// sent as a Warning header and listed in the "warnings" field of the response body, so
+// clients can tell users the data may be delayed or incomplete.
+// It must be called before the response is written.
+func Warn(ctx *gin.Context, code int, text string) {
+	ctx.Writer.Header().Add("Warning", fmt.Sprintf("%d - %q", code, text))
+	ctx.Set(warningsKey, append(ctx.GetStringSlice(warningsKey), text))
+}
+
+// MarkStale flags a response that includes cached data older than usual. Besides the
+// Warning header, it sets X-Stale to true.
+func MarkStale(ctx *gin.Context, text string) {
+	ctx.Header("X-Stale", "true")
+	Warn(ctx, WarnStale, text)
+}
+
+// MarkSandbox flags a response served by an instance running in sandbox mode, whose data
+// is synthetic. It sets X-Sandbox to true and the "sandbox" field of the response body.
+// It must be called before the response is written.
+func MarkSandbox(ctx *gin.Context) {
+	ctx.Header("X-Sandbox", "true")
+	ctx.Set(sandboxKey, true)
+}
+
+// MarkAsOf flags a response built from the data as it stood at a past time. It sets
+// X-As-Of and the "asOf" field of the response body to that time, in RFC 3339 format, so
+// clients can tell which time a date-only asOf resolved to.
+// It must be called before the response is written.
+func MarkAsOf(ctx *gin.Context, at time.Time) {
+	formatted := at.UTC().Format(time.RFC3339Nano)
+	ctx.Header("X-As-Of", formatted)
+	ctx.Set(asOfKey, formatted)
+}
+
+// Success writes a successful response. Reads built from data the services hinted as
+// cacheable (see domain.HintCache) carry the matching Cache-Control and ETag headers, and
+// are answered with 304 Not Modified when the client's copy is current.
 func Success(ctx *gin.Context, status int, data interface{}) {
-	ctx.IndentedJSON(status, JsonResponse{
-		Success: true,
-		Data:    data,
-	})
+	body := JsonResponse{
+		Success:  true,
+		Data:     data,
+		Warnings: ctx.GetStringSlice(warningsKey),
+		Sandbox:  ctx.GetBool(sandboxKey),
+		AsOf:     ctx.GetString(asOfKey),
+	}
+	if cacheable(ctx, status) && writeCacheable(ctx, status, body) {
+		return
+	}
+	ctx.IndentedJSON(status, body)
 }
 
 func Error(ctx *gin.Context, status int, err string) {
 	ctx.IndentedJSON(status, JsonResponse{
 		Success: false,
 		Error:   err,
+		Sandbox: ctx.GetBool(sandboxKey),
 	})
 }
 
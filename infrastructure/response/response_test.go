@@ -0,0 +1,148 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestWarnings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should surface warnings in headers and body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		Warn(c, WarnMiscellaneous, "totalRecords is an estimate")
+		MarkStale(c, "totalRecords was served from cache and may be delayed")
+		Success(c, http.StatusOK, "data")
+
+		assert.Equal(t, []string{
+			`199 - "totalRecords is an estimate"`,
+			`110 - "totalRecords was served from cache and may be delayed"`,
+		}, w.Header().Values("Warning"))
+		assert.Equal(t, "true", w.Header().Get("X-Stale"))
+
+		var body JsonResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, []string{
+			"totalRecords is an estimate",
+			"totalRecords was served from cache and may be delayed",
+		}, body.Warnings)
+	})
+
+	t.Run("should omit warnings from regular responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		Success(c, http.StatusOK, "data")
+
+		assert.Empty(t, w.Header().Values("Warning"))
+		assert.Empty(t, w.Header().Get("X-Stale"))
+		assert.NotContains(t, w.Body.String(), "warnings")
+	})
+}
+
+func TestMarkSandbox(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should label sandbox responses in headers and body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		MarkSandbox(c)
+		Error(c, http.StatusNotFound, "Stock not found")
+
+		assert.Equal(t, "true", w.Header().Get("X-Sandbox"))
+
+		var body JsonResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Sandbox)
+	})
+
+	t.Run("should omit the sandbox flag from regular responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		Success(c, http.StatusOK, "data")
+
+		assert.Empty(t, w.Header().Get("X-Sandbox"))
+		assert.NotContains(t, w.Body.String(), "sandbox")
+	})
+}
+
+func TestCacheHints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	respond := func(method, ifNoneMatch string, hints ...domain.CacheHint) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/api/v1/stocks", nil)
+		c.Request = c.Request.WithContext(domain.WithCacheHints(c.Request.Context()))
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		for _, hint := range hints {
+			domain.HintCache(c.Request.Context(), hint)
+		}
+		Success(c, http.StatusOK, "data")
+		return w
+	}
+
+	t.Run("should set the hinted lifetime and an ETag", func(t *testing.T) {
+		w := respond(http.MethodGet, "", domain.CacheHint{MaxAge: 5 * time.Minute})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "max-age=300", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+
+		var body JsonResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "data", body.Data)
+	})
+
+	t.Run("should keep the most restrictive of several hints", func(t *testing.T) {
+		w := respond(http.MethodGet, "",
+			domain.CacheHint{MaxAge: 5 * time.Minute},
+			domain.CacheHint{MaxAge: 30 * time.Second},
+		)
+		assert.Equal(t, "max-age=30", w.Header().Get("Cache-Control"))
+
+		w = respond(http.MethodGet, "", domain.CacheHint{MaxAge: time.Minute}, domain.CacheHint{Revalidate: true})
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+		w = respond(http.MethodGet, "", domain.CacheHint{Revalidate: true}, domain.CacheHint{NoStore: true})
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("should answer 304 when the client holds the current body", func(t *testing.T) {
+		etag := respond(http.MethodGet, "", domain.CacheHint{Revalidate: true}).Header().Get("ETag")
+
+		w := respond(http.MethodGet, `"other", W/`+etag, domain.CacheHint{Revalidate: true})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+
+		w = respond(http.MethodGet, `"other"`, domain.CacheHint{Revalidate: true})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not cache unhinted responses or writes", func(t *testing.T) {
+		w := respond(http.MethodGet, "")
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+
+		w = respond(http.MethodPost, "", domain.CacheHint{MaxAge: time.Minute})
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+}
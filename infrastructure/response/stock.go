@@ -1,31 +1,54 @@
 package response
 
 import (
-	"time"
+	"math"
 
 	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/dto"
 )
 
 // StockResponse representa la estructura esperada por el frontend
 type StockResponse struct {
 	Items        []StockItem `json:"items"`
 	Page         int         `json:"page"`
+	PageSize     int         `json:"pageSize,omitempty"`
 	TotalRecords int         `json:"totalRecords,omitempty"`
+	Approximate  bool        `json:"approximate,omitempty"` // TotalRecords is an estimate
+	Stale        bool        `json:"stale,omitempty"`       // TotalRecords was served from an old cache entry
 	OrderBy      string      `json:"order_by"`
 }
 
 // StockItem es la representación Go de tu interfaz TypeScript
-type StockItem struct {
-	Ticker          string   `json:"ticker"`
-	TargetFrom      string   `json:"target_from"`
-	TargetTo        string   `json:"target_to"`
-	Company         string   `json:"company"`
-	Action          string   `json:"action"`
-	Brokerage       string   `json:"brokerage"`
-	RatingFrom      string   `json:"rating_from"`
-	RatingTo        string   `json:"rating_to"`
-	Time            string   `json:"time"`
-	Classifications []string `json:"classifications"`
+type StockItem = dto.StockItem
+
+// CompactStockResponse is the abbreviated list response used by mobile clients
+// (?compact=true). It carries the same metadata as StockResponse.
+type CompactStockResponse struct {
+	Items        []CompactStockItem `json:"items"`
+	Page         int                `json:"page"`
+	PageSize     int                `json:"pageSize,omitempty"`
+	TotalRecords int                `json:"totalRecords,omitempty"`
+	Approximate  bool               `json:"approximate,omitempty"` // TotalRecords is an estimate
+	Stale        bool               `json:"stale,omitempty"`       // TotalRecords was served from an old cache entry
+	OrderBy      string             `json:"order_by"`
+}
+
+// CompactStockItem holds the fields a stock list needs at a glance.
+// Upside is omitted when the target prices cannot be parsed, and Classification is the
+// stock's highest-scoring label.
+type CompactStockItem struct {
+	Ticker         string   `json:"ticker"`
+	Company        string   `json:"company"`
+	RatingTo       string   `json:"rating_to"`
+	Upside         *float64 `json:"upside,omitempty"`
+	Classification string   `json:"classification,omitempty"`
+}
+
+// CountResponse is the response of count-only list queries (?countOnly=true).
+type CountResponse struct {
+	TotalRecords int  `json:"totalRecords"`
+	Approximate  bool `json:"approximate,omitempty"` // TotalRecords is an estimate
+	Stale        bool `json:"stale,omitempty"`       // TotalRecords was served from an old cache entry
 }
 
 func ToStockResponse(
@@ -34,28 +57,68 @@ func ToStockResponse(
 	totalRecords int,
 	orderBy string,
 ) StockResponse {
-	items := make([]StockItem, len(stocks))
+	return StockResponse{
+		Items:        ToStockItems(stocks),
+		Page:         page,
+		TotalRecords: totalRecords,
+		OrderBy:      orderBy,
+	}
+}
+
+// ToStockItem maps a domain stock to its client representation.
+func ToStockItem(stock *domain.Stock) StockItem {
+	return dto.FromStock(stock)
+}
+
+// ToStockItems maps a slice of domain stocks to their client representation.
+func ToStockItems(stocks []domain.Stock) []StockItem {
+	return dto.FromStocks(stocks)
+}
+
+// ToCompactStockResponse maps stocks to the compact list representation. The top
+// classification of each stock is the one given the most points.
+func ToCompactStockResponse(
+	stocks []domain.Stock,
+	points map[string]float64,
+	page int,
+	totalRecords int,
+	orderBy string,
+) CompactStockResponse {
+	items := make([]CompactStockItem, len(stocks))
 
 	for i := range stocks {
 		stock := &stocks[i]
-		items[i] = StockItem{
-			Ticker:          stock.Ticker,
-			TargetFrom:      stock.TargetFrom,
-			TargetTo:        stock.TargetTo,
-			Company:         stock.Company,
-			Action:          stock.Action,
-			Brokerage:       stock.Brokerage,
-			RatingFrom:      stock.RatingFrom,
-			RatingTo:        stock.RatingTo,
-			Time:            stock.Time.Format(time.RFC3339), // Formato estándar
-			Classifications: stock.Classifications,
+		items[i] = CompactStockItem{
+			Ticker:         stock.Ticker,
+			Company:        stock.Company,
+			RatingTo:       stock.RatingTo,
+			Classification: topClassification(stock.Classifications, points),
+		}
+		if upside, err := stock.GetUpside(); err == nil {
+			rounded := math.Round(upside*100) / 100
+			items[i].Upside = &rounded
 		}
 	}
 
-	return StockResponse{
+	return CompactStockResponse{
 		Items:        items,
 		Page:         page,
 		TotalRecords: totalRecords,
 		OrderBy:      orderBy,
 	}
 }
+
+// topClassification returns the label given the most points, the one contributing the
+// most to the score. Without any scoring label, the first label is returned.
+func topClassification(labels []string, points map[string]float64) string {
+	top, topPoints := "", 0.0
+	for _, label := range labels {
+		if labelPoints := points[label]; labelPoints > topPoints {
+			top, topPoints = label, labelPoints
+		}
+	}
+	if top == "" && len(labels) > 0 {
+		return labels[0]
+	}
+	return top
+}
@@ -0,0 +1,88 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestStockResponseShapes(t *testing.T) {
+	stocks := []domain.Stock{
+		{
+			Ticker:          "AAPL",
+			TargetFrom:      "$100.00",
+			TargetTo:        "$133.333",
+			Company:         "Apple Inc.",
+			Action:          "upgraded by",
+			Brokerage:       "Acme",
+			RatingFrom:      "Hold",
+			RatingTo:        "Buy",
+			Time:            time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Classifications: domain.StringArray{"Tech", "Potential Growth"},
+		},
+		{
+			Ticker:          "XYZ",
+			TargetFrom:      "n/a",
+			Company:         "Xyz Corp.",
+			RatingTo:        "Sell",
+			Classifications: domain.StringArray{"Analyst Negative"},
+		},
+	}
+
+	t.Run("should map every field in the full shape", func(t *testing.T) {
+		resp := ToStockResponse(stocks, 1, 2, "ticker")
+
+		assert.Len(t, resp.Items, 2)
+		assert.Equal(t, StockItem{
+			Ticker:          "AAPL",
+			TargetFrom:      "$100.00",
+			TargetTo:        "$133.333",
+			Company:         "Apple Inc.",
+			Action:          "upgraded by",
+			Brokerage:       "Acme",
+			RatingFrom:      "Hold",
+			RatingTo:        "Buy",
+			Time:            "2025-01-02T03:04:05Z",
+			Classifications: []string{"Tech", "Potential Growth"},
+		}, resp.Items[0])
+		assert.Equal(t, 2, resp.TotalRecords)
+	})
+
+	t.Run("should include the ID of stored stocks", func(t *testing.T) {
+		stored := stocks[0]
+		stored.ID = 7
+
+		assert.Equal(t, uint(7), ToStockItem(&stored).ID)
+		b, _ := json.Marshal(ToStockItem(&stocks[1]))
+		assert.NotContains(t, string(b), `"id"`)
+	})
+
+	t.Run("should map the abbreviated fields in the compact shape", func(t *testing.T) {
+		resp := ToCompactStockResponse(stocks, domain.ClassificationPoints, 1, 2, "ticker")
+
+		upside := 33.33
+		assert.Equal(t, []CompactStockItem{
+			{Ticker: "AAPL", Company: "Apple Inc.", RatingTo: "Buy", Upside: &upside, Classification: "Potential Growth"},
+			{Ticker: "XYZ", Company: "Xyz Corp.", RatingTo: "Sell", Classification: "Analyst Negative"},
+		}, resp.Items)
+		assert.Equal(t, 2, resp.TotalRecords)
+		assert.Equal(t, "ticker", resp.OrderBy)
+	})
+
+	t.Run("should pick the top classification with the given points", func(t *testing.T) {
+		resp := ToCompactStockResponse(stocks[:1], map[string]float64{"Tech": 50, "Potential Growth": 10}, 1, 1, "ticker")
+
+		assert.Equal(t, "Tech", resp.Items[0].Classification)
+	})
+
+	t.Run("should omit unavailable compact fields from the JSON", func(t *testing.T) {
+		b, err := json.Marshal(CompactStockItem{Ticker: "XYZ", Company: "Xyz Corp.", RatingTo: "Sell"})
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"ticker":"XYZ","company":"Xyz Corp.","rating_to":"Sell"}`, string(b))
+	})
+}
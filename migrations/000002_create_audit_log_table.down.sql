@@ -0,0 +1,7 @@
+-- Drop indexes if they exist
+DROP INDEX IF EXISTS idx_audit_log_actor;
+
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+
+-- Drop the table audit_log if it exists
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,17 @@
+CREATE TABLE
+    audit_log (
+        id SERIAL PRIMARY KEY,
+        actor VARCHAR(100) NOT NULL,
+        action VARCHAR(100) NOT NULL,
+        method VARCHAR(10) NOT NULL,
+        path VARCHAR(255) NOT NULL,
+        status INT NOT NULL,
+        details TEXT,
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1,7 @@
+-- Drop indexes if they exist
+DROP INDEX IF EXISTS idx_shadow_classifications_stock_id;
+
+DROP INDEX IF EXISTS idx_shadow_classifications_classifier;
+
+-- Drop the table shadow_classifications if it exists
+DROP TABLE IF EXISTS shadow_classifications;
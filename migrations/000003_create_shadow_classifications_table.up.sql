@@ -0,0 +1,15 @@
+CREATE TABLE
+    shadow_classifications (
+        id SERIAL PRIMARY KEY,
+        stock_id INT NOT NULL,
+        ticker VARCHAR(10) NOT NULL,
+        classifier VARCHAR(50) NOT NULL,
+        labels TEXT[] DEFAULT '{}',
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE INDEX idx_shadow_classifications_stock_id ON shadow_classifications (stock_id);
+
+CREATE INDEX idx_shadow_classifications_classifier ON shadow_classifications (classifier);
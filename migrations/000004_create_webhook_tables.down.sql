@@ -0,0 +1,9 @@
+-- Drop indexes if they exist
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription_id;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_created_at;
+
+-- Drop the tables webhook_deliveries and webhook_subscriptions if they exist
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhook_subscriptions;
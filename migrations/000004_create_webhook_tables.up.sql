@@ -0,0 +1,34 @@
+CREATE TABLE
+    webhook_subscriptions (
+        id SERIAL PRIMARY KEY,
+        url VARCHAR(2048) NOT NULL,
+        secret VARCHAR(128) NOT NULL,
+        tickers TEXT[] DEFAULT '{}',
+        classifications TEXT[] DEFAULT '{}',
+        active BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL,
+            updated_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE TABLE
+    webhook_deliveries (
+        id SERIAL PRIMARY KEY,
+        subscription_id INT NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+        event VARCHAR(50) NOT NULL,
+        tickers TEXT[] DEFAULT '{}',
+        attempts INT NOT NULL,
+        status_code INT,
+        error TEXT,
+        delivered BOOLEAN NOT NULL,
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
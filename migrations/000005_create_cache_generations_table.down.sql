@@ -0,0 +1,2 @@
+-- Drop the table cache_generations if it exists
+DROP TABLE IF EXISTS cache_generations;
@@ -0,0 +1,10 @@
+CREATE TABLE
+    cache_generations (
+        name VARCHAR(50) PRIMARY KEY,
+        generation BIGINT NOT NULL DEFAULT 0
+    );
+
+INSERT INTO
+    cache_generations (name, generation)
+VALUES
+    ('stocks', 0);
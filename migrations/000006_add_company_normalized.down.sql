@@ -0,0 +1,6 @@
+-- Drop index if it exists
+DROP INDEX IF EXISTS idx_stocks_company_normalized;
+
+-- Drop the column company_normalized if it exists
+ALTER TABLE stocks
+DROP COLUMN IF EXISTS company_normalized;
@@ -0,0 +1,6 @@
+-- Searchable company name, filled by the application on write. Existing rows are
+-- backfilled by the application after the migrations run.
+ALTER TABLE stocks
+ADD COLUMN company_normalized VARCHAR(255);
+
+CREATE INDEX idx_stocks_company_normalized ON stocks (company_normalized);
@@ -0,0 +1,5 @@
+-- Drop index if it exists
+DROP INDEX IF EXISTS idx_score_history_ticker_computed_at;
+
+-- Drop the table score_history if it exists
+DROP TABLE IF EXISTS score_history;
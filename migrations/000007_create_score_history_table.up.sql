@@ -0,0 +1,19 @@
+CREATE TABLE
+    score_history (
+        id SERIAL PRIMARY KEY,
+        ticker VARCHAR(10) NOT NULL,
+        stock_id INT NOT NULL,
+        score DECIMAL NOT NULL,
+        upside_points DECIMAL NOT NULL,
+        classification_points DECIMAL NOT NULL,
+        rating_points DECIMAL NOT NULL,
+        risk_score DECIMAL NOT NULL,
+        event_time TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL,
+            computed_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE INDEX idx_score_history_ticker_computed_at ON score_history (ticker, computed_at);
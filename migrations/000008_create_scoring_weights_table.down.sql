@@ -0,0 +1,8 @@
+-- Drop index if it exists
+DROP INDEX IF EXISTS idx_scoring_weights_version;
+
+-- Drop the table scoring_weight_versions if it exists
+DROP TABLE IF EXISTS scoring_weight_versions;
+
+-- Drop the table scoring_weights if it exists
+DROP TABLE IF EXISTS scoring_weights;
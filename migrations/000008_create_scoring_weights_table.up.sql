@@ -0,0 +1,39 @@
+CREATE TABLE
+    scoring_weights (
+        id SERIAL PRIMARY KEY,
+        version INT NOT NULL,
+        label VARCHAR(50) NOT NULL,
+        points DECIMAL NOT NULL,
+        removed BOOLEAN NOT NULL DEFAULT FALSE,
+        actor VARCHAR(100) NOT NULL,
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX idx_scoring_weights_version ON scoring_weights (version);
+
+-- One row per version, so concurrent appends cannot share a version number
+CREATE TABLE
+    scoring_weight_versions (
+        version INT PRIMARY KEY,
+        created_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL DEFAULT now()
+    );
+
+-- Version 1 holds the weights built into the application so far
+INSERT INTO
+    scoring_weight_versions (version)
+VALUES
+    (1);
+
+INSERT INTO
+    scoring_weights (version, label, points, actor)
+VALUES
+    (1, 'Potential Growth', 30, 'system'),
+    (1, 'Bullish Signal', 25, 'system'),
+    (1, 'New Coverage', 20, 'system'),
+    (1, 'Analyst Positive', 15, 'system'),
+    (1, 'Tech', 10, 'system'),
+    (1, 'Biotech', 8, 'system');
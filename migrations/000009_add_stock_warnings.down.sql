@@ -0,0 +1,3 @@
+-- Drop the column warnings if it exists
+ALTER TABLE stocks
+DROP COLUMN IF EXISTS warnings;
@@ -0,0 +1,4 @@
+-- Soft validation warnings raised by the stock when it was written (e.g.
+-- unknown_rating). Rows written before this migration have none.
+ALTER TABLE stocks
+ADD COLUMN warnings TEXT[];
@@ -0,0 +1,5 @@
+-- Drop index if it exists
+DROP INDEX IF EXISTS idx_stock_revisions_stock_id_revised_at;
+
+-- Drop the table stock_revisions if it exists
+DROP TABLE IF EXISTS stock_revisions;
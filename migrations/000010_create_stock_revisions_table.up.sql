@@ -0,0 +1,31 @@
+-- Values stock rows had before each update in place, written in the same transaction as
+-- the update, so reads of a past time (asOf) see the values rows had then. A revision
+-- holds the values a row had until revised_at. Rows updated before this migration have
+-- no revisions for those updates.
+CREATE TABLE
+    stock_revisions (
+        id SERIAL PRIMARY KEY,
+        stock_id INT NOT NULL,
+        revised_at TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL,
+            updated_at TIMESTAMP
+        WITH
+            TIME ZONE,
+            ticker VARCHAR(10) NOT NULL,
+            target_from VARCHAR(20),
+            target_to VARCHAR(20),
+            company VARCHAR(255) NOT NULL,
+            company_normalized VARCHAR(255),
+            action VARCHAR(100),
+            brokerage VARCHAR(255) NOT NULL,
+            rating_from VARCHAR(50),
+            rating_to VARCHAR(50),
+            classifications TEXT[],
+            warnings TEXT[],
+            time TIMESTAMP
+        WITH
+            TIME ZONE NOT NULL
+    );
+
+CREATE INDEX idx_stock_revisions_stock_id_revised_at ON stock_revisions (stock_id, revised_at);
@@ -0,0 +1,87 @@
+//go:build contract
+
+package contract
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migrate_driver "github.com/golang-migrate/migrate/v4/database/cockroachdb"
+	_ "github.com/golang-migrate/migrate/v4/source/file" // Import the file source driver
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"stock-api/infrastructure/adapters/repository"
+)
+
+// databaseURLEnv names the environment variable holding the URL of the CockroachDB the
+// contract is checked against, such as one started with "make contract-db".
+const databaseURLEnv = "CONTRACT_DATABASE_URL"
+
+// TestMatchModesDatabase asserts the behavior of every match mode on a real CockroachDB,
+// in a fresh database migrated with the production migrations and dropped afterwards. It
+// is skipped when CONTRACT_DATABASE_URL is not set, and runs with:
+//
+//	make contract-db test-contract
+func TestMatchModesDatabase(t *testing.T) {
+	rawURL := os.Getenv(databaseURLEnv)
+	if rawURL == "" {
+		t.Skipf("%s is not set", databaseURLEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	admin := openDatabase(t, rawURL)
+	name := fmt.Sprintf("contract_%d", time.Now().UnixNano())
+	require.NoError(t, admin.WithContext(ctx).Exec("CREATE DATABASE "+name).Error)
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP DATABASE " + name + " CASCADE").Error; err != nil {
+			t.Logf("Error dropping database %s: %v", name, err)
+		}
+	})
+
+	dsn, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	dsn.Path = "/" + name
+	// Sequential IDs, so the seed stocks get IDs 1 to 5 as on the other backends
+	query := dsn.Query()
+	query.Set("options", "-c serial_normalization=sql_sequence")
+	dsn.RawQuery = query.Encode()
+	db := openDatabase(t, dsn.String())
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	driver, err := migrate_driver.WithInstance(sqlDB, &migrate_driver.Config{})
+	require.NoError(t, err)
+	m, err := migrate.NewWithDatabaseInstance("file://../../migrations", name, driver)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	repo := repository.NewStockBDRepository(db, nil)
+	require.NoError(t, repo.SaveBatch(ctx, seedStocks()))
+
+	assertMatchModeBehavior(t, repo)
+}
+
+// openDatabase connects to the database at dsn with the dialect settings of
+// infrastructure.NewDatabaseConnection, and closes it when the test ends.
+func openDatabase(t *testing.T, dsn string) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
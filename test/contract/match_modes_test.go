@@ -0,0 +1,171 @@
+// Package contract holds the contract tests of the filter match modes. Every documented
+// match mode is checked twice: the SQL it generates is asserted against a mocked
+// connection, and its behavior is asserted against both stock stores on the same
+// seed data. The in-memory store always runs; a real CockroachDB runs with the
+// "contract" build tag when CONTRACT_DATABASE_URL is set (see database_test.go).
+package contract
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"stock-api/infrastructure/adapters/repository"
+	"stock-api/infrastructure/adapters/repository/querybuilder"
+	"stock-api/infrastructure/core/domain"
+	"stock-api/infrastructure/core/port"
+)
+
+// baseTime anchors the seed data in the past, so time filters are deterministic.
+var baseTime = time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// seedStocks returns the stocks every backend is loaded with. They get IDs 1 to 5.
+func seedStocks() []*domain.Stock {
+	return []*domain.Stock{
+		{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Morgan Stanley", TargetFrom: "$100.00", TargetTo: "$150.00", RatingTo: "Buy", Classifications: domain.StringArray{"Tech", "Potential Growth"}, Time: baseTime.Add(-2 * time.Hour)},
+		{Ticker: "MSFT", Company: "Microsoft Corporation", Brokerage: "Goldman Sachs", TargetFrom: "$300.00", TargetTo: "$330.00", Classifications: domain.StringArray{"Tech"}, Time: baseTime.Add(-time.Hour)},
+		{Ticker: "XOM", Company: "Exxon Mobil Corp.", Brokerage: "Barclays", TargetFrom: "$90.00", TargetTo: "$85.00", Classifications: domain.StringArray{"Energy"}, Time: baseTime.Add(-3 * time.Hour)},
+		{Ticker: "NVDA", Company: "NVIDIA Corp.", Brokerage: "Morgan Stanley", TargetFrom: "$500.00", TargetTo: "$700.00", Classifications: domain.StringArray{"Tech", "Bullish Signal"}, Time: baseTime.Add(-30 * time.Minute)},
+		{Ticker: "KO", Company: "Coca-Cola Co", Brokerage: "Barclays", TargetFrom: "$60.00", TargetTo: "$62.00", Time: baseTime.Add(-5 * time.Hour)},
+	}
+}
+
+// matchModeCase is the contract of a match mode on a field.
+//
+// Fields:
+//   - columnType: The type of the filtered column, used to check every mode is covered.
+//   - sql, args: The condition generated for the filter and its bound arguments.
+//   - tickers: The seed stocks matching the filter, ordered by ID.
+type matchModeCase struct {
+	name       string
+	columnType querybuilder.ColumnType
+	field      string
+	filter     domain.Filter
+	sql        string
+	args       []driver.Value
+	tickers    []string
+}
+
+var matchModeCases = []matchModeCase{
+	// Strings
+	{"string equals", querybuilder.TypeString, "ticker", domain.Filter{Value: "XOM", MatchMode: "equals"},
+		`ticker = $1`, []driver.Value{"XOM"}, []string{"XOM"}},
+	{"string contains searches the normalized company", querybuilder.TypeString, "company", domain.Filter{Value: "MOBIL", MatchMode: "contains"},
+		`company_normalized LIKE $1`, []driver.Value{"%mobil%"}, []string{"XOM"}},
+	{"string startsWith searches the normalized company", querybuilder.TypeString, "company", domain.Filter{Value: "Micro", MatchMode: "startsWith"},
+		`company_normalized LIKE $1`, []driver.Value{"micro%"}, []string{"MSFT"}},
+	{"string endsWith", querybuilder.TypeString, "ticker", domain.Filter{Value: "A", MatchMode: "endsWith"},
+		`ticker LIKE $1`, []driver.Value{"%A"}, []string{"NVDA"}},
+	{"string greaterThan", querybuilder.TypeString, "ticker", domain.Filter{Value: "MSFT", MatchMode: "greaterThan"},
+		`ticker > $1`, []driver.Value{"MSFT"}, []string{"XOM", "NVDA"}},
+	{"string lessThan", querybuilder.TypeString, "brokerage", domain.Filter{Value: "C", MatchMode: "lessThan"},
+		`brokerage < $1`, []driver.Value{"C"}, []string{"XOM", "KO"}},
+
+	// Numbers
+	{"number equals", querybuilder.TypeNumber, "id", domain.Filter{Value: float64(2), MatchMode: "equals"},
+		`id = $1`, []driver.Value{float64(2)}, []string{"MSFT"}},
+	{"number greaterThan", querybuilder.TypeNumber, "id", domain.Filter{Value: float64(3), MatchMode: "greaterThan"},
+		`id > $1`, []driver.Value{float64(3)}, []string{"NVDA", "KO"}},
+	{"number lessThan", querybuilder.TypeNumber, "id", domain.Filter{Value: float64(2), MatchMode: "lessThan"},
+		`id < $1`, []driver.Value{float64(2)}, []string{"AAPL"}},
+
+	// Timestamps
+	{"time equals", querybuilder.TypeTime, "time", domain.Filter{Value: "2025-01-15T11:00:00Z", MatchMode: "equals"},
+		`time = $1`, []driver.Value{"2025-01-15T11:00:00Z"}, []string{"MSFT"}},
+	{"time greaterThan", querybuilder.TypeTime, "time", domain.Filter{Value: "2025-01-15T10:30:00Z", MatchMode: "greaterThan"},
+		`time > $1`, []driver.Value{"2025-01-15T10:30:00Z"}, []string{"MSFT", "NVDA"}},
+	{"time lessThan", querybuilder.TypeTime, "time", domain.Filter{Value: "2025-01-15T09:30:00Z", MatchMode: "lessThan"},
+		`time < $1`, []driver.Value{"2025-01-15T09:30:00Z"}, []string{"XOM", "KO"}},
+
+	// Classifications
+	{"array contains", querybuilder.TypeStringArray, "classifications", domain.Filter{Value: "Tech", MatchMode: "contains"},
+		`classifications @> $1`, []driver.Value{pq.StringArray{"Tech"}}, []string{"AAPL", "MSFT", "NVDA"}},
+	{"array containsAll", querybuilder.TypeStringArray, "classifications", domain.Filter{Value: []interface{}{"Tech", "Bullish Signal"}, MatchMode: "containsAll"},
+		`classifications @> $1`, []driver.Value{pq.StringArray{"Tech", "Bullish Signal"}}, []string{"NVDA"}},
+	{"array containsAny", querybuilder.TypeStringArray, "classifications", domain.Filter{Value: []interface{}{"Energy", "Potential Growth"}, MatchMode: "containsAny"},
+		`classifications && $1`, []driver.Value{pq.StringArray{"Energy", "Potential Growth"}}, []string{"AAPL", "XOM"}},
+	// GORM parenthesizes conditions containing OR when combining them with others
+	{"array hasNone", querybuilder.TypeStringArray, "classifications", domain.Filter{MatchMode: "hasNone"},
+		`((classifications IS NULL OR classifications <@ $1))`, []driver.Value{pq.StringArray{domain.NeutralLabel}}, []string{"KO"}},
+}
+
+// byID orders results deterministically on every backend.
+var byID = domain.PaginationParams{SortField: "id", SortOrder: 1}
+
+// TestMatchModeCoverage fails when a match mode is added without a contract case.
+func TestMatchModeCoverage(t *testing.T) {
+	columnTypes := []querybuilder.ColumnType{
+		querybuilder.TypeString,
+		querybuilder.TypeNumber,
+		querybuilder.TypeTime,
+		querybuilder.TypeStringArray,
+	}
+
+	for _, columnType := range columnTypes {
+		for _, mode := range querybuilder.DefaultMatchModes(columnType) {
+			covered := false
+			for _, tc := range matchModeCases {
+				covered = covered || (tc.columnType == columnType && tc.filter.MatchMode == string(mode))
+			}
+			assert.True(t, covered, "match mode %q on column type %d has no contract case", mode, columnType)
+		}
+	}
+}
+
+// TestMatchModesSQL asserts the SQL the GORM backend sends for every match mode.
+func TestMatchModesSQL(t *testing.T) {
+	for _, tc := range matchModeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			require.NoError(t, err)
+
+			query := `SELECT * FROM "stocks" WHERE ` + tc.sql + ` AND "stocks"."deleted_at" IS NULL ORDER BY id ASC`
+			mock.ExpectQuery("^" + regexp.QuoteMeta(query) + "$").
+				WithArgs(tc.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "ticker"}))
+
+			_, err = repository.NewStockBDRepository(db, nil).Find(context.Background(), byID, domain.Filters{tc.field: tc.filter})
+			require.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestMatchModesMemory asserts the behavior of every match mode on the in-memory backend.
+func TestMatchModesMemory(t *testing.T) {
+	repo := repository.NewStockMemoryRepository(nil)
+	require.NoError(t, repo.SaveBatch(context.Background(), seedStocks()))
+
+	assertMatchModeBehavior(t, repo)
+}
+
+// assertMatchModeBehavior runs every contract case against a backend loaded with the
+// seed stocks.
+func assertMatchModeBehavior(t *testing.T, repo port.StockRepository) {
+	for _, tc := range matchModeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stocks, err := repo.Find(context.Background(), byID, domain.Filters{tc.field: tc.filter})
+			require.NoError(t, err)
+
+			tickers := []string{}
+			for _, stock := range stocks {
+				tickers = append(tickers, stock.Ticker)
+			}
+			assert.Equal(t, tc.tickers, tickers)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestParseAsOf(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected time.Time
+	}{
+		{"timestamp", "2025-02-01T08:30:00Z", time.Date(2025, 2, 1, 8, 30, 0, 0, time.UTC)},
+		{"timestamp with an offset", "2025-02-01T08:30:00-03:00", time.Date(2025, 2, 1, 11, 30, 0, 0, time.UTC)},
+		{"past date", "2025-02-01", time.Date(2025, 2, 1, 23, 59, 59, 999999999, time.UTC)},
+		{"today", "2025-03-01", now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := domain.ParseAsOf(tt.raw, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, at)
+		})
+	}
+
+	for _, raw := range []string{"", "01/02/2025", "2025-03-01T12:00:01Z", "2025-03-02"} {
+		_, err := domain.ParseAsOf(raw, now)
+		assert.ErrorIs(t, err, domain.ErrInvalidAsOf, raw)
+	}
+}
+
+func TestVisibleAt(t *testing.T) {
+	created := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	stock := domain.Stock{Ticker: "AAPL"}
+	stock.CreatedAt = created
+
+	assert.False(t, stock.VisibleAt(created.Add(-time.Second)))
+	assert.True(t, stock.VisibleAt(created))
+
+	stock.DeletedAt.Time, stock.DeletedAt.Valid = created.Add(time.Hour), true
+	assert.True(t, stock.VisibleAt(created.Add(time.Minute)))
+	assert.False(t, stock.VisibleAt(created.Add(time.Hour)))
+}
@@ -43,6 +43,20 @@ func TestClassificationService_Classify(t *testing.T) {
 			},
 			expectedLabels: []string{"Energy"},
 		},
+		{
+			name: "Sector classification ignores case, accents, and suffixes",
+			stock: &domain.Stock{
+				Company: "LABORATOIRES PHARMACÉUTIQUES S.A.",
+			},
+			expectedLabels: []string{"Biotech"},
+		},
+		{
+			name: "Sector keywords match whole word prefixes",
+			stock: &domain.Stock{
+				Company: "Las Vegas Sands Corp.",
+			},
+			expectedLabels: []string{"Other Sector"},
+		},
 		{
 			name: "Other sector classification",
 			stock: &domain.Stock{
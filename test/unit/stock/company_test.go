@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestNormalizeCompany(t *testing.T) {
+	tests := []struct {
+		name     string
+		company  string
+		expected string
+	}{
+		{"legal suffix", "Apple Inc.", "apple"},
+		{"stacked suffixes", "Foxconn Holdings Co., Ltd.", "foxconn holdings"},
+		{"dotted suffix", "Grupo Televisa, S.A.B. de C.V.", "grupo televisa"},
+		{"accents", "Société Générale S.A.", "societe generale"},
+		{"full-width characters", "ＡＣＭＥ PLC", "acme"},
+		{"punctuation", "Johnson & Johnson", "johnson johnson"},
+		{"possessive", "Macy's, Inc.", "macys"},
+		{"suffix only", "Company", "company"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, domain.NormalizeCompany(tt.company))
+		})
+	}
+}
+
+func TestCompanyHasKeyword(t *testing.T) {
+	assert.True(t, domain.CompanyHasKeyword("acme technologies", "tech"))
+	assert.False(t, domain.CompanyHasKeyword("acme biotech", "tech"))
+	assert.False(t, domain.CompanyHasKeyword("", "tech"))
+}
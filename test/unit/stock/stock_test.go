@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,6 +24,16 @@ func (m *MockStockRepository) FindByClassification(ctx context.Context, classifi
 	return args.Get(0).([]domain.Stock), args.Error(1)
 }
 
+func (m *MockStockRepository) FindByClassificationPaginated(ctx context.Context, classification string, pagination domain.PaginationParams) ([]domain.Stock, error) {
+	args := m.Called(ctx, classification, pagination)
+	return args.Get(0).([]domain.Stock), args.Error(1)
+}
+
+func (m *MockStockRepository) CountByClassification(ctx context.Context, classification string) (int, error) {
+	args := m.Called(ctx, classification)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockStockRepository) Create(ctx context.Context, stock *domain.Stock) error {
 	args := m.Called(ctx, stock)
 	return args.Error(0)
@@ -36,6 +49,11 @@ func (m *MockStockRepository) Count(ctx context.Context, filters domain.Filters)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockStockRepository) EstimateCount(ctx context.Context, filters domain.Filters) (count int, approximate bool, err error) {
+	args := m.Called(ctx, filters)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
 func (m *MockStockRepository) FindAll(ctx context.Context, order string, page, limit int) ([]domain.Stock, error) {
 	args := m.Called(ctx, order, page, limit)
 	return args.Get(0).([]domain.Stock), args.Error(1)
@@ -46,6 +64,23 @@ func (m *MockStockRepository) FindByTicker(ctx context.Context, ticker string) (
 	return args.Get(0).(*domain.Stock), args.Error(1)
 }
 
+func (m *MockStockRepository) FindLatestByTicker(ctx context.Context, ticker string) (*domain.Stock, error) {
+	args := m.Called(ctx, ticker)
+	stock, _ := args.Get(0).(*domain.Stock)
+	return stock, args.Error(1)
+}
+
+func (m *MockStockRepository) FindByID(ctx context.Context, id uint) (*domain.Stock, error) {
+	args := m.Called(ctx, id)
+	stock, _ := args.Get(0).(*domain.Stock)
+	return stock, args.Error(1)
+}
+
+func (m *MockStockRepository) Update(ctx context.Context, stock *domain.Stock, columns []string) error {
+	args := m.Called(ctx, stock, columns)
+	return args.Error(0)
+}
+
 func (m *MockStockRepository) Delete(ctx context.Context, stock *domain.Stock, id uint) error {
 	args := m.Called(ctx, stock, id)
 	return args.Error(0)
@@ -65,6 +100,11 @@ func (m *MockFieldValidator) IsValidField(field string) bool {
 	return args.Bool(0)
 }
 
+func (m *MockFieldValidator) IsVirtualField(field string) bool {
+	args := m.Called(field)
+	return args.Bool(0)
+}
+
 func (m *MockFieldValidator) GetAllValidFields() []string {
 	args := m.Called()
 	return args.Get(0).([]string)
@@ -82,6 +122,7 @@ func TestFind(t *testing.T) {
 
 	mockValidator.On("IsValidField", "company").Return(true)
 	mockValidator.On("IsValidField", "ticker").Return(true)
+	mockValidator.On("IsVirtualField", "ticker").Return(false)
 	mockRepo.On("Find", ctx, pagination, filters).Return([]domain.Stock{{Ticker: "MOMO"}}, nil)
 	mockRepo.On("Count", ctx, filters).Return(1, nil)
 
@@ -96,6 +137,136 @@ func TestFind(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestFindPage_Estimate(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockValidator := new(MockFieldValidator)
+	service := service.NewStockService(mockRepo, mockValidator)
+
+	ctx := context.Background()
+	pagination := domain.PaginationParams{Page: 1, PageSize: 20, SortOrder: -1, SortField: "time"}
+	filters := domain.Filters{}
+
+	mockValidator.On("IsValidField", "time").Return(true)
+	mockRepo.On("Find", ctx, pagination, filters).Return([]domain.Stock{{Ticker: "MOMO"}}, nil)
+	mockRepo.On("EstimateCount", ctx, filters).Return(250000, true, nil)
+
+	page, err := service.FindPage(ctx, pagination, filters, domain.QueryOptions{Estimate: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 250000, page.Total)
+	assert.True(t, page.Approximate)
+	assert.Len(t, page.Stocks, 1)
+
+	mockRepo.AssertNotCalled(t, "Count", ctx, filters)
+	mockRepo.AssertExpectations(t)
+}
+
+// MockCachedCountRepository is a repository whose counts are served from a cache.
+type MockCachedCountRepository struct {
+	MockStockRepository
+}
+
+func (m *MockCachedCountRepository) CountCached(ctx context.Context, filters domain.Filters) (int, time.Time, error) {
+	args := m.Called(ctx, filters)
+	return args.Int(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func TestFindPage_StaleCount(t *testing.T) {
+	ctx := context.Background()
+	pagination := domain.PaginationParams{Page: 1, PageSize: 20, SortOrder: -1, SortField: "time"}
+	filters := domain.Filters{}
+
+	newService := func(cachedAt time.Time) (*service.StockService, *MockCachedCountRepository) {
+		mockRepo := new(MockCachedCountRepository)
+		mockValidator := new(MockFieldValidator)
+		mockValidator.On("IsValidField", "time").Return(true)
+		mockRepo.On("Find", ctx, pagination, filters).Return([]domain.Stock{{Ticker: "MOMO"}}, nil)
+		mockRepo.On("CountCached", ctx, filters).Return(42, cachedAt, nil)
+		return service.NewStockService(mockRepo, mockValidator), mockRepo
+	}
+
+	t.Run("should flag counts cached longer than StaleAfter", func(t *testing.T) {
+		cachedAt := time.Now().Add(-2 * domain.StaleAfter)
+		service, mockRepo := newService(cachedAt)
+
+		page, err := service.FindPage(ctx, pagination, filters, domain.QueryOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 42, page.Total)
+		assert.True(t, page.Stale())
+		assert.Equal(t, cachedAt, page.CachedAt)
+		mockRepo.AssertNotCalled(t, "Count", ctx, filters)
+	})
+
+	t.Run("should not flag recently cached counts", func(t *testing.T) {
+		service, _ := newService(time.Now())
+
+		page, err := service.FindPage(ctx, pagination, filters, domain.QueryOptions{})
+
+		assert.NoError(t, err)
+		assert.False(t, page.Stale())
+	})
+}
+
+func TestFindPage_CountOnly(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockValidator := new(MockFieldValidator)
+	service := service.NewStockService(mockRepo, mockValidator)
+
+	ctx := context.Background()
+	filters := domain.Filters{"ticker": domain.Filter{Value: "MOM", MatchMode: "contains"}}
+
+	mockValidator.On("IsValidField", "time").Return(true)
+	mockValidator.On("IsValidField", "ticker").Return(true)
+	mockValidator.On("IsVirtualField", "ticker").Return(false)
+	mockRepo.On("Count", ctx, filters).Return(7, nil)
+
+	page, err := service.FindPage(ctx, domain.PaginationParams{}, filters, domain.QueryOptions{CountOnly: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, page.Total)
+	assert.Nil(t, page.Stocks)
+	mockRepo.AssertNotCalled(t, "Find", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindPage_CacheHints(t *testing.T) {
+	pagination := domain.PaginationParams{Page: 1, PageSize: 20, SortOrder: -1, SortField: "time"}
+	filters := domain.Filters{}
+
+	find := func(cachedAt time.Time, opts domain.QueryOptions) (domain.CacheHint, bool) {
+		ctx := domain.WithCacheHints(context.Background())
+		mockRepo := new(MockCachedCountRepository)
+		mockValidator := new(MockFieldValidator)
+		mockValidator.On("IsValidField", "time").Return(true)
+		mockRepo.On("Find", ctx, pagination, filters).Return([]domain.Stock{{Ticker: "MOMO"}}, nil)
+		mockRepo.On("CountCached", ctx, filters).Return(42, cachedAt, nil)
+
+		_, err := service.NewStockService(mockRepo, mockValidator).FindPage(ctx, pagination, filters, opts)
+		assert.NoError(t, err)
+		return domain.CacheHintFrom(ctx)
+	}
+
+	t.Run("should hint pages as cacheable for a short time", func(t *testing.T) {
+		hint, ok := find(time.Time{}, domain.QueryOptions{})
+
+		assert.True(t, ok)
+		assert.Equal(t, domain.CacheHint{MaxAge: domain.StockCacheMaxAge}, hint)
+	})
+
+	t.Run("should hint totals alone as cacheable for longer", func(t *testing.T) {
+		hint, _ := find(time.Time{}, domain.QueryOptions{CountOnly: true})
+
+		assert.Equal(t, domain.CountCacheMaxAge, hint.MaxAge)
+	})
+
+	t.Run("should require revalidation of stale pages", func(t *testing.T) {
+		hint, _ := find(time.Now().Add(-2*domain.StaleAfter), domain.QueryOptions{})
+
+		assert.Equal(t, domain.CacheHint{Revalidate: true}, hint)
+	})
+}
+
 func TestFind_InvalidSortField(t *testing.T) {
 	mockRepo := new(MockStockRepository)
 	mockValidator := new(MockFieldValidator)
@@ -138,3 +309,267 @@ func TestFind_InvalidFilterField(t *testing.T) {
 
 	mockValidator.AssertExpectations(t)
 }
+
+func TestFind_VirtualFilterField(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockValidator := new(MockFieldValidator)
+	service := service.NewStockService(mockRepo, mockValidator)
+
+	ctx := context.Background()
+	pagination := domain.PaginationParams{Page: 1, PageSize: 10, SortField: "score", SortOrder: -1}
+	filters := domain.Filters{"upside": domain.Filter{Value: 10, MatchMode: "greaterThan"}}
+
+	mockValidator.On("IsValidField", "score").Return(true)
+	mockValidator.On("IsValidField", "upside").Return(true)
+	mockValidator.On("IsVirtualField", "upside").Return(true)
+
+	stocks, total, err := service.Find(ctx, pagination, filters)
+
+	assert.Error(t, err)
+	assert.Nil(t, stocks)
+	assert.Equal(t, 0, total)
+	assert.EqualError(t, err, "field upside can only be used for sorting")
+
+	mockValidator.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Find")
+}
+
+func TestFindByClassification(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockValidator := new(MockFieldValidator)
+	service := service.NewStockService(mockRepo, mockValidator)
+
+	ctx := context.Background()
+	pagination := domain.PaginationParams{Page: 2, PageSize: 10}
+	expectedPagination := domain.PaginationParams{Page: 2, PageSize: 10, SortField: "time", SortOrder: -1}
+
+	mockValidator.On("IsValidField", "time").Return(true)
+	mockRepo.On("FindByClassificationPaginated", ctx, "Tech", expectedPagination).Return([]domain.Stock{{Ticker: "MSFT"}}, nil)
+	mockRepo.On("CountByClassification", ctx, "Tech").Return(11, nil)
+
+	page, err := service.FindByClassification(ctx, "Tech", pagination)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 11, page.Total)
+	assert.Len(t, page.Stocks, 1)
+	assert.Equal(t, expectedPagination, page.Pagination)
+
+	mockValidator.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFindByClassification_InvalidPageSize(t *testing.T) {
+	mockRepo := new(MockStockRepository)
+	mockValidator := new(MockFieldValidator)
+	service := service.NewStockService(mockRepo, mockValidator)
+
+	page, err := service.FindByClassification(context.Background(), "Tech", domain.PaginationParams{Page: 1, PageSize: -1})
+
+	assert.EqualError(t, err, "invalid page size: -1 (must be greater than 0)")
+	assert.Empty(t, page.Stocks)
+	assert.Equal(t, 0, page.Total)
+	mockRepo.AssertNotCalled(t, "FindByClassificationPaginated")
+}
+
+func TestFindPage_PaginationLimits(t *testing.T) {
+	ctx := context.Background()
+	filters := domain.Filters{}
+
+	tests := []struct {
+		name     string
+		limits   domain.PaginationLimits
+		input    domain.PaginationParams
+		expected domain.PaginationParams
+	}{
+		{
+			name:     "should apply the defaults when page and page size are omitted",
+			input:    domain.PaginationParams{},
+			expected: domain.PaginationParams{Page: 1, PageSize: 20, SortField: "time", SortOrder: -1},
+		},
+		{
+			name:     "should clamp page sizes above the cap",
+			input:    domain.PaginationParams{Page: 3, PageSize: 1000000},
+			expected: domain.PaginationParams{Page: 3, PageSize: 500, SortField: "time", SortOrder: -1},
+		},
+		{
+			name:     "should apply configured limits",
+			limits:   domain.PaginationLimits{DefaultPageSize: 50, MaxPageSize: 100},
+			input:    domain.PaginationParams{Page: 1, PageSize: 101},
+			expected: domain.PaginationParams{Page: 1, PageSize: 100, SortField: "time", SortOrder: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockStockRepository)
+			mockValidator := new(MockFieldValidator)
+			service := service.NewStockService(mockRepo, mockValidator).WithPaginationLimits(tt.limits)
+
+			mockValidator.On("IsValidField", "time").Return(true)
+			mockRepo.On("Find", ctx, tt.expected, filters).Return([]domain.Stock{}, nil)
+			mockRepo.On("Count", ctx, filters).Return(0, nil)
+
+			page, err := service.FindPage(ctx, tt.input, filters, domain.QueryOptions{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, page.Pagination)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRegisterStock_LabelPolicy(t *testing.T) {
+	ctx := context.Background()
+	newStock := func(labels ...string) *domain.Stock {
+		return &domain.Stock{Ticker: "AAPL", Company: "Apple Inc.", Brokerage: "Acme", Classifications: labels}
+	}
+
+	t.Run("should reject unknown labels by default", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+
+		err := service.RegisterStock(ctx, newStock("Tech", "Moon Shot"))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("should reject custom tags by default", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+
+		err := service.RegisterStock(ctx, newStock("Tech", "custom:Moon Shot"))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("should namespace unknown labels as custom tags", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator)).WithLabelPolicy(domain.LabelPolicyNamespace)
+		stock := newStock(" Tech ", "Moon Shot", "Tech", "", "custom:Moon Shot", "custom:Rocket")
+		mockRepo.On("Create", ctx, stock).Return(nil)
+
+		assert.NoError(t, service.RegisterStock(ctx, stock))
+		assert.Equal(t, domain.StringArray{"Tech", "custom:Moon Shot", "custom:Rocket"}, stock.Classifications)
+	})
+
+	t.Run("should keep unknown labels when allowed", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator)).WithLabelPolicy(domain.LabelPolicyAllow)
+		stock := newStock("Moon Shot")
+		mockRepo.On("Create", ctx, stock).Return(nil)
+
+		assert.NoError(t, service.RegisterStock(ctx, stock))
+		assert.Equal(t, domain.StringArray{"Moon Shot"}, stock.Classifications)
+	})
+
+	t.Run("should reject too many labels", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator)).WithLabelPolicy(domain.LabelPolicyAllow)
+		labels := make([]string, domain.MaxLabelsPerStock+1)
+		for i := range labels {
+			labels[i] = fmt.Sprintf("label-%d", i)
+		}
+
+		err := service.RegisterStock(ctx, newStock(labels...))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+	})
+
+	t.Run("should reject the whole batch when one item is invalid", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+
+		err := service.RegisterStocks(ctx, []*domain.Stock{newStock("Tech"), newStock("Moon Shot")})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+		assert.Contains(t, err.Error(), "item 1")
+		mockRepo.AssertNotCalled(t, "SaveBatch")
+	})
+}
+
+func TestUpdateStock(t *testing.T) {
+	ctx := context.Background()
+	existing := func() *domain.Stock {
+		stock := &domain.Stock{
+			Ticker:          "AAPL",
+			Company:         "Apple Inc.",
+			Brokerage:       "Acme",
+			Action:          "upgraded by",
+			RatingTo:        "Buy",
+			Classifications: domain.StringArray{"Tech"},
+		}
+		stock.ID = 7
+		return stock
+	}
+	parse := func(body string) map[string]json.RawMessage {
+		patch, err := domain.ParseMergePatch([]byte(body))
+		assert.NoError(t, err)
+		return patch
+	}
+
+	t.Run("should write only the changed fields and clear explicit nulls", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+		mockRepo.On("FindByID", ctx, uint(7)).Return(existing(), nil)
+		mockRepo.On("Update", ctx, mock.Anything, []string{"action", "rating_to"}).Return(nil)
+
+		stock, changes, err := service.UpdateStock(ctx, 7, parse(`{"rating_to": "Strong-Buy", "action": null, "company": "Apple Inc."}`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Strong-Buy", stock.RatingTo)
+		assert.Empty(t, stock.Action)
+		assert.Equal(t, "Apple Inc.", stock.Company)
+		assert.Equal(t, []domain.FieldChange{
+			{Field: "action", From: "upgraded by", To: ""},
+			{Field: "rating_to", From: "Buy", To: "Strong-Buy"},
+		}, changes)
+	})
+
+	t.Run("should not write a no-op patch", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+		mockRepo.On("FindByID", ctx, uint(7)).Return(existing(), nil)
+
+		_, changes, err := service.UpdateStock(ctx, 7, parse(`{"ticker": "AAPL"}`))
+
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("should reject nulls on required fields", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+		mockRepo.On("FindByID", ctx, uint(7)).Return(existing(), nil)
+
+		_, _, err := service.UpdateStock(ctx, 7, parse(`{"company": null}`))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("should reject unknown fields and unknown labels", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+		mockRepo.On("FindByID", ctx, uint(7)).Return(existing(), nil)
+
+		_, _, err := service.UpdateStock(ctx, 7, parse(`{"id": 8}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+
+		_, _, err = service.UpdateStock(ctx, 7, parse(`{"classifications": ["Moon Shot"]}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidStock)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+
+	t.Run("should return not found for missing stocks", func(t *testing.T) {
+		mockRepo := new(MockStockRepository)
+		service := service.NewStockService(mockRepo, new(MockFieldValidator))
+		mockRepo.On("FindByID", ctx, uint(9)).Return(nil, domain.ErrStockNotFound)
+
+		_, _, err := service.UpdateStock(ctx, 9, parse(`{"rating_to": "Buy"}`))
+
+		assert.ErrorIs(t, err, domain.ErrStockNotFound)
+	})
+}
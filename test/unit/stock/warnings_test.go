@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"stock-api/infrastructure/core/domain"
+)
+
+func TestCheckWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		stock    domain.Stock
+		expected domain.StringArray
+	}{
+		{"regular event", domain.Stock{RatingFrom: "Hold", RatingTo: "Buy", TargetFrom: "$100.00", TargetTo: "$120.00"}, nil},
+		{"rating in another case", domain.Stock{RatingFrom: "market perform", RatingTo: "STRONG-BUY"}, nil},
+		{"new coverage without a previous rating", domain.Stock{RatingTo: "Outperform"}, nil},
+		{"unknown rating", domain.Stock{RatingFrom: "Hold", RatingTo: "To The Moon"}, domain.StringArray{domain.WarningUnknownRating}},
+		{"target change at the limit", domain.Stock{TargetFrom: "$10.00", TargetTo: "$40.00"}, nil},
+		{"large target raise", domain.Stock{TargetFrom: "$10.00", TargetTo: "$40.01"}, domain.StringArray{domain.WarningLargeTargetChange}},
+		{"unparsable targets", domain.Stock{TargetFrom: "n/a", TargetTo: "$1,000.00"}, nil},
+		{
+			"several warnings",
+			domain.Stock{RatingFrom: "???", TargetFrom: "$1.00", TargetTo: "$9.00"},
+			domain.StringArray{domain.WarningUnknownRating, domain.WarningLargeTargetChange},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.stock.CheckWarnings())
+		})
+	}
+}
+
+func TestWarningTally(t *testing.T) {
+	var tally domain.WarningTally
+	tally.Add(
+		&domain.Stock{Ticker: "OK"},
+		&domain.Stock{Ticker: "ODD", Warnings: domain.StringArray{domain.WarningUnknownRating}},
+		&domain.Stock{Ticker: "WILD", Warnings: domain.StringArray{domain.WarningUnknownRating, domain.WarningLargeTargetChange}},
+	)
+
+	assert.Equal(t, 2, tally.Flagged)
+	assert.Equal(t, map[string]int{domain.WarningUnknownRating: 2, domain.WarningLargeTargetChange: 1}, tally.Counts)
+}